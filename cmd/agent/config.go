@@ -7,6 +7,10 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
 )
 
 type Config struct {
@@ -15,24 +19,47 @@ type Config struct {
 	ReportInterval int `json:"report_interval"`
 	PollInterval   int `json:"poll_interval"`
 	maxRetries     int
-	CryptoKey      string `json:"crypto_key"`
+	RateLimit      int               `json:"rate_limit"`
+	CryptoKey      string            `json:"crypto_key"`
+	GRPCAddress    string            `json:"grpc_address"`
+	Labels         map[string]string `json:"labels"`
+	HostnameLabel  string            `json:"hostname_label"`
+
+	// RetryBackoff and RetryMaxBackoff are durations such as 1s or 500ms.
+	RetryBackoff    string  `json:"retry_backoff"`
+	RetryMaxBackoff string  `json:"retry_max_backoff"`
+	RetryJitter     float64 `json:"retry_jitter"`
+	retryBackoff    time.Duration
+	retryMaxBackoff time.Duration
 }
 
 func NewConfig() (*Config, error) {
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 	c := &Config{
-		FlagSendAddr:   "localhost:8080",
-		ReportInterval: 10,
-		PollInterval:   2,
-		maxRetries:     5,
-		shaKey:         "",
-		CryptoKey:      "",
+		FlagSendAddr:    "localhost:8080",
+		ReportInterval:  10,
+		PollInterval:    2,
+		maxRetries:      5,
+		RateLimit:       1,
+		RetryBackoff:    "1s",
+		RetryMaxBackoff: "30s",
+		RetryJitter:     0.2,
+		shaKey:          "",
+		CryptoKey:       "",
 	}
 
 	if err := c.parseFlags(); err != nil {
 		return nil, err
 	}
 
+	if err := c.addHostnameLabel(); err != nil {
+		return nil, err
+	}
+
+	if err := dto.ValidateLabels(c.Labels); err != nil {
+		return nil, fmt.Errorf("labels: %s", err)
+	}
+
 	return c, nil
 }
 
@@ -57,16 +84,43 @@ func (c *Config) parseFlags() error {
 		}
 	}
 	if v, ok := os.LookupEnv("RATE_LIMIT"); v != "" && ok {
-		if c.maxRetries, err = strconv.Atoi(v); err != nil {
+		if c.RateLimit, err = strconv.Atoi(v); err != nil {
 			return fmt.Errorf("ENV RATE_LIMIT: %s", err)
 		}
 	}
+	if v, ok := os.LookupEnv("MAX_RETRIES"); v != "" && ok {
+		if c.maxRetries, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("ENV MAX_RETRIES: %s", err)
+		}
+	}
+	if v, ok := os.LookupEnv("RETRY_BACKOFF"); v != "" && ok {
+		c.RetryBackoff = v
+	}
+	if v, ok := os.LookupEnv("RETRY_MAX_BACKOFF"); v != "" && ok {
+		c.RetryMaxBackoff = v
+	}
+	if v, ok := os.LookupEnv("RETRY_JITTER"); v != "" && ok {
+		if c.RetryJitter, err = strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("ENV RETRY_JITTER: %s", err)
+		}
+	}
 	if v, ok := os.LookupEnv("KEY"); v != "" && ok {
 		c.shaKey = v
 	}
 	if v, ok := os.LookupEnv("CRYPTO_KEY"); v != "" && ok {
 		c.CryptoKey = v
 	}
+	if v, ok := os.LookupEnv("GRPC_ADDRESS"); v != "" && ok {
+		c.GRPCAddress = v
+	}
+	if v, ok := os.LookupEnv("LABELS"); v != "" && ok {
+		if c.Labels, err = parseLabels(v); err != nil {
+			return fmt.Errorf("ENV LABELS: %s", err)
+		}
+	}
+	if v, ok := os.LookupEnv("HOSTNAME_LABEL"); v != "" && ok {
+		c.HostnameLabel = v
+	}
 
 	flag.StringVar(&configFile, "c", configFile, "Path to the JSON config file")
 	flag.StringVar(&configFile, "config", configFile, "Path to the JSON config file")
@@ -74,8 +128,18 @@ func (c *Config) parseFlags() error {
 	flag.StringVar(&c.FlagSendAddr, "a", c.FlagSendAddr, "address and port to run server")
 	flag.IntVar(&c.ReportInterval, "r", c.ReportInterval, "reportInterval description")
 	flag.IntVar(&c.PollInterval, "p", c.PollInterval, "pollInterval description")
-	flag.IntVar(&c.maxRetries, "i", c.maxRetries, "maxRetries description")
+	flag.IntVar(&c.maxRetries, "i", c.maxRetries, "number of times a report that failed with a network error or a 5xx status is sent again before it is dropped")
+	flag.IntVar(&c.RateLimit, "rate-limit", c.RateLimit, "number of reports sent to the server at the same time")
+	flag.StringVar(&c.RetryBackoff, "retry-backoff", c.RetryBackoff, "delay before the first retry of a report, doubled with every next retry")
+	flag.StringVar(&c.RetryMaxBackoff, "retry-max-backoff", c.RetryMaxBackoff, "maximum delay between the retries of a report, an hour when 0")
+	flag.Float64Var(&c.RetryJitter, "retry-jitter", c.RetryJitter, "fraction of the delay from 0 to 1 the retries are randomly moved by")
 	flag.StringVar(&c.shaKey, "k", c.shaKey, "key description")
+	flag.StringVar(&c.GRPCAddress, "g", c.GRPCAddress, "address and port of the gRPC server, metrics are sent over HTTP when empty")
+	flag.Func("l", "comma separated static labels attached to every metric, e.g. env=prod,service=api", func(v string) error {
+		c.Labels, err = parseLabels(v)
+		return err
+	})
+	flag.StringVar(&c.HostnameLabel, "hostname-label", c.HostnameLabel, "name of the label that carries the hostname, disabled when empty")
 	flag.Parse()
 
 	if flag.NArg() > 0 {
@@ -103,6 +167,60 @@ func (c *Config) parseFlags() error {
 	log.Println("agent: " + strconv.Itoa(c.ReportInterval))
 	log.Println("agent: " + strconv.Itoa(c.PollInterval))
 	log.Println("agent: " + strconv.Itoa(c.maxRetries))
+	log.Println("agent: " + strconv.Itoa(c.RateLimit))
+
+	if c.RateLimit < 1 {
+		return fmt.Errorf("rate limit must be positive: %d", c.RateLimit)
+	}
+
+	return c.parseRetryPolicy()
+}
+
+// parseRetryPolicy parses the backoff durations of the retried reports and checks the jitter fraction.
+func (c *Config) parseRetryPolicy() error {
+	var err error
+	if c.retryBackoff, err = time.ParseDuration(c.RetryBackoff); err != nil || c.retryBackoff < 0 {
+		return fmt.Errorf("retry backoff must be a non-negative duration: %s", c.RetryBackoff)
+	}
+	if c.retryMaxBackoff, err = time.ParseDuration(c.RetryMaxBackoff); err != nil || c.retryMaxBackoff < 0 {
+		return fmt.Errorf("retry max backoff must be a non-negative duration: %s", c.RetryMaxBackoff)
+	}
+	if c.RetryJitter < 0 || c.RetryJitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1: %v", c.RetryJitter)
+	}
 
 	return nil
 }
+
+// addHostnameLabel adds the hostname to the static labels under the configured label name.
+func (c *Config) addHostnameLabel() error {
+	if c.HostnameLabel == "" {
+		return nil
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("hostname label: %s", err)
+	}
+
+	if c.Labels == nil {
+		c.Labels = make(map[string]string)
+	}
+	c.Labels[c.HostnameLabel] = hostname
+
+	return nil
+}
+
+// parseLabels parses comma separated name=value pairs.
+func parseLabels(v string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, item := range strings.Split(v, ",") {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("label must be name=value: %s", item)
+		}
+		labels[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return labels, nil
+}
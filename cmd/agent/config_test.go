@@ -4,6 +4,7 @@ import (
 	"flag"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,7 +24,8 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, 20, config.ReportInterval, "expected default report interval")
 		assert.Equal(t, 5, config.PollInterval, "expected default poll interval")
 		assert.Equal(t, "test", config.shaKey, "expected default poll interval")
-		assert.Equal(t, 222, config.maxRetries, "expected default poll interval")
+		assert.Equal(t, 222, config.RateLimit, "expected custom rate limit")
+		assert.Equal(t, 5, config.maxRetries, "expected default max retries")
 		assert.Equal(t, "test", config.CryptoKey, "expected default poll interval")
 	})
 
@@ -42,6 +44,7 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, 2, config.PollInterval, "expected default poll interval")
 		assert.Equal(t, "", config.shaKey, "expected default poll interval")
 		assert.Equal(t, 5, config.maxRetries, "expected default poll interval")
+		assert.Equal(t, 1, config.RateLimit, "expected default rate limit")
 		assert.Equal(t, "", config.CryptoKey, "expected default poll interval")
 	})
 
@@ -70,6 +73,67 @@ func TestNewConfig(t *testing.T) {
 		_ = os.Setenv("RATE_LIMIT", "Error")
 		_, err := NewConfig()
 		assert.Error(t, err)
+
+		resetVars()
+		_ = os.Setenv("RATE_LIMIT", "0")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("ENV_MAX_RETRIES", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("MAX_RETRIES", "3")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, config.maxRetries, "expected custom max retries")
+
+		resetVars()
+		_ = os.Setenv("MAX_RETRIES", "Error")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("ENV_RETRY", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("RETRY_BACKOFF", "500ms")
+		_ = os.Setenv("RETRY_MAX_BACKOFF", "10s")
+		_ = os.Setenv("RETRY_JITTER", "0.5")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 500*time.Millisecond, config.retryBackoff, "expected custom retry backoff")
+		assert.Equal(t, 10*time.Second, config.retryMaxBackoff, "expected custom retry max backoff")
+		assert.Equal(t, 0.5, config.RetryJitter, "expected custom retry jitter")
+
+		resetVars()
+		_ = os.Setenv("RETRY_JITTER", "Error")
+		_, err = NewConfig()
+		assert.Error(t, err)
+
+		resetVars()
+		_ = os.Setenv("RETRY_BACKOFF", "Error")
+		_, err = NewConfig()
+		assert.Error(t, err)
+
+		resetVars()
+		_ = os.Setenv("RETRY_MAX_BACKOFF", "-1s")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("CMD_RETRY", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("RETRY_BACKOFF", "500ms")
+		os.Args = []string{"cmd", "-retry-backoff=2s", "-retry-max-backoff=1m", "-retry-jitter=0"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 2*time.Second, config.retryBackoff, "expected custom retry backoff")
+		assert.Equal(t, time.Minute, config.retryMaxBackoff, "expected custom retry max backoff")
+		assert.Equal(t, 0.0, config.RetryJitter, "expected custom retry jitter")
+
+		resetVars()
+		os.Args = []string{"cmd", "-retry-jitter=1.5"}
+		_, err = NewConfig()
+		assert.Error(t, err)
 	})
 
 	t.Run("CMD", func(t *testing.T) {
@@ -94,7 +158,7 @@ func TestNewConfig(t *testing.T) {
 		_ = os.Setenv("RATE_LIMIT", "10")
 		_ = os.Setenv("CRYPTO_KEY", "1234")
 
-		os.Args = []string{"cmd", "-a=127.21.10.1:8080", "-r=100", "-p=500", "-k=key", "-i=25", "-crypto-key=123111"}
+		os.Args = []string{"cmd", "-a=127.21.10.1:8080", "-r=100", "-p=500", "-k=key", "-i=25", "-rate-limit=4", "-crypto-key=123111"}
 		config, err := NewConfig()
 		assert.NoError(t, err)
 		assert.Equal(t, "127.21.10.1:8080", config.FlagSendAddr, "expected default address")
@@ -102,9 +166,57 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, 500, config.PollInterval, "expected default poll interval")
 		assert.Equal(t, "key", config.shaKey, "expected default poll interval")
 		assert.Equal(t, 25, config.maxRetries, "expected default poll interval")
+		assert.Equal(t, 4, config.RateLimit, "expected custom rate limit")
 		assert.Equal(t, "123111", config.CryptoKey, "expected default poll interval")
 	})
 
+	t.Run("GRPC_ADDRESS", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("GRPC_ADDRESS", "127.0.0.1:3200")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:3200", config.GRPCAddress, "expected grpc address from env")
+
+		resetVars()
+		_ = os.Setenv("GRPC_ADDRESS", "127.0.0.1:3200")
+		os.Args = []string{"cmd", "-g=127.0.0.1:3201"}
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:3201", config.GRPCAddress, "expected grpc address from flag")
+	})
+
+	t.Run("LABELS", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("LABELS", "env=prod, service=api")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "prod", "service": "api"}, config.Labels, "expected labels from env")
+
+		resetVars()
+		_ = os.Setenv("LABELS", "env=prod")
+		os.Args = []string{"cmd", "-l=env=dev", "-hostname-label=host"}
+		config, err = NewConfig()
+		hostname, _ := os.Hostname()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "dev", "host": hostname}, config.Labels, "expected labels from flag")
+
+		resetVars()
+		_ = os.Setenv("HOSTNAME_LABEL", "host")
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"host": hostname}, config.Labels, "expected hostname label from env")
+
+		resetVars()
+		_ = os.Setenv("LABELS", "env")
+		_, err = NewConfig()
+		assert.Error(t, err)
+
+		resetVars()
+		_ = os.Setenv("LABELS", "service-name=api")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
 	t.Run("CMD_ERROR_FLAG", func(t *testing.T) {
 		resetVars()
 		os.Args = []string{"cmd", "-B=127.0.10.1:8080", "-r=15", "-p=66"}
@@ -135,6 +247,9 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, 2, config.PollInterval, "expected default poll interval")
 		assert.Equal(t, "", config.shaKey, "expected default poll interval")
 		assert.Equal(t, 5, config.maxRetries, "expected default poll interval")
+		assert.Equal(t, time.Second, config.retryBackoff, "expected default retry backoff")
+		assert.Equal(t, 30*time.Second, config.retryMaxBackoff, "expected default retry max backoff")
+		assert.Equal(t, 0.2, config.RetryJitter, "expected default retry jitter")
 	})
 
 	t.Run("ENV_FILE_PATH", func(t *testing.T) {
@@ -142,7 +257,8 @@ func TestNewConfig(t *testing.T) {
 			"address": "localhost:8080",
 			"report_interval":1,
 			"poll_interval": 1,
-			"crypto_key": "/path/to/key.pem"
+			"crypto_key": "/path/to/key.pem",
+			"retry_backoff": "3s"
 		}`), 0644)
 
 		resetVars()
@@ -153,6 +269,7 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, 1, config.ReportInterval, "expected default report interval")
 		assert.Equal(t, 1, config.PollInterval, "expected default poll interval")
 		assert.Equal(t, "/path/to/key.pem", config.CryptoKey, "expected default poll interval")
+		assert.Equal(t, 3*time.Second, config.retryBackoff, "expected retry backoff from the file")
 		_ = os.Remove("config.json")
 	})
 
@@ -10,8 +10,11 @@ import (
 	"syscall"
 
 	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	pb "github.com/AnatolySnegovskiy/metric/internal/proto"
 	"github.com/AnatolySnegovskiy/metric/internal/services/agent"
 	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 var buildVersion string
@@ -47,17 +50,32 @@ func main() {
 	fmt.Println("Agent started")
 	c, err := NewConfig()
 	handleError(err)
+
+	var grpcClient pb.MetricsClient
+	if c.GRPCAddress != "" {
+		conn, err := grpc.NewClient(c.GRPCAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		handleError(err)
+		defer conn.Close()
+		grpcClient = pb.NewMetricsClient(conn)
+	}
+
 	handleError(
 		agent.New(
 			agent.Options{
-				Storage:        s,
-				Client:         &http.Client{},
-				PollInterval:   c.PollInterval,
-				ReportInterval: c.ReportInterval,
-				SendAddr:       c.FlagSendAddr,
-				MaxRetries:     c.maxRetries,
-				ShaKey:         c.shaKey,
-				CryptoKey:      c.CryptoKey,
+				Storage:         s,
+				Client:          &http.Client{},
+				PollInterval:    c.PollInterval,
+				ReportInterval:  c.ReportInterval,
+				SendAddr:        c.FlagSendAddr,
+				MaxRetries:      c.maxRetries,
+				RateLimit:       c.RateLimit,
+				RetryBackoff:    c.retryBackoff,
+				RetryMaxBackoff: c.retryMaxBackoff,
+				RetryJitter:     c.RetryJitter,
+				ShaKey:          c.shaKey,
+				CryptoKey:       c.CryptoKey,
+				GRPCClient:      grpcClient,
+				Labels:          c.Labels,
 			},
 		).Run(context.Background()))
 }
@@ -118,6 +118,103 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, "test", config.GetCryptoKey(), "expected default crypto key")
 	})
 
+	t.Run("ENV_GRPC_ADDRESS", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("GRPC_ADDRESS", "localhost:3200")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "localhost:3200", config.GetGRPCAddress(), "expected custom grpc address")
+	})
+
+	t.Run("ENV_HISTOGRAM_BUCKETS", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("HISTOGRAM_BUCKETS", "0.1, 1,10")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{0.1, 1, 10}, config.GetHistogramBuckets(), "expected custom histogram buckets")
+
+		resetVars()
+		_ = os.Setenv("HISTOGRAM_BUCKETS", "10,1")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("ENV_HISTORY_SIZE", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("HISTORY_SIZE", "100")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 100, config.GetHistorySize(), "expected custom history size")
+
+		resetVars()
+		_ = os.Setenv("HISTORY_SIZE", "many")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("ENV_ALERTS", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("ALERT_RULES", "/etc/metric/alerts.rules")
+		_ = os.Setenv("ALERT_WEBHOOK", "http://localhost:9093/hook")
+		_ = os.Setenv("ALERT_INTERVAL", "30")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "/etc/metric/alerts.rules", config.GetAlertRulesFile(), "expected custom alert rules file")
+		assert.Equal(t, "http://localhost:9093/hook", config.GetAlertWebhook(), "expected custom alert webhook")
+		assert.Equal(t, 30, config.GetAlertInterval(), "expected custom alert interval")
+
+		resetVars()
+		_ = os.Setenv("ALERT_INTERVAL", "often")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("ENV_STORAGE", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("STORAGE", "file")
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "file", config.GetStorage(), "expected file storage")
+		assert.Equal(t, "/tmp/metrics-db.bolt", config.GetBoltPath(), "expected default bolt path")
+
+		resetVars()
+		_ = os.Setenv("STORAGE", "bolt")
+		_ = os.Setenv("BOLT_PATH", "/var/lib/metric/metrics.bolt")
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "bolt", config.GetStorage(), "expected bolt storage")
+		assert.Equal(t, "/var/lib/metric/metrics.bolt", config.GetBoltPath(), "expected custom bolt path")
+	})
+
+	t.Run("ENV_DATABASE_POOL", func(t *testing.T) {
+		resetVars()
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 10, config.GetDBMaxConns(), "expected default max conns")
+		assert.Equal(t, 0, config.GetDBMinConns(), "expected default min conns")
+		assert.Equal(t, 5, config.GetDBConnectTimeout(), "expected default connect timeout")
+		assert.Equal(t, 300, config.GetDBMaxConnIdleTime(), "expected default max conn idle time")
+
+		resetVars()
+		_ = os.Setenv("DATABASE_MAX_CONNS", "20")
+		_ = os.Setenv("DATABASE_MIN_CONNS", "2")
+		_ = os.Setenv("DATABASE_CONNECT_TIMEOUT", "1")
+		_ = os.Setenv("DATABASE_MAX_CONN_IDLE_TIME", "60")
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 20, config.GetDBMaxConns(), "expected custom max conns")
+		assert.Equal(t, 2, config.GetDBMinConns(), "expected custom min conns")
+		assert.Equal(t, 1, config.GetDBConnectTimeout(), "expected custom connect timeout")
+		assert.Equal(t, 60, config.GetDBMaxConnIdleTime(), "expected custom max conn idle time")
+
+		for _, env := range []string{"DATABASE_MAX_CONNS", "DATABASE_MIN_CONNS", "DATABASE_CONNECT_TIMEOUT", "DATABASE_MAX_CONN_IDLE_TIME"} {
+			resetVars()
+			_ = os.Setenv(env, "many")
+			_, err = NewConfig()
+			assert.Error(t, err, env)
+		}
+	})
+
 	t.Run("ENV_CONFIG_FILE", func(t *testing.T) {
 		_ = os.WriteFile(
 			"config.json",
@@ -190,6 +287,213 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, "1234", config.GetCryptoKey(), "expected restore to be false")
 	})
 
+	t.Run("CMD_GRPC_ADDRESS", func(t *testing.T) {
+		resetVars()
+		os.Args = []string{"cmd", "-g=localhost:3201"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "localhost:3201", config.GetGRPCAddress(), "expected custom grpc address")
+	})
+
+	t.Run("CMD_HISTOGRAM_BUCKETS", func(t *testing.T) {
+		resetVars()
+		os.Args = []string{"cmd", "-histogram-buckets=1,5"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{1, 5}, config.GetHistogramBuckets(), "expected custom histogram buckets")
+
+		resetVars()
+		os.Args = []string{"cmd", "-histogram-buckets=1,error", "-a=localhost:8080"}
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("CMD_HISTORY_SIZE", func(t *testing.T) {
+		resetVars()
+		os.Args = []string{"cmd", "-history-size=0"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, config.GetHistorySize(), "expected disabled history")
+	})
+
+	t.Run("CMD_ALERTS", func(t *testing.T) {
+		resetVars()
+		os.Args = []string{"cmd", "-alert-rules=alerts.rules", "-alert-webhook=http://localhost/hook", "-alert-interval=5"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "alerts.rules", config.GetAlertRulesFile(), "expected custom alert rules file")
+		assert.Equal(t, "http://localhost/hook", config.GetAlertWebhook(), "expected custom alert webhook")
+		assert.Equal(t, 5, config.GetAlertInterval(), "expected custom alert interval")
+	})
+
+	t.Run("CMD_STORAGE", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("STORAGE", "file")
+		os.Args = []string{"cmd", "-storage=memory", "-bolt-path=metrics.bolt"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "memory", config.GetStorage(), "expected memory storage")
+		assert.Equal(t, "metrics.bolt", config.GetBoltPath(), "expected custom bolt path")
+	})
+
+	t.Run("ENV_MIGRATIONS", func(t *testing.T) {
+		resetVars()
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "", config.GetMigrationsDir(), "expected embedded migrations")
+		assert.False(t, config.GetSkipMigrate(), "expected migrations on start")
+		assert.Empty(t, config.GetCommand(), "expected no command")
+
+		resetVars()
+		_ = os.Setenv("MIGRATIONS_DIR", "/etc/metric/migrations")
+		_ = os.Setenv("SKIP_MIGRATE", "true")
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "/etc/metric/migrations", config.GetMigrationsDir(), "expected custom migrations dir")
+		assert.True(t, config.GetSkipMigrate(), "expected skipped migrations")
+
+		resetVars()
+		_ = os.Setenv("SKIP_MIGRATE", "sometimes")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("CMD_MIGRATE", func(t *testing.T) {
+		resetVars()
+		os.Args = []string{"cmd", "-d=postgres://localhost/db", "-skip-migrate", "-migrations-dir=migrations", "migrate", "to", "3"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"migrate", "to", "3"}, config.GetCommand(), "expected migrate command")
+		assert.Equal(t, "postgres://localhost/db", config.GetDataBaseDSN(), "expected custom dsn")
+		assert.Equal(t, "migrations", config.GetMigrationsDir(), "expected custom migrations dir")
+		assert.True(t, config.GetSkipMigrate(), "expected skipped migrations")
+
+		resetVars()
+		os.Args = []string{"cmd", "export", "-format=csv", "dump.csv"}
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"export", "-format=csv", "dump.csv"}, config.GetCommand(), "expected export command")
+
+		resetVars()
+		os.Args = []string{"cmd", "serve"}
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("CMD_DATABASE_POOL", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("DATABASE_MAX_CONNS", "20")
+		os.Args = []string{"cmd", "-db-max-conns=30", "-db-min-conns=3", "-db-connect-timeout=2", "-db-max-conn-idle-time=90"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 30, config.GetDBMaxConns(), "expected custom max conns")
+		assert.Equal(t, 3, config.GetDBMinConns(), "expected custom min conns")
+		assert.Equal(t, 2, config.GetDBConnectTimeout(), "expected custom connect timeout")
+		assert.Equal(t, 90, config.GetDBMaxConnIdleTime(), "expected custom max conn idle time")
+	})
+
+	t.Run("ENV_RETENTION", func(t *testing.T) {
+		resetVars()
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "", config.GetRetention(), "expected no retention")
+		assert.Equal(t, 60, config.GetRetentionInterval(), "expected default retention interval")
+
+		resetVars()
+		_ = os.Setenv("RETENTION", "24h,gauge=1h")
+		_ = os.Setenv("RETENTION_INTERVAL", "30")
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "24h,gauge=1h", config.GetRetention(), "expected custom retention")
+		assert.Equal(t, 30, config.GetRetentionInterval(), "expected custom retention interval")
+
+		resetVars()
+		_ = os.Setenv("RETENTION_INTERVAL", "often")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("CMD_RETENTION", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("RETENTION", "24h")
+		os.Args = []string{"cmd", "-retention=gauge/Random*=10m", "-retention-interval=5"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "gauge/Random*=10m", config.GetRetention(), "expected custom retention")
+		assert.Equal(t, 5, config.GetRetentionInterval(), "expected custom retention interval")
+	})
+
+	t.Run("ENV_TENANTS", func(t *testing.T) {
+		resetVars()
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "", config.GetTenantsFile(), "expected no tenants file")
+
+		resetVars()
+		_ = os.Setenv("TENANTS_FILE", "/etc/metrics/tenants.json")
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "/etc/metrics/tenants.json", config.GetTenantsFile(), "expected custom tenants file")
+	})
+
+	t.Run("CMD_TENANTS", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("TENANTS_FILE", "/etc/metrics/tenants.json")
+		os.Args = []string{"cmd", "-tenants=tenants.json"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "tenants.json", config.GetTenantsFile(), "expected custom tenants file")
+	})
+
+	t.Run("ENV_ADMIN_TOKEN", func(t *testing.T) {
+		resetVars()
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "", config.GetAdminToken(), "expected disabled admin endpoints")
+
+		resetVars()
+		_ = os.Setenv("ADMIN_TOKEN", "secret")
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", config.GetAdminToken(), "expected custom admin token")
+	})
+
+	t.Run("CMD_ADMIN_TOKEN", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("ADMIN_TOKEN", "secret")
+		os.Args = []string{"cmd", "-admin-token=other"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "other", config.GetAdminToken(), "expected custom admin token")
+	})
+
+	t.Run("ENV_CACHE_STALENESS", func(t *testing.T) {
+		resetVars()
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, config.GetCacheStaleness(), "expected disabled cache")
+
+		resetVars()
+		_ = os.Setenv("CACHE_STALENESS", "10")
+		config, err = NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 10, config.GetCacheStaleness(), "expected custom cache staleness")
+
+		resetVars()
+		_ = os.Setenv("CACHE_STALENESS", "long")
+		_, err = NewConfig()
+		assert.Error(t, err)
+	})
+
+	t.Run("CMD_CACHE_STALENESS", func(t *testing.T) {
+		resetVars()
+		_ = os.Setenv("CACHE_STALENESS", "10")
+		os.Args = []string{"cmd", "-cache-staleness=3"}
+		config, err := NewConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, config.GetCacheStaleness(), "expected custom cache staleness")
+	})
+
 	t.Run("CMD_CONFIG_FILE", func(t *testing.T) {
 		_ = os.WriteFile(
 			"config.json",
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/server"
+	"github.com/gookit/gsr"
+)
+
+// runDump runs the export or import subcommand against the configured storage without serving it or running background jobs.
+// The dump is written to or read from the file of the last argument, the standard output or input when it is missing or -.
+// Export opens the storage read-only, so it can dump the file storage of a running server, an import saves it when done.
+// An import needs the server stopped, it fails while a server holds the lock of the file or bolt storage.
+func runDump(ctx context.Context, conf server.Config, logger gsr.GenLogger, args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	format := flags.String("format", server.FormatJSON, "dump format: json, ndjson or csv")
+	tenant := flags.String("tenant", "", "tenant whose metrics are dumped, the default tenant when empty")
+	mode := server.ImportMerge
+	if args[0] == "import" {
+		flags.StringVar(&mode, "mode", mode, "import mode: merge, replace or deltas")
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("%s: unexpected arguments %v", args[0], flags.Args()[1:])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	serv, err := server.Open(ctx, conf, logger, args[0] == "export")
+	if err != nil {
+		return err
+	}
+
+	ctx, err = serv.WithTenant(ctx, *tenant)
+	if err != nil {
+		serv.Close()
+		return err
+	}
+
+	path := flags.Arg(0)
+	if args[0] == "export" {
+		defer serv.Close()
+
+		if path != "" && path != "-" {
+			file, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+
+		return serv.Export(ctx, out, *format)
+	}
+
+	defer serv.ShotDown()
+
+	if path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	imported, err := serv.Import(ctx, in, *format, mode)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("imported %d metrics", imported)
+	return nil
+}
@@ -23,15 +23,26 @@ func handleError(err error) {
 }
 
 func main() {
-	fmt.Printf("Build version: %s\n", setDefaultValue(buildVersion, "N/A"))
-	fmt.Printf("Build date: %s\n", setDefaultValue(buildDate, "N/A"))
-	fmt.Printf("Build commit: %s\n", setDefaultValue(buildCommit, "N/A"))
 	logger, err := zap.NewProduction()
 	handleError(err)
 
 	conf, err := NewConfig()
 	handleError(err)
 
+	if command := conf.GetCommand(); len(command) > 0 {
+		if command[0] == "migrate" {
+			handleError(runMigrate(context.Background(), conf, command[1:], os.Stdout))
+			return
+		}
+
+		handleError(runDump(context.Background(), conf, logger.Sugar(), command, os.Stdin, os.Stdout))
+		return
+	}
+
+	fmt.Printf("Build version: %s\n", setDefaultValue(buildVersion, "N/A"))
+	fmt.Printf("Build date: %s\n", setDefaultValue(buildDate, "N/A"))
+	fmt.Printf("Build commit: %s\n", setDefaultValue(buildCommit, "N/A"))
+
 	serv, err := server.New(context.Background(), conf, logger.Sugar())
 	handleError(err)
 
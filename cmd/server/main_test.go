@@ -2,21 +2,24 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"bou.ke/monkey"
 	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
 	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/gookit/slog"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_Main(t *testing.T) {
 	resetVars()
-	os.Args = []string{"cmd", "-a=127.21.10.1:8150"}
+	os.Args = []string{"cmd", "-a=127.21.10.1:8150", "-storage=memory"}
 	s := storages.NewMemStorage()
 	s.AddMetric("gauge", metrics.NewGauge(nil))
 	s.AddMetric("counter", metrics.NewCounter(nil))
@@ -73,3 +76,92 @@ func TestSetDefaultValueEmpty(t *testing.T) {
 	val = setDefaultValue(val, "TEST2")
 	assert.Equal(t, val, "TEST2")
 }
+
+func TestRunMigrate(t *testing.T) {
+	conf := &Config{DataBaseDSN: "postgres://user@127.0.0.1:1/db", DBConnectTimeout: 1}
+	var out bytes.Buffer
+
+	assert.Error(t, runMigrate(context.Background(), conf, []string{"sideways"}, &out))
+	assert.Error(t, runMigrate(context.Background(), conf, []string{"status"}, &out))
+	assert.Empty(t, out.String())
+}
+
+func TestRunDump(t *testing.T) {
+	dir := t.TempDir()
+	conf := &Config{Storage: "file", FileStoragePath: "dump-test-metrics.json", Restore: true}
+	defer os.Remove(conf.FileStoragePath)
+	defer os.Remove(conf.FileStoragePath + ".wal")
+	defer os.Remove(conf.FileStoragePath + ".lock")
+	dump := filepath.Join(dir, "dump.ndjson")
+	_ = os.WriteFile(dump, []byte(`{"id":"PollCount","type":"counter","delta":5}`+"\n"+`{"id":"Alloc","type":"gauge","value":1.5}`+"\n"), 0644)
+
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"import", "-format=ndjson", "-mode=replace", dump}, nil, nil))
+	assert.FileExists(t, conf.FileStoragePath)
+
+	var out bytes.Buffer
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-format=csv"}, nil, &out))
+	assert.Contains(t, out.String(), "counter,PollCount,,5,")
+	assert.Contains(t, out.String(), "gauge,Alloc,,1.5,")
+
+	exported := filepath.Join(dir, "dump.json")
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"export", exported}, nil, nil))
+	data, _ := os.ReadFile(exported)
+	assert.Contains(t, string(data), `{"id":"PollCount","type":"counter","delta":5`)
+
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"import", "-mode=deltas", "-"}, bytes.NewBufferString(`[{"id":"PollCount","type":"counter","delta":2}]`), nil))
+	out.Reset()
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-format=ndjson", "-"}, nil, &out))
+	assert.Contains(t, out.String(), `{"id":"PollCount","type":"counter","delta":7`)
+
+	conf.Restore = false
+	snapshot, _ := os.ReadFile(conf.FileStoragePath)
+	wal, _ := os.OpenFile(conf.FileStoragePath+".wal", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	_, _ = wal.WriteString(`{"type":"counter","items":{"PollCount":9},"at":"2024-01-01T00:00:00Z"}` + "\n" + `{"type":"gauge",`)
+	_ = wal.Close()
+	walData, _ := os.ReadFile(conf.FileStoragePath + ".wal")
+	out.Reset()
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-format=ndjson", "-"}, nil, &out))
+	assert.Contains(t, out.String(), `{"id":"PollCount","type":"counter","delta":9`)
+	data, _ = os.ReadFile(conf.FileStoragePath)
+	assert.Equal(t, snapshot, data)
+	data, _ = os.ReadFile(conf.FileStoragePath + ".wal")
+	assert.Equal(t, walData, data)
+
+	running := storages.NewFileStore(conf.FileStoragePath, true)
+	assert.NoError(t, running.Lock())
+	assert.ErrorContains(t, runDump(context.Background(), conf, slog.New(), []string{"import", "-"}, bytes.NewBufferString(`[{"id":"PollCount","type":"counter","delta":1}]`), nil), "locked by another process")
+	out.Reset()
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-format=ndjson", "-"}, nil, &out))
+	assert.Contains(t, out.String(), `{"id":"PollCount","type":"counter","delta":9`)
+	assert.NoError(t, running.Close())
+	data, _ = os.ReadFile(conf.FileStoragePath)
+	assert.Equal(t, snapshot, data)
+
+	assert.Error(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-mode=replace"}, nil, &out))
+	assert.Error(t, runDump(context.Background(), conf, slog.New(), []string{"export", "a.json", "b.json"}, nil, &out))
+	assert.Error(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-format=xml"}, nil, &out))
+	assert.Error(t, runDump(context.Background(), conf, slog.New(), []string{"import", filepath.Join(dir, "missing.json")}, nil, nil))
+	assert.Error(t, runDump(context.Background(), conf, slog.New(), []string{"import"}, bytes.NewBufferString("{"), nil))
+}
+
+func TestRunDumpTenant(t *testing.T) {
+	dir := t.TempDir()
+	tenants := filepath.Join(dir, "tenants.json")
+	_ = os.WriteFile(tenants, []byte(`[{"name":"team-a","token":"token-a"}]`), 0644)
+	conf := &Config{Storage: "file", FileStoragePath: "dump-tenant-test-metrics.json", Restore: true, TenantsFile: tenants}
+	defer os.Remove(conf.FileStoragePath)
+	defer os.Remove(conf.FileStoragePath + ".wal")
+	defer os.Remove(conf.FileStoragePath + ".lock")
+
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"import", "-tenant=team-a", "-"}, bytes.NewBufferString(`[{"id":"Alloc","type":"gauge","value":1.5}]`), nil))
+
+	var out bytes.Buffer
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-format=csv", "-tenant=team-a"}, nil, &out))
+	assert.Contains(t, out.String(), "gauge,Alloc,,1.5,")
+
+	out.Reset()
+	assert.NoError(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-format=csv"}, nil, &out))
+	assert.NotContains(t, out.String(), "Alloc")
+
+	assert.Error(t, runDump(context.Background(), conf, slog.New(), []string{"export", "-tenant=team-b"}, nil, &out))
+}
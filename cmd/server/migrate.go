@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/migrations"
+	"github.com/jackc/pgx/v5"
+)
+
+// runMigrate runs the migrate subcommand with the arguments against the configured database.
+func runMigrate(ctx context.Context, conf *Config, args []string, out io.Writer) error {
+	command, err := migrations.ParseCommand(args)
+	if err != nil {
+		return err
+	}
+
+	db, err := clients.NewPostgresPool(ctx, conf.GetDataBaseDSN(), clients.PoolConfig{
+		MaxConns:       1,
+		ConnectTimeout: time.Duration(conf.GetDBConnectTimeout()) * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.AcquireConn(ctx, func(conn *pgx.Conn) error {
+		migrator, err := migrations.NewMigrator(ctx, conn, migrations.Source(conf.GetMigrationsDir()))
+		if err != nil {
+			return err
+		}
+
+		return command.Run(ctx, migrator, out)
+	})
+}
@@ -4,56 +4,106 @@ import (
 	"context"
 	"errors"
 	"strconv"
+	"time"
 
-	"github.com/AnatolySnegovskiy/metric/internal/repositories"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
 )
 
 type Counter struct {
-	Items map[string]float64
-	rep   *repositories.CounterRepo
+	updater[float64]
 }
 
 func (c *Counter) Process(ctx context.Context, name string, data string) error {
+	_, err := c.ProcessSample(ctx, name, data)
+	return err
+}
+
+// ProcessSample adds the delta to the named counter and returns its total.
+func (c *Counter) ProcessSample(ctx context.Context, name string, data string) (float64, error) {
 	intValue, err := strconv.ParseInt(data, 10, 64)
 	if err != nil {
-		return errors.New("metric value is not int")
+		return 0, errors.New("metric value is not int")
 	}
 
-	c.Items[name] += float64(intValue)
-
-	if c.rep != nil {
-		return c.rep.AddMetric(ctx, name, int(c.Items[name]))
-	}
-
-	return nil
+	totals, err := c.ProcessSamples(ctx, map[string]float64{name: float64(intValue)})
+	return totals[name], err
 }
 
+// ProcessMassive adds the deltas to the stored counters.
 func (c *Counter) ProcessMassive(ctx context.Context, data map[string]float64) error {
-	for name, value := range data {
-		c.Items[name] += value
-	}
+	_, err := c.ProcessSamples(ctx, data)
+	return err
+}
 
-	if c.rep != nil {
-		return c.rep.AddMetrics(ctx, c.Items)
+// ProcessSamples adds the deltas to the stored counters and returns their totals.
+// A backend that implements storages.Incrementer gets only the deltas, so it keeps the authoritative totals.
+func (c *Counter) ProcessSamples(ctx context.Context, data map[string]float64) (map[string]float64, error) {
+	if incrementer, ok := c.backend.(storages.Incrementer); ok {
+		if len(data) == 0 {
+			return nil, nil
+		}
+
+		return incrementer.IncrementMetrics(ctx, data)
 	}
 
-	return nil
+	return c.update(ctx, dto.SeriesKeys(data), func(key string, value float64, _ bool) (float64, error) {
+		return value + data[key], nil
+	})
 }
 
 func (c *Counter) GetList(ctx context.Context) (map[string]float64, error) {
-	if c.rep != nil {
-		items, err := c.rep.GetList(ctx)
-		if err != nil {
-			return nil, err
+	return c.backend.GetList(ctx)
+}
+
+// GetItem returns the value of the series with the key, ok is false when it isn't stored.
+func (c *Counter) GetItem(ctx context.Context, key string) (float64, bool, error) {
+	return getItem(ctx, c.backend, key)
+}
+
+// Delete removes the series with the keys.
+func (c *Counter) Delete(ctx context.Context, keys []string) error {
+	return c.backend.DeleteMetrics(ctx, keys)
+}
+
+// UpdatedAt returns the last update time of every series.
+func (c *Counter) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return updatedAt(ctx, c.backend)
+}
+
+// Expire removes the series with the keys that weren't updated since before.
+func (c *Counter) Expire(ctx context.Context, keys []string, before time.Time) error {
+	return expire(ctx, c.backend, keys, before)
+}
+
+// Reset sets the series with the keys back to zero, unknown keys are skipped.
+func (c *Counter) Reset(ctx context.Context, keys []string) error {
+	items, err := c.backend.GetList(ctx)
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[string]float64, len(keys))
+	for _, key := range keys {
+		if _, ok := items[key]; ok {
+			changed[key] = 0
 		}
-		c.Items = items
 	}
-	return c.Items, nil
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	return c.backend.AddMetrics(ctx, changed)
 }
 
-func NewCounter(rep *repositories.CounterRepo) *Counter {
+// NewCounter creates a counter stored in the backend, a nil backend keeps the counter in memory.
+func NewCounter(backend storages.Backend[float64]) *Counter {
+	if backend == nil {
+		backend = storages.NewMemBackend[float64]()
+	}
+
 	return &Counter{
-		Items: make(map[string]float64),
-		rep:   rep,
+		updater: updater[float64]{backend: backend},
 	}
 }
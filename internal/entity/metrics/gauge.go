@@ -4,56 +4,79 @@ import (
 	"context"
 	"errors"
 	"strconv"
+	"time"
 
-	"github.com/AnatolySnegovskiy/metric/internal/repositories"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
 )
 
 type Gauge struct {
-	Items map[string]float64
-	rep   *repositories.GaugeRepo
+	backend storages.Backend[float64]
 }
 
 func (g *Gauge) Process(ctx context.Context, name string, data string) error {
+	_, err := g.ProcessSample(ctx, name, data)
+	return err
+}
+
+// ProcessSample sets the named gauge and returns the stored value.
+func (g *Gauge) ProcessSample(ctx context.Context, name string, data string) (float64, error) {
 	floatValue, err := strconv.ParseFloat(data, 64)
 	if err != nil {
-		return errors.New("metric value is not float64")
-	}
-
-	g.Items[name] = floatValue
-
-	if g.rep != nil {
-		return g.rep.AddMetric(ctx, name, g.Items[name])
+		return 0, errors.New("metric value is not float64")
 	}
 
-	return nil
+	return floatValue, g.backend.AddMetrics(ctx, map[string]float64{name: floatValue})
 }
 
 func (g *Gauge) ProcessMassive(ctx context.Context, data map[string]float64) error {
-	for name, value := range data {
-		g.Items[name] = value
+	_, err := g.ProcessSamples(ctx, data)
+	return err
+}
+
+// ProcessSamples sets the gauges and returns the stored values, which are the values given.
+func (g *Gauge) ProcessSamples(ctx context.Context, data map[string]float64) (map[string]float64, error) {
+	if len(data) == 0 {
+		return nil, nil
 	}
 
-	if g.rep != nil {
-		return g.rep.AddMetrics(ctx, g.Items)
+	if err := g.backend.AddMetrics(ctx, data); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return data, nil
 }
 
 func (g *Gauge) GetList(ctx context.Context) (map[string]float64, error) {
-	if g.rep != nil {
-		items, err := g.rep.GetList(ctx)
-		if err != nil {
-			return nil, err
-		}
-		g.Items = items
-	}
-	return g.Items, nil
+	return g.backend.GetList(ctx)
+}
+
+// GetItem returns the value of the series with the key, ok is false when it isn't stored.
+func (g *Gauge) GetItem(ctx context.Context, key string) (float64, bool, error) {
+	return getItem(ctx, g.backend, key)
+}
+
+// Delete removes the series with the keys.
+func (g *Gauge) Delete(ctx context.Context, keys []string) error {
+	return g.backend.DeleteMetrics(ctx, keys)
+}
+
+// UpdatedAt returns the last update time of every series.
+func (g *Gauge) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return updatedAt(ctx, g.backend)
 }
 
-func NewGauge(rep *repositories.GaugeRepo) *Gauge {
+// Expire removes the series with the keys that weren't updated since before.
+func (g *Gauge) Expire(ctx context.Context, keys []string, before time.Time) error {
+	return expire(ctx, g.backend, keys, before)
+}
+
+// NewGauge creates a gauge stored in the backend, a nil backend keeps the gauge in memory.
+func NewGauge(backend storages.Backend[float64]) *Gauge {
+	if backend == nil {
+		backend = storages.NewMemBackend[float64]()
+	}
+
 	return &Gauge{
-		Items: make(map[string]float64),
-		rep:   rep,
+		backend: backend,
 	}
 }
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+)
+
+// DefaultBuckets are the bucket upper bounds used when no bounds are configured.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type Histogram struct {
+	updater[dto.Histogram]
+	bounds []float64
+}
+
+// Process adds a single observation to the named histogram.
+func (h *Histogram) Process(ctx context.Context, name string, data string) error {
+	_, err := h.ProcessSample(ctx, name, data)
+	return err
+}
+
+// ProcessSample adds a single observation to the named histogram and returns its number of observations.
+func (h *Histogram) ProcessSample(ctx context.Context, name string, data string) (float64, error) {
+	floatValue, err := strconv.ParseFloat(data, 64)
+	if err != nil {
+		return 0, errors.New("metric value is not float64")
+	}
+
+	counts, err := h.ProcessSamples(ctx, map[string]float64{name: floatValue})
+	return counts[name], err
+}
+
+// ProcessMassive adds one observation per histogram.
+func (h *Histogram) ProcessMassive(ctx context.Context, data map[string]float64) error {
+	_, err := h.ProcessSamples(ctx, data)
+	return err
+}
+
+// ProcessSamples adds one observation per histogram and returns their numbers of observations.
+func (h *Histogram) ProcessSamples(ctx context.Context, data map[string]float64) (map[string]float64, error) {
+	items, err := h.update(ctx, dto.SeriesKeys(data), func(key string, item dto.Histogram, ok bool) (dto.Histogram, error) {
+		item = h.item(item, ok)
+		if err := item.Observe(data[key]); err != nil {
+			return item, fmt.Errorf("metric %s: %w", key, err)
+		}
+
+		return item, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sampleValues(items, histogramCount), nil
+}
+
+// ProcessHistograms merges bucket observations into the stored histograms and returns their numbers of observations.
+func (h *Histogram) ProcessHistograms(ctx context.Context, data map[string]dto.Histogram) (map[string]float64, error) {
+	items, err := h.update(ctx, dto.SeriesKeys(data), func(key string, item dto.Histogram, ok bool) (dto.Histogram, error) {
+		item = h.item(item, ok)
+		if err := item.Merge(data[key]); err != nil {
+			return item, fmt.Errorf("metric %s: %w", key, err)
+		}
+
+		return item, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sampleValues(items, histogramCount), nil
+}
+
+// GetList returns the number of observations of every histogram.
+func (h *Histogram) GetList(ctx context.Context) (map[string]float64, error) {
+	items, err := h.GetHistograms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sampleValues(items, histogramCount), nil
+}
+
+// GetHistograms returns every stored histogram.
+func (h *Histogram) GetHistograms(ctx context.Context) (map[string]dto.Histogram, error) {
+	return h.backend.GetList(ctx)
+}
+
+// Bounds returns the bucket upper bounds used for new histograms.
+func (h *Histogram) Bounds() []float64 {
+	return h.bounds
+}
+
+// item returns a copy of the stored histogram or a new empty one when it doesn't exist.
+func (h *Histogram) item(item dto.Histogram, ok bool) dto.Histogram {
+	if ok {
+		return item.Clone()
+	}
+
+	return dto.NewHistogram(h.bounds)
+}
+
+// Delete removes the series with the keys.
+func (h *Histogram) Delete(ctx context.Context, keys []string) error {
+	return h.backend.DeleteMetrics(ctx, keys)
+}
+
+// UpdatedAt returns the last update time of every series.
+func (h *Histogram) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return updatedAt(ctx, h.backend)
+}
+
+// Expire removes the series with the keys that weren't updated since before.
+func (h *Histogram) Expire(ctx context.Context, keys []string, before time.Time) error {
+	return expire(ctx, h.backend, keys, before)
+}
+
+// NewHistogram creates histograms with the bucket bounds stored in the backend, a nil backend keeps them in memory.
+func NewHistogram(backend storages.Backend[dto.Histogram], bounds []float64) *Histogram {
+	if len(bounds) == 0 {
+		bounds = DefaultBuckets
+	}
+
+	if backend == nil {
+		backend = storages.NewMemBackend[dto.Histogram]()
+	}
+
+	return &Histogram{
+		updater: updater[dto.Histogram]{backend: backend},
+		bounds:  bounds,
+	}
+}
+
+// histogramCount returns the number of observations of the histogram, the value of the histogram in GetList.
+func histogramCount(item dto.Histogram) float64 {
+	return float64(item.Count)
+}
@@ -2,11 +2,17 @@ package metrics
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"math"
 	"regexp"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/AnatolySnegovskiy/metric/internal/repositories"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
 	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
@@ -78,7 +84,8 @@ func TestGauge_getListErrorDB(t *testing.T) {
 	}
 	defer mock.Close()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM gauge")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).
+		WithArgs("").
 		WillReturnError(errors.New("db error"))
 	mockDB := clients.NewPostgres(mock)
 	cr := repositories.NewGaugeRepo(mockDB)
@@ -94,7 +101,8 @@ func TestCounter_getListErrorDB(t *testing.T) {
 	}
 	defer mock.Close()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM counter")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
 		WillReturnError(errors.New("db error"))
 	mockDB := clients.NewPostgres(mock)
 	cr := repositories.NewCounterRepo(mockDB)
@@ -110,11 +118,12 @@ func TestGauge_ProcessDB(t *testing.T) {
 	}
 	defer mock.Close()
 
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = $2")).
-		WithArgs("test", float64(100)).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value")).
+		WithArgs("", "test", "", float64(100)).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM gauge")).
-		WillReturnRows(pgxmock.NewRows([]string{"name", "value"}).AddRow("test", float64(100)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", float64(100)))
 
 	mockDB := clients.NewPostgres(mock)
 	cr := repositories.NewGaugeRepo(mockDB)
@@ -133,11 +142,14 @@ func TestCounter_ProcessDB(t *testing.T) {
 	}
 	defer mock.Close()
 
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = $2")).
-		WithArgs("test", int(100)).
-		WillReturnResult(pgxmock.NewResult("INSERT", 1))
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM counter")).
-		WillReturnRows(pgxmock.NewRows([]string{"name", "value"}).AddRow("test", 100))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = counter.value + EXCLUDED.value")).
+		WithArgs("", "test", "", int(100)).
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", int64(100)))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", 140))
 
 	mockDB := clients.NewPostgres(mock)
 	cr := repositories.NewCounterRepo(mockDB)
@@ -146,7 +158,7 @@ func TestCounter_ProcessDB(t *testing.T) {
 	assert.NoError(t, err)
 	list, err := counter.GetList(context.Background())
 	assert.NoError(t, err)
-	assert.Equal(t, map[string]float64{"test": 100}, list, "Expected list %v, but got: %v", map[string]float64{"test": 100}, list)
+	assert.Equal(t, map[string]float64{"test": 140}, list, "Expected list %v, but got: %v", map[string]float64{"test": 140}, list)
 }
 
 func TestGauge_ProcessMassiveDB(t *testing.T) {
@@ -156,11 +168,12 @@ func TestGauge_ProcessMassiveDB(t *testing.T) {
 	}
 	defer mock.Close()
 
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value")).
-		WithArgs("test", float64(500)).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value")).
+		WithArgs("", "test", "", float64(500)).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM gauge")).
-		WillReturnRows(pgxmock.NewRows([]string{"name", "value"}).AddRow("test", float64(500)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", float64(500)))
 
 	mockDB := clients.NewPostgres(mock)
 	cr := repositories.NewGaugeRepo(mockDB)
@@ -179,11 +192,14 @@ func TestCounter_ProcessMassiveDB(t *testing.T) {
 	}
 	defer mock.Close()
 
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value")).
-		WithArgs("test", int(500)).
-		WillReturnResult(pgxmock.NewResult("INSERT", 1))
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM counter")).
-		WillReturnRows(pgxmock.NewRows([]string{"name", "value"}).AddRow("test", 500))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = counter.value + EXCLUDED.value")).
+		WithArgs("", "test", "", int(500)).
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", int64(500)))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", 500))
 
 	mockDB := clients.NewPostgres(mock)
 	cr := repositories.NewCounterRepo(mockDB)
@@ -212,3 +228,319 @@ func TestCounter_ProcessMassive(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, map[string]float64{"test": 500}, list, "Expected list %v, but got: %v", map[string]float64{"test": 500}, list)
 }
+
+// listBackend is a backend without storages.Updater, its updates go through the entity mutex.
+type listBackend struct {
+	*storages.MemBackend[float64]
+}
+
+func TestCounter_ProcessConcurrent(t *testing.T) {
+	backends := map[string]storages.Backend[float64]{
+		"updater": storages.NewMemBackend[float64](),
+		"list":    listBackend{storages.NewMemBackend[float64]()},
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			counter := NewCounter(backend)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						assert.NoError(t, counter.Process(context.Background(), "PollCount", "1"))
+						assert.NoError(t, counter.ProcessMassive(context.Background(), map[string]float64{"PollCount": 1, "Other": 2}))
+					}
+				}()
+			}
+			wg.Wait()
+
+			list, err := counter.GetList(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]float64{"PollCount": 1600, "Other": 1600}, list)
+		})
+	}
+}
+
+func TestHistogram_Process(t *testing.T) {
+	histogram := NewHistogram(nil, []float64{1, 5})
+
+	assert.NoError(t, histogram.Process(context.Background(), "latency", "0.5"))
+	assert.NoError(t, histogram.Process(context.Background(), "latency", "5"))
+	assert.NoError(t, histogram.ProcessMassive(context.Background(), map[string]float64{"latency": 10}))
+	assert.EqualError(t, histogram.Process(context.Background(), "latency", "invalid"), "metric value is not float64")
+	assert.ErrorIs(t, histogram.Process(context.Background(), "latency", "NaN"), dto.ErrNonFiniteValue)
+	assert.ErrorIs(t, histogram.ProcessMassive(context.Background(), map[string]float64{"latency": math.Inf(1)}), dto.ErrNonFiniteValue)
+
+	list, err := histogram.GetList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"latency": 3}, list)
+
+	items, err := histogram.GetHistograms(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, dto.Histogram{Bounds: []float64{1, 5}, Counts: []uint64{1, 1, 1}, Sum: 15.5, Count: 3}, items["latency"])
+}
+
+func TestHistogram_ProcessHistograms(t *testing.T) {
+	histogram := NewHistogram(nil, nil)
+	assert.Equal(t, DefaultBuckets, histogram.Bounds())
+
+	histogram = NewHistogram(nil, []float64{1, 5})
+	counts, err := histogram.ProcessHistograms(context.Background(), map[string]dto.Histogram{
+		"latency": {Bounds: []float64{1, 5}, Counts: []uint64{2, 0, 1}, Sum: 7, Count: 3},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"latency": 3}, counts)
+
+	_, err = histogram.ProcessHistograms(context.Background(), map[string]dto.Histogram{
+		"latency": {Bounds: []float64{1, 2}, Counts: []uint64{1, 0, 0}, Sum: 1, Count: 1},
+	})
+	assert.ErrorIs(t, err, dto.ErrHistogramBounds)
+
+	_, err = histogram.ProcessHistograms(context.Background(), map[string]dto.Histogram{
+		"latency": {Bounds: []float64{1, 5}, Counts: []uint64{1, 0, 0}, Sum: math.NaN(), Count: 1},
+	})
+	assert.ErrorIs(t, err, dto.ErrNonFiniteValue)
+
+	items, _ := histogram.GetHistograms(context.Background())
+	assert.Equal(t, uint64(3), items["latency"].Count)
+}
+
+func TestHistogram_ProcessDB(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, bounds, counts, sum, count FROM histogram")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "bounds", "counts", "sum", "count"}))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO histogram (tenant, name, labels, bounds, counts, sum, count) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (tenant, name, labels) DO UPDATE SET bounds = EXCLUDED.bounds")).
+		WithArgs("", "latency", "", []float64{1}, []int64{1, 0}, float64(0.5), int64(1)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, bounds, counts, sum, count FROM histogram")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "bounds", "counts", "sum", "count"}).AddRow("latency", "", []float64{1}, []int64{1, 0}, float64(0.5), int64(1)))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO histogram (tenant, name, labels, bounds, counts, sum, count) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (tenant, name, labels) DO UPDATE SET bounds = EXCLUDED.bounds")).
+		WithArgs("", "latency", "", []float64{1}, []int64{1, 1}, float64(2.5), int64(2)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, bounds, counts, sum, count FROM histogram")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "bounds", "counts", "sum", "count"}).AddRow("latency", "", []float64{1}, []int64{1, 1}, float64(2.5), int64(2)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, bounds, counts, sum, count FROM histogram")).
+		WithArgs("").
+		WillReturnError(errors.New("db error"))
+
+	histogram := NewHistogram(repositories.NewHistogramRepo(clients.NewPostgres(mock)), []float64{1})
+	assert.NoError(t, histogram.Process(context.Background(), "latency", "0.5"))
+	assert.NoError(t, histogram.ProcessMassive(context.Background(), map[string]float64{"latency": 2}))
+
+	list, err := histogram.GetList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"latency": 2}, list)
+
+	_, err = histogram.GetList(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSummary_Process(t *testing.T) {
+	summary := NewSummary(nil)
+	assert.Equal(t, dto.DefaultRelativeAccuracy, summary.RelativeAccuracy())
+
+	values := make(map[string]float64, 1)
+	for i := 1; i <= 1000; i++ {
+		values["latency"] = float64(i)
+		assert.NoError(t, summary.ProcessMassive(context.Background(), values))
+	}
+	assert.NoError(t, summary.Process(context.Background(), "signed", "-5"))
+	assert.NoError(t, summary.Process(context.Background(), "signed", "0"))
+	assert.NoError(t, summary.Process(context.Background(), "signed", "5"))
+	assert.EqualError(t, summary.Process(context.Background(), "latency", "invalid"), "metric value is not float64")
+	assert.ErrorIs(t, summary.Process(context.Background(), "latency", "+Inf"), dto.ErrNonFiniteValue)
+	assert.ErrorIs(t, summary.ProcessMassive(context.Background(), map[string]float64{"latency": math.NaN()}), dto.ErrNonFiniteValue)
+
+	list, err := summary.GetList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"latency": 1000, "signed": 3}, list)
+
+	items, err := summary.GetSummaries(context.Background())
+	assert.NoError(t, err)
+
+	latency := items["latency"]
+	for _, q := range []float64{0, 0.5, 0.9, 0.99, 1} {
+		expected := 1 + q*999
+		assert.InEpsilon(t, expected, latency.Quantile(q), dto.DefaultRelativeAccuracy*1.01, "quantile %v", q)
+	}
+	assert.Equal(t, float64(500500), latency.Sum)
+	assert.Equal(t, float64(1), latency.Min)
+	assert.Equal(t, float64(1000), latency.Max)
+
+	signed := items["signed"]
+	assert.InEpsilon(t, -5, signed.Quantile(0), dto.DefaultRelativeAccuracy)
+	assert.Equal(t, float64(0), signed.Quantile(0.5))
+	assert.InEpsilon(t, 5, signed.Quantile(1), dto.DefaultRelativeAccuracy)
+}
+
+func TestSummary_ProcessSummaries(t *testing.T) {
+	first := dto.NewSummary(dto.DefaultRelativeAccuracy)
+	second := dto.NewSummary(dto.DefaultRelativeAccuracy)
+	for i := 1; i <= 100; i++ {
+		first.Observe(float64(i))
+		second.Observe(float64(i + 100))
+	}
+
+	summary := NewSummary(nil)
+	_, err := summary.ProcessSummaries(context.Background(), map[string]dto.Summary{"latency": first})
+	assert.NoError(t, err)
+	counts, err := summary.ProcessSummaries(context.Background(), map[string]dto.Summary{"latency": second})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"latency": 200}, counts)
+
+	_, err = summary.ProcessSummaries(context.Background(), map[string]dto.Summary{"latency": dto.NewSummary(0.05)})
+	assert.ErrorIs(t, err, dto.ErrSummaryAccuracy)
+
+	broken := first.Clone()
+	broken.Count++
+	_, err = summary.ProcessSummaries(context.Background(), map[string]dto.Summary{"latency": broken})
+	assert.Error(t, err)
+
+	infinite := first.Clone()
+	infinite.Max = math.Inf(1)
+	_, err = summary.ProcessSummaries(context.Background(), map[string]dto.Summary{"latency": infinite})
+	assert.ErrorIs(t, err, dto.ErrNonFiniteValue)
+
+	items, _ := summary.GetSummaries(context.Background())
+	latency := items["latency"]
+	assert.Equal(t, uint64(200), latency.Count)
+	assert.InEpsilon(t, 100.5, latency.Quantile(0.5), dto.DefaultRelativeAccuracy*1.01)
+	assert.Equal(t, float64(1), latency.Min)
+	assert.Equal(t, float64(200), latency.Max)
+
+	withQuantiles := latency.WithQuantiles([]float64{0.5})
+	assert.Len(t, withQuantiles.Quantiles, 1)
+	assert.Empty(t, latency.Quantiles)
+}
+
+func TestSummary_ProcessDB(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	observed := dto.NewSummary(dto.DefaultRelativeAccuracy)
+	observed.Observe(1)
+	sketch, _ := json.Marshal(observed)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, sketch FROM summary")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "sketch"}))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO summary (tenant, name, labels, sketch) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET sketch = EXCLUDED.sketch")).
+		WithArgs("", "latency", "", sketch).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, sketch FROM summary")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "sketch"}).AddRow("latency", "", sketch))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, sketch FROM summary")).
+		WithArgs("").
+		WillReturnError(errors.New("db error"))
+
+	summary := NewSummary(repositories.NewSummaryRepo(clients.NewPostgres(mock)))
+	assert.NoError(t, summary.Process(context.Background(), "latency", "1"))
+
+	list, err := summary.GetList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"latency": 1}, list)
+
+	_, err = summary.GetList(context.Background())
+	assert.Error(t, err)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+
+	gauge := NewGauge(nil)
+	_ = gauge.ProcessMassive(ctx, map[string]float64{"Alloc": 1, `Alloc{host="web1"}`: 2})
+	assert.NoError(t, gauge.Delete(ctx, []string{`Alloc{host="web1"}`, "Missing"}))
+	list, _ := gauge.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 1}, list)
+
+	histogram := NewHistogram(nil, nil)
+	_ = histogram.Process(ctx, "latency", "0.1")
+	assert.NoError(t, histogram.Delete(ctx, []string{"latency"}))
+	list, _ = histogram.GetList(ctx)
+	assert.Empty(t, list)
+
+	summary := NewSummary(nil)
+	_ = summary.Process(ctx, "latency", "0.1")
+	assert.NoError(t, summary.Delete(ctx, []string{"latency"}))
+	list, _ = summary.GetList(ctx)
+	assert.Empty(t, list)
+}
+
+func TestExpire(t *testing.T) {
+	ctx := context.Background()
+
+	gauge := NewGauge(nil)
+	counter := NewCounter(nil)
+	histogram := NewHistogram(nil, nil)
+	summary := NewSummary(nil)
+	_ = gauge.Process(ctx, "Alloc", "1")
+	_ = counter.Process(ctx, "PollCount", "1")
+	_ = histogram.Process(ctx, "latency", "0.1")
+	_ = summary.Process(ctx, "latency", "0.1")
+
+	for name, metric := range map[string]storages.ExpirableMetric{"gauge": gauge, "counter": counter, "histogram": histogram, "summary": summary} {
+		updated, err := metric.UpdatedAt(ctx)
+		assert.NoError(t, err, name)
+		assert.Len(t, updated, 1, name)
+
+		keys := dto.SeriesKeys(updated)
+		for _, at := range updated {
+			assert.NoError(t, metric.Expire(ctx, keys, at), name)
+			list, _ := metric.GetList(ctx)
+			assert.Len(t, list, 1, name)
+
+			assert.NoError(t, metric.Expire(ctx, keys, at.Add(time.Nanosecond)), name)
+			list, _ = metric.GetList(ctx)
+			assert.Empty(t, list, name)
+		}
+	}
+
+	untracked := NewGauge(struct{ storages.Backend[float64] }{storages.NewMemBackend[float64]()})
+	_, err := untracked.UpdatedAt(ctx)
+	assert.ErrorIs(t, err, storages.ErrUpdatesNotTracked)
+	assert.ErrorIs(t, untracked.Expire(ctx, []string{"Alloc"}, time.Now()), storages.ErrUpdatesNotTracked)
+}
+
+func TestCounter_DeleteAndResetDB(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = counter.value + EXCLUDED.value")).
+		WithArgs("", "test", "", int(100)).
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", int64(100)))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", float64(100)))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value")).
+		WithArgs("", "test", "", int(0)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM counter WHERE tenant = $1 AND (name, labels) IN (($2, $3))")).
+		WithArgs("", "test", "").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	counter := NewCounter(repositories.NewCounterRepo(clients.NewPostgres(mock)))
+	assert.NoError(t, counter.Process(context.Background(), "test", "100"))
+	assert.NoError(t, counter.Reset(context.Background(), []string{"test", "missing"}))
+	assert.NoError(t, counter.Delete(context.Background(), []string{"test"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
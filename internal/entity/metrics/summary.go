@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+)
+
+type Summary struct {
+	updater[dto.Summary]
+	accuracy float64
+}
+
+// Process adds a single observation to the named summary.
+func (s *Summary) Process(ctx context.Context, name string, data string) error {
+	_, err := s.ProcessSample(ctx, name, data)
+	return err
+}
+
+// ProcessSample adds a single observation to the named summary and returns its number of observations.
+func (s *Summary) ProcessSample(ctx context.Context, name string, data string) (float64, error) {
+	floatValue, err := strconv.ParseFloat(data, 64)
+	if err != nil {
+		return 0, errors.New("metric value is not float64")
+	}
+
+	counts, err := s.ProcessSamples(ctx, map[string]float64{name: floatValue})
+	return counts[name], err
+}
+
+// ProcessMassive adds one observation per summary.
+func (s *Summary) ProcessMassive(ctx context.Context, data map[string]float64) error {
+	_, err := s.ProcessSamples(ctx, data)
+	return err
+}
+
+// ProcessSamples adds one observation per summary and returns their numbers of observations.
+func (s *Summary) ProcessSamples(ctx context.Context, data map[string]float64) (map[string]float64, error) {
+	items, err := s.update(ctx, dto.SeriesKeys(data), func(key string, item dto.Summary, ok bool) (dto.Summary, error) {
+		item = s.item(item, ok)
+		if err := item.Observe(data[key]); err != nil {
+			return item, fmt.Errorf("metric %s: %w", key, err)
+		}
+
+		return item, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sampleValues(items, summaryCount), nil
+}
+
+// ProcessSummaries merges sketches into the stored summaries and returns their numbers of observations.
+func (s *Summary) ProcessSummaries(ctx context.Context, data map[string]dto.Summary) (map[string]float64, error) {
+	items, err := s.update(ctx, dto.SeriesKeys(data), func(key string, item dto.Summary, ok bool) (dto.Summary, error) {
+		item = s.item(item, ok)
+		if err := item.Merge(data[key]); err != nil {
+			return item, fmt.Errorf("metric %s: %w", key, err)
+		}
+
+		return item, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sampleValues(items, summaryCount), nil
+}
+
+// GetList returns the number of observations of every summary.
+func (s *Summary) GetList(ctx context.Context) (map[string]float64, error) {
+	items, err := s.GetSummaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sampleValues(items, summaryCount), nil
+}
+
+// GetSummaries returns every stored summary.
+func (s *Summary) GetSummaries(ctx context.Context) (map[string]dto.Summary, error) {
+	return s.backend.GetList(ctx)
+}
+
+// RelativeAccuracy returns the relative accuracy of new summaries.
+func (s *Summary) RelativeAccuracy() float64 {
+	return s.accuracy
+}
+
+// item returns a copy of the stored summary or a new empty one when it doesn't exist.
+func (s *Summary) item(item dto.Summary, ok bool) dto.Summary {
+	if ok {
+		return item.Clone()
+	}
+
+	return dto.NewSummary(s.accuracy)
+}
+
+// Delete removes the series with the keys.
+func (s *Summary) Delete(ctx context.Context, keys []string) error {
+	return s.backend.DeleteMetrics(ctx, keys)
+}
+
+// UpdatedAt returns the last update time of every series.
+func (s *Summary) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return updatedAt(ctx, s.backend)
+}
+
+// Expire removes the series with the keys that weren't updated since before.
+func (s *Summary) Expire(ctx context.Context, keys []string, before time.Time) error {
+	return expire(ctx, s.backend, keys, before)
+}
+
+// NewSummary creates summaries stored in the backend, a nil backend keeps them in memory.
+func NewSummary(backend storages.Backend[dto.Summary]) *Summary {
+	if backend == nil {
+		backend = storages.NewMemBackend[dto.Summary]()
+	}
+
+	return &Summary{
+		updater:  updater[dto.Summary]{backend: backend},
+		accuracy: dto.DefaultRelativeAccuracy,
+	}
+}
+
+// summaryCount returns the number of observations of the summary, the value of the summary in GetList.
+func summaryCount(item dto.Summary) float64 {
+	return float64(item.Count)
+}
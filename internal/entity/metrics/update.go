@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+)
+
+// updater changes series from their current values.
+// Backends that implement storages.Updater do it in one step, the updates of other backends are serialized by the mutex.
+type updater[V any] struct {
+	mu      sync.Mutex
+	backend storages.Backend[V]
+}
+
+// update replaces the series of the keys with the result of fn and returns the stored values.
+func (u *updater[V]) update(ctx context.Context, keys []string, fn storages.UpdateFunc[V]) (map[string]V, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	changed := make(map[string]V, len(keys))
+	if backend, ok := u.backend.(storages.Updater[V]); ok {
+		err := backend.UpdateMetrics(ctx, keys, func(key string, value V, ok bool) (V, error) {
+			value, err := fn(key, value, ok)
+			changed[key] = value
+			return value, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return changed, nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	items, err := u.backend.GetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		value, ok := changed[key]
+		if !ok {
+			value, ok = items[key]
+		}
+
+		if changed[key], err = fn(key, value, ok); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.backend.AddMetrics(ctx, changed); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// sampleValues returns the values of the items as GetList of their entity reports them.
+func sampleValues[V any](items map[string]V, value func(V) float64) map[string]float64 {
+	values := make(map[string]float64, len(items))
+	for key, item := range items {
+		values[key] = value(item)
+	}
+
+	return values
+}
+
+// getItem returns the series with the key from a backend that implements storages.ItemGetter, other backends are listed.
+func getItem[V any](ctx context.Context, backend storages.Backend[V], key string) (V, bool, error) {
+	if getter, ok := backend.(storages.ItemGetter[V]); ok {
+		value, err := getter.GetItem(ctx, key)
+		if errors.Is(err, storages.ErrSeriesNotFound) {
+			return value, false, nil
+		}
+
+		return value, err == nil, err
+	}
+
+	items, err := backend.GetList(ctx)
+	if err != nil {
+		var value V
+		return value, false, err
+	}
+
+	value, ok := items[key]
+	return value, ok, nil
+}
+
+// updatedAt returns the last update time of every series of a backend that implements storages.Expirer.
+func updatedAt(ctx context.Context, backend any) (map[string]time.Time, error) {
+	expirer, ok := backend.(storages.Expirer)
+	if !ok {
+		return nil, storages.ErrUpdatesNotTracked
+	}
+
+	return expirer.UpdatedAt(ctx)
+}
+
+// expire deletes the series with the keys that weren't updated since before from a backend that implements storages.Expirer.
+func expire(ctx context.Context, backend any, keys []string, before time.Time) error {
+	expirer, ok := backend.(storages.Expirer)
+	if !ok {
+		return storages.ErrUpdatesNotTracked
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return expirer.ExpireMetrics(ctx, keys, before)
+}
@@ -12,7 +12,9 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	dto "github.com/AnatolySnegovskiy/metric/internal/services/dto"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,6 +41,20 @@ func (m *MockEntityMetric) EXPECT() *MockEntityMetricMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockEntityMetric) Delete(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockEntityMetricMockRecorder) Delete(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockEntityMetric)(nil).Delete), ctx, keys)
+}
+
 // GetList mocks base method.
 func (m *MockEntityMetric) GetList(ctx context.Context) (map[string]float64, error) {
 	m.ctrl.T.Helper()
@@ -81,3 +97,660 @@ func (mr *MockEntityMetricMockRecorder) ProcessMassive(ctx, data any) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMassive", reflect.TypeOf((*MockEntityMetric)(nil).ProcessMassive), ctx, data)
 }
+
+// MockResettableMetric is a mock of ResettableMetric interface.
+type MockResettableMetric struct {
+	ctrl     *gomock.Controller
+	recorder *MockResettableMetricMockRecorder
+}
+
+// MockResettableMetricMockRecorder is the mock recorder for MockResettableMetric.
+type MockResettableMetricMockRecorder struct {
+	mock *MockResettableMetric
+}
+
+// NewMockResettableMetric creates a new mock instance.
+func NewMockResettableMetric(ctrl *gomock.Controller) *MockResettableMetric {
+	mock := &MockResettableMetric{ctrl: ctrl}
+	mock.recorder = &MockResettableMetricMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResettableMetric) EXPECT() *MockResettableMetricMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockResettableMetric) Delete(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockResettableMetricMockRecorder) Delete(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockResettableMetric)(nil).Delete), ctx, keys)
+}
+
+// GetList mocks base method.
+func (m *MockResettableMetric) GetList(ctx context.Context) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", ctx)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockResettableMetricMockRecorder) GetList(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockResettableMetric)(nil).GetList), ctx)
+}
+
+// Process mocks base method.
+func (m *MockResettableMetric) Process(ctx context.Context, name, data string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", ctx, name, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockResettableMetricMockRecorder) Process(ctx, name, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockResettableMetric)(nil).Process), ctx, name, data)
+}
+
+// ProcessMassive mocks base method.
+func (m *MockResettableMetric) ProcessMassive(ctx context.Context, data map[string]float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMassive", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMassive indicates an expected call of ProcessMassive.
+func (mr *MockResettableMetricMockRecorder) ProcessMassive(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMassive", reflect.TypeOf((*MockResettableMetric)(nil).ProcessMassive), ctx, data)
+}
+
+// Reset mocks base method.
+func (m *MockResettableMetric) Reset(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockResettableMetricMockRecorder) Reset(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockResettableMetric)(nil).Reset), ctx, keys)
+}
+
+// MockExpirableMetric is a mock of ExpirableMetric interface.
+type MockExpirableMetric struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpirableMetricMockRecorder
+}
+
+// MockExpirableMetricMockRecorder is the mock recorder for MockExpirableMetric.
+type MockExpirableMetricMockRecorder struct {
+	mock *MockExpirableMetric
+}
+
+// NewMockExpirableMetric creates a new mock instance.
+func NewMockExpirableMetric(ctrl *gomock.Controller) *MockExpirableMetric {
+	mock := &MockExpirableMetric{ctrl: ctrl}
+	mock.recorder = &MockExpirableMetricMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpirableMetric) EXPECT() *MockExpirableMetricMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockExpirableMetric) Delete(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockExpirableMetricMockRecorder) Delete(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockExpirableMetric)(nil).Delete), ctx, keys)
+}
+
+// Expire mocks base method.
+func (m *MockExpirableMetric) Expire(ctx context.Context, keys []string, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", ctx, keys, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockExpirableMetricMockRecorder) Expire(ctx, keys, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockExpirableMetric)(nil).Expire), ctx, keys, before)
+}
+
+// GetList mocks base method.
+func (m *MockExpirableMetric) GetList(ctx context.Context) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", ctx)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockExpirableMetricMockRecorder) GetList(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockExpirableMetric)(nil).GetList), ctx)
+}
+
+// Process mocks base method.
+func (m *MockExpirableMetric) Process(ctx context.Context, name, data string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", ctx, name, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockExpirableMetricMockRecorder) Process(ctx, name, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockExpirableMetric)(nil).Process), ctx, name, data)
+}
+
+// ProcessMassive mocks base method.
+func (m *MockExpirableMetric) ProcessMassive(ctx context.Context, data map[string]float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMassive", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMassive indicates an expected call of ProcessMassive.
+func (mr *MockExpirableMetricMockRecorder) ProcessMassive(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMassive", reflect.TypeOf((*MockExpirableMetric)(nil).ProcessMassive), ctx, data)
+}
+
+// UpdatedAt mocks base method.
+func (m *MockExpirableMetric) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatedAt", ctx)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatedAt indicates an expected call of UpdatedAt.
+func (mr *MockExpirableMetricMockRecorder) UpdatedAt(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedAt", reflect.TypeOf((*MockExpirableMetric)(nil).UpdatedAt), ctx)
+}
+
+// MockItemMetric is a mock of ItemMetric interface.
+type MockItemMetric struct {
+	ctrl     *gomock.Controller
+	recorder *MockItemMetricMockRecorder
+}
+
+// MockItemMetricMockRecorder is the mock recorder for MockItemMetric.
+type MockItemMetricMockRecorder struct {
+	mock *MockItemMetric
+}
+
+// NewMockItemMetric creates a new mock instance.
+func NewMockItemMetric(ctrl *gomock.Controller) *MockItemMetric {
+	mock := &MockItemMetric{ctrl: ctrl}
+	mock.recorder = &MockItemMetricMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockItemMetric) EXPECT() *MockItemMetricMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockItemMetric) Delete(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockItemMetricMockRecorder) Delete(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockItemMetric)(nil).Delete), ctx, keys)
+}
+
+// GetItem mocks base method.
+func (m *MockItemMetric) GetItem(ctx context.Context, key string) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", ctx, key)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockItemMetricMockRecorder) GetItem(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockItemMetric)(nil).GetItem), ctx, key)
+}
+
+// GetList mocks base method.
+func (m *MockItemMetric) GetList(ctx context.Context) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", ctx)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockItemMetricMockRecorder) GetList(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockItemMetric)(nil).GetList), ctx)
+}
+
+// Process mocks base method.
+func (m *MockItemMetric) Process(ctx context.Context, name, data string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", ctx, name, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockItemMetricMockRecorder) Process(ctx, name, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockItemMetric)(nil).Process), ctx, name, data)
+}
+
+// ProcessMassive mocks base method.
+func (m *MockItemMetric) ProcessMassive(ctx context.Context, data map[string]float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMassive", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMassive indicates an expected call of ProcessMassive.
+func (mr *MockItemMetricMockRecorder) ProcessMassive(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMassive", reflect.TypeOf((*MockItemMetric)(nil).ProcessMassive), ctx, data)
+}
+
+// MockSampledMetric is a mock of SampledMetric interface.
+type MockSampledMetric struct {
+	ctrl     *gomock.Controller
+	recorder *MockSampledMetricMockRecorder
+}
+
+// MockSampledMetricMockRecorder is the mock recorder for MockSampledMetric.
+type MockSampledMetricMockRecorder struct {
+	mock *MockSampledMetric
+}
+
+// NewMockSampledMetric creates a new mock instance.
+func NewMockSampledMetric(ctrl *gomock.Controller) *MockSampledMetric {
+	mock := &MockSampledMetric{ctrl: ctrl}
+	mock.recorder = &MockSampledMetricMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSampledMetric) EXPECT() *MockSampledMetricMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockSampledMetric) Delete(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSampledMetricMockRecorder) Delete(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSampledMetric)(nil).Delete), ctx, keys)
+}
+
+// GetList mocks base method.
+func (m *MockSampledMetric) GetList(ctx context.Context) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", ctx)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockSampledMetricMockRecorder) GetList(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockSampledMetric)(nil).GetList), ctx)
+}
+
+// Process mocks base method.
+func (m *MockSampledMetric) Process(ctx context.Context, name, data string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", ctx, name, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockSampledMetricMockRecorder) Process(ctx, name, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockSampledMetric)(nil).Process), ctx, name, data)
+}
+
+// ProcessMassive mocks base method.
+func (m *MockSampledMetric) ProcessMassive(ctx context.Context, data map[string]float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMassive", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMassive indicates an expected call of ProcessMassive.
+func (mr *MockSampledMetricMockRecorder) ProcessMassive(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMassive", reflect.TypeOf((*MockSampledMetric)(nil).ProcessMassive), ctx, data)
+}
+
+// ProcessSample mocks base method.
+func (m *MockSampledMetric) ProcessSample(ctx context.Context, name, data string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessSample", ctx, name, data)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessSample indicates an expected call of ProcessSample.
+func (mr *MockSampledMetricMockRecorder) ProcessSample(ctx, name, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSample", reflect.TypeOf((*MockSampledMetric)(nil).ProcessSample), ctx, name, data)
+}
+
+// ProcessSamples mocks base method.
+func (m *MockSampledMetric) ProcessSamples(ctx context.Context, data map[string]float64) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessSamples", ctx, data)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessSamples indicates an expected call of ProcessSamples.
+func (mr *MockSampledMetricMockRecorder) ProcessSamples(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSamples", reflect.TypeOf((*MockSampledMetric)(nil).ProcessSamples), ctx, data)
+}
+
+// MockHistogramMetric is a mock of HistogramMetric interface.
+type MockHistogramMetric struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistogramMetricMockRecorder
+}
+
+// MockHistogramMetricMockRecorder is the mock recorder for MockHistogramMetric.
+type MockHistogramMetricMockRecorder struct {
+	mock *MockHistogramMetric
+}
+
+// NewMockHistogramMetric creates a new mock instance.
+func NewMockHistogramMetric(ctrl *gomock.Controller) *MockHistogramMetric {
+	mock := &MockHistogramMetric{ctrl: ctrl}
+	mock.recorder = &MockHistogramMetricMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistogramMetric) EXPECT() *MockHistogramMetricMockRecorder {
+	return m.recorder
+}
+
+// Bounds mocks base method.
+func (m *MockHistogramMetric) Bounds() []float64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bounds")
+	ret0, _ := ret[0].([]float64)
+	return ret0
+}
+
+// Bounds indicates an expected call of Bounds.
+func (mr *MockHistogramMetricMockRecorder) Bounds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bounds", reflect.TypeOf((*MockHistogramMetric)(nil).Bounds))
+}
+
+// Delete mocks base method.
+func (m *MockHistogramMetric) Delete(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockHistogramMetricMockRecorder) Delete(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHistogramMetric)(nil).Delete), ctx, keys)
+}
+
+// GetHistograms mocks base method.
+func (m *MockHistogramMetric) GetHistograms(ctx context.Context) (map[string]dto.Histogram, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistograms", ctx)
+	ret0, _ := ret[0].(map[string]dto.Histogram)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistograms indicates an expected call of GetHistograms.
+func (mr *MockHistogramMetricMockRecorder) GetHistograms(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistograms", reflect.TypeOf((*MockHistogramMetric)(nil).GetHistograms), ctx)
+}
+
+// GetList mocks base method.
+func (m *MockHistogramMetric) GetList(ctx context.Context) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", ctx)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockHistogramMetricMockRecorder) GetList(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockHistogramMetric)(nil).GetList), ctx)
+}
+
+// Process mocks base method.
+func (m *MockHistogramMetric) Process(ctx context.Context, name, data string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", ctx, name, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockHistogramMetricMockRecorder) Process(ctx, name, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockHistogramMetric)(nil).Process), ctx, name, data)
+}
+
+// ProcessHistograms mocks base method.
+func (m *MockHistogramMetric) ProcessHistograms(ctx context.Context, data map[string]dto.Histogram) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessHistograms", ctx, data)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessHistograms indicates an expected call of ProcessHistograms.
+func (mr *MockHistogramMetricMockRecorder) ProcessHistograms(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessHistograms", reflect.TypeOf((*MockHistogramMetric)(nil).ProcessHistograms), ctx, data)
+}
+
+// ProcessMassive mocks base method.
+func (m *MockHistogramMetric) ProcessMassive(ctx context.Context, data map[string]float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMassive", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMassive indicates an expected call of ProcessMassive.
+func (mr *MockHistogramMetricMockRecorder) ProcessMassive(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMassive", reflect.TypeOf((*MockHistogramMetric)(nil).ProcessMassive), ctx, data)
+}
+
+// MockSummaryMetric is a mock of SummaryMetric interface.
+type MockSummaryMetric struct {
+	ctrl     *gomock.Controller
+	recorder *MockSummaryMetricMockRecorder
+}
+
+// MockSummaryMetricMockRecorder is the mock recorder for MockSummaryMetric.
+type MockSummaryMetricMockRecorder struct {
+	mock *MockSummaryMetric
+}
+
+// NewMockSummaryMetric creates a new mock instance.
+func NewMockSummaryMetric(ctrl *gomock.Controller) *MockSummaryMetric {
+	mock := &MockSummaryMetric{ctrl: ctrl}
+	mock.recorder = &MockSummaryMetricMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSummaryMetric) EXPECT() *MockSummaryMetricMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockSummaryMetric) Delete(ctx context.Context, keys []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSummaryMetricMockRecorder) Delete(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSummaryMetric)(nil).Delete), ctx, keys)
+}
+
+// GetList mocks base method.
+func (m *MockSummaryMetric) GetList(ctx context.Context) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", ctx)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockSummaryMetricMockRecorder) GetList(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockSummaryMetric)(nil).GetList), ctx)
+}
+
+// GetSummaries mocks base method.
+func (m *MockSummaryMetric) GetSummaries(ctx context.Context) (map[string]dto.Summary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSummaries", ctx)
+	ret0, _ := ret[0].(map[string]dto.Summary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSummaries indicates an expected call of GetSummaries.
+func (mr *MockSummaryMetricMockRecorder) GetSummaries(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSummaries", reflect.TypeOf((*MockSummaryMetric)(nil).GetSummaries), ctx)
+}
+
+// Process mocks base method.
+func (m *MockSummaryMetric) Process(ctx context.Context, name, data string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", ctx, name, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockSummaryMetricMockRecorder) Process(ctx, name, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockSummaryMetric)(nil).Process), ctx, name, data)
+}
+
+// ProcessMassive mocks base method.
+func (m *MockSummaryMetric) ProcessMassive(ctx context.Context, data map[string]float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMassive", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMassive indicates an expected call of ProcessMassive.
+func (mr *MockSummaryMetricMockRecorder) ProcessMassive(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMassive", reflect.TypeOf((*MockSummaryMetric)(nil).ProcessMassive), ctx, data)
+}
+
+// ProcessSummaries mocks base method.
+func (m *MockSummaryMetric) ProcessSummaries(ctx context.Context, data map[string]dto.Summary) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessSummaries", ctx, data)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessSummaries indicates an expected call of ProcessSummaries.
+func (mr *MockSummaryMetricMockRecorder) ProcessSummaries(ctx, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSummaries", reflect.TypeOf((*MockSummaryMetric)(nil).ProcessSummaries), ctx, data)
+}
+
+// RelativeAccuracy mocks base method.
+func (m *MockSummaryMetric) RelativeAccuracy() float64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelativeAccuracy")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+// RelativeAccuracy indicates an expected call of RelativeAccuracy.
+func (mr *MockSummaryMetricMockRecorder) RelativeAccuracy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelativeAccuracy", reflect.TypeOf((*MockSummaryMetric)(nil).RelativeAccuracy))
+}
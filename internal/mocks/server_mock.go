@@ -38,6 +38,90 @@ func (m *MockConfig) EXPECT() *MockConfigMockRecorder {
 	return m.recorder
 }
 
+// GetAdminToken mocks base method.
+func (m *MockConfig) GetAdminToken() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdminToken")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetAdminToken indicates an expected call of GetAdminToken.
+func (mr *MockConfigMockRecorder) GetAdminToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdminToken", reflect.TypeOf((*MockConfig)(nil).GetAdminToken))
+}
+
+// GetAlertInterval mocks base method.
+func (m *MockConfig) GetAlertInterval() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertInterval")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetAlertInterval indicates an expected call of GetAlertInterval.
+func (mr *MockConfigMockRecorder) GetAlertInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertInterval", reflect.TypeOf((*MockConfig)(nil).GetAlertInterval))
+}
+
+// GetAlertRulesFile mocks base method.
+func (m *MockConfig) GetAlertRulesFile() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertRulesFile")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetAlertRulesFile indicates an expected call of GetAlertRulesFile.
+func (mr *MockConfigMockRecorder) GetAlertRulesFile() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertRulesFile", reflect.TypeOf((*MockConfig)(nil).GetAlertRulesFile))
+}
+
+// GetAlertWebhook mocks base method.
+func (m *MockConfig) GetAlertWebhook() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertWebhook")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetAlertWebhook indicates an expected call of GetAlertWebhook.
+func (mr *MockConfigMockRecorder) GetAlertWebhook() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertWebhook", reflect.TypeOf((*MockConfig)(nil).GetAlertWebhook))
+}
+
+// GetBoltPath mocks base method.
+func (m *MockConfig) GetBoltPath() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoltPath")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetBoltPath indicates an expected call of GetBoltPath.
+func (mr *MockConfigMockRecorder) GetBoltPath() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoltPath", reflect.TypeOf((*MockConfig)(nil).GetBoltPath))
+}
+
+// GetCacheStaleness mocks base method.
+func (m *MockConfig) GetCacheStaleness() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCacheStaleness")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetCacheStaleness indicates an expected call of GetCacheStaleness.
+func (mr *MockConfigMockRecorder) GetCacheStaleness() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCacheStaleness", reflect.TypeOf((*MockConfig)(nil).GetCacheStaleness))
+}
+
 // GetCryptoKey mocks base method.
 func (m *MockConfig) GetCryptoKey() string {
 	m.ctrl.T.Helper()
@@ -52,6 +136,62 @@ func (mr *MockConfigMockRecorder) GetCryptoKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCryptoKey", reflect.TypeOf((*MockConfig)(nil).GetCryptoKey))
 }
 
+// GetDBConnectTimeout mocks base method.
+func (m *MockConfig) GetDBConnectTimeout() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDBConnectTimeout")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetDBConnectTimeout indicates an expected call of GetDBConnectTimeout.
+func (mr *MockConfigMockRecorder) GetDBConnectTimeout() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDBConnectTimeout", reflect.TypeOf((*MockConfig)(nil).GetDBConnectTimeout))
+}
+
+// GetDBMaxConnIdleTime mocks base method.
+func (m *MockConfig) GetDBMaxConnIdleTime() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDBMaxConnIdleTime")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetDBMaxConnIdleTime indicates an expected call of GetDBMaxConnIdleTime.
+func (mr *MockConfigMockRecorder) GetDBMaxConnIdleTime() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDBMaxConnIdleTime", reflect.TypeOf((*MockConfig)(nil).GetDBMaxConnIdleTime))
+}
+
+// GetDBMaxConns mocks base method.
+func (m *MockConfig) GetDBMaxConns() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDBMaxConns")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetDBMaxConns indicates an expected call of GetDBMaxConns.
+func (mr *MockConfigMockRecorder) GetDBMaxConns() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDBMaxConns", reflect.TypeOf((*MockConfig)(nil).GetDBMaxConns))
+}
+
+// GetDBMinConns mocks base method.
+func (m *MockConfig) GetDBMinConns() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDBMinConns")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetDBMinConns indicates an expected call of GetDBMinConns.
+func (mr *MockConfigMockRecorder) GetDBMinConns() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDBMinConns", reflect.TypeOf((*MockConfig)(nil).GetDBMinConns))
+}
+
 // GetDataBaseDSN mocks base method.
 func (m *MockConfig) GetDataBaseDSN() string {
 	m.ctrl.T.Helper()
@@ -80,6 +220,48 @@ func (mr *MockConfigMockRecorder) GetFileStoragePath() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileStoragePath", reflect.TypeOf((*MockConfig)(nil).GetFileStoragePath))
 }
 
+// GetGRPCAddress mocks base method.
+func (m *MockConfig) GetGRPCAddress() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGRPCAddress")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetGRPCAddress indicates an expected call of GetGRPCAddress.
+func (mr *MockConfigMockRecorder) GetGRPCAddress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGRPCAddress", reflect.TypeOf((*MockConfig)(nil).GetGRPCAddress))
+}
+
+// GetHistogramBuckets mocks base method.
+func (m *MockConfig) GetHistogramBuckets() []float64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistogramBuckets")
+	ret0, _ := ret[0].([]float64)
+	return ret0
+}
+
+// GetHistogramBuckets indicates an expected call of GetHistogramBuckets.
+func (mr *MockConfigMockRecorder) GetHistogramBuckets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistogramBuckets", reflect.TypeOf((*MockConfig)(nil).GetHistogramBuckets))
+}
+
+// GetHistorySize mocks base method.
+func (m *MockConfig) GetHistorySize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistorySize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetHistorySize indicates an expected call of GetHistorySize.
+func (mr *MockConfigMockRecorder) GetHistorySize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistorySize", reflect.TypeOf((*MockConfig)(nil).GetHistorySize))
+}
+
 // GetMigrationsDir mocks base method.
 func (m *MockConfig) GetMigrationsDir() string {
 	m.ctrl.T.Helper()
@@ -108,6 +290,34 @@ func (mr *MockConfigMockRecorder) GetRestore() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestore", reflect.TypeOf((*MockConfig)(nil).GetRestore))
 }
 
+// GetRetention mocks base method.
+func (m *MockConfig) GetRetention() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRetention")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetRetention indicates an expected call of GetRetention.
+func (mr *MockConfigMockRecorder) GetRetention() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRetention", reflect.TypeOf((*MockConfig)(nil).GetRetention))
+}
+
+// GetRetentionInterval mocks base method.
+func (m *MockConfig) GetRetentionInterval() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRetentionInterval")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetRetentionInterval indicates an expected call of GetRetentionInterval.
+func (mr *MockConfigMockRecorder) GetRetentionInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRetentionInterval", reflect.TypeOf((*MockConfig)(nil).GetRetentionInterval))
+}
+
 // GetServerAddress mocks base method.
 func (m *MockConfig) GetServerAddress() string {
 	m.ctrl.T.Helper()
@@ -136,6 +346,34 @@ func (mr *MockConfigMockRecorder) GetShaKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShaKey", reflect.TypeOf((*MockConfig)(nil).GetShaKey))
 }
 
+// GetSkipMigrate mocks base method.
+func (m *MockConfig) GetSkipMigrate() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSkipMigrate")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetSkipMigrate indicates an expected call of GetSkipMigrate.
+func (mr *MockConfigMockRecorder) GetSkipMigrate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSkipMigrate", reflect.TypeOf((*MockConfig)(nil).GetSkipMigrate))
+}
+
+// GetStorage mocks base method.
+func (m *MockConfig) GetStorage() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStorage")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetStorage indicates an expected call of GetStorage.
+func (mr *MockConfigMockRecorder) GetStorage() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorage", reflect.TypeOf((*MockConfig)(nil).GetStorage))
+}
+
 // GetStoreInterval mocks base method.
 func (m *MockConfig) GetStoreInterval() int {
 	m.ctrl.T.Helper()
@@ -149,3 +387,17 @@ func (mr *MockConfigMockRecorder) GetStoreInterval() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStoreInterval", reflect.TypeOf((*MockConfig)(nil).GetStoreInterval))
 }
+
+// GetTenantsFile mocks base method.
+func (m *MockConfig) GetTenantsFile() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantsFile")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetTenantsFile indicates an expected call of GetTenantsFile.
+func (mr *MockConfigMockRecorder) GetTenantsFile() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantsFile", reflect.TypeOf((*MockConfig)(nil).GetTenantsFile))
+}
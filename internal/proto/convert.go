@@ -0,0 +1,131 @@
+package proto
+
+import "github.com/AnatolySnegovskiy/metric/internal/services/dto"
+
+// FromDTO converts a metric DTO to its gRPC representation.
+func FromDTO(m dto.Metrics) *Metric {
+	metric := &Metric{
+		Id:     m.ID,
+		Type:   m.MType,
+		Delta:  m.Delta,
+		Value:  m.Value,
+		Labels: m.Labels,
+	}
+
+	if m.Histogram != nil {
+		metric.Histogram = &Histogram{
+			Bounds: m.Histogram.Bounds,
+			Counts: m.Histogram.Counts,
+			Sum:    m.Histogram.Sum,
+			Count:  m.Histogram.Count,
+		}
+	}
+
+	if m.Summary != nil {
+		metric.Summary = fromSummary(m.Summary)
+	}
+
+	return metric
+}
+
+// ToDTO converts a gRPC metric to the DTO shared with the HTTP API.
+func ToDTO(m *Metric) dto.Metrics {
+	metric := dto.Metrics{
+		ID:     m.GetId(),
+		MType:  m.GetType(),
+		Delta:  m.Delta,
+		Value:  m.Value,
+		Labels: m.GetLabels(),
+	}
+
+	if h := m.GetHistogram(); h != nil {
+		metric.Histogram = &dto.Histogram{
+			Bounds: h.GetBounds(),
+			Counts: h.GetCounts(),
+			Sum:    h.GetSum(),
+			Count:  h.GetCount(),
+		}
+	}
+
+	if sm := m.GetSummary(); sm != nil {
+		metric.Summary = toSummary(sm)
+	}
+
+	return metric
+}
+
+// FromCollection converts a collection of metric DTOs to gRPC metrics.
+func FromCollection(collection dto.MetricsCollection) []*Metric {
+	list := make([]*Metric, 0, len(collection))
+	for _, m := range collection {
+		list = append(list, FromDTO(m))
+	}
+
+	return list
+}
+
+// ToCollection converts gRPC metrics to a collection of metric DTOs.
+func ToCollection(list []*Metric) dto.MetricsCollection {
+	collection := make(dto.MetricsCollection, 0, len(list))
+	for _, m := range list {
+		collection = append(collection, ToDTO(m))
+	}
+
+	return collection
+}
+
+func fromSummary(s *dto.Summary) *Summary {
+	summary := &Summary{
+		RelativeAccuracy: s.RelativeAccuracy,
+		Positive:         fromBins(s.Positive),
+		Negative:         fromBins(s.Negative),
+		Zero:             s.Zero,
+		Sum:              s.Sum,
+		Count:            s.Count,
+		Min:              s.Min,
+		Max:              s.Max,
+	}
+
+	for _, q := range s.Quantiles {
+		summary.Quantiles = append(summary.Quantiles, &Quantile{Quantile: q.Quantile, Value: q.Value})
+	}
+
+	return summary
+}
+
+func toSummary(s *Summary) *dto.Summary {
+	summary := &dto.Summary{
+		RelativeAccuracy: s.GetRelativeAccuracy(),
+		Positive:         toBins(s.GetPositive()),
+		Negative:         toBins(s.GetNegative()),
+		Zero:             s.GetZero(),
+		Sum:              s.GetSum(),
+		Count:            s.GetCount(),
+		Min:              s.GetMin(),
+		Max:              s.GetMax(),
+	}
+
+	for _, q := range s.GetQuantiles() {
+		summary.Quantiles = append(summary.Quantiles, dto.Quantile{Quantile: q.GetQuantile(), Value: q.GetValue()})
+	}
+
+	return summary
+}
+
+func fromBins(bins []dto.SketchBin) []*SketchBin {
+	list := make([]*SketchBin, 0, len(bins))
+	for _, bin := range bins {
+		list = append(list, &SketchBin{Index: bin.Index, Count: bin.Count})
+	}
+
+	return list
+}
+
+func toBins(list []*SketchBin) []dto.SketchBin {
+	bins := make([]dto.SketchBin, 0, len(list))
+	for _, bin := range list {
+		bins = append(bins, dto.SketchBin{Index: bin.GetIndex(), Count: bin.GetCount()})
+	}
+
+	return bins
+}
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltRepo keeps the series of a metric type in a bucket of the embedded database.
+// The values are stored as JSON keyed by the series key, their update times are kept in the bucket with the _updated suffix.
+type BoltRepo[V any] struct {
+	db      *clients.Bolt
+	bucket  []byte
+	updated []byte
+}
+
+// NewBoltRepo creates the repository of the bucket, the bucket must exist in the database.
+// The bucket of the update times is created with the first write.
+func NewBoltRepo[V any](db *clients.Bolt, bucket string) *BoltRepo[V] {
+	return &BoltRepo[V]{
+		db:      db,
+		bucket:  []byte(bucket),
+		updated: []byte(bucket + "_updated"),
+	}
+}
+
+func (b *BoltRepo[V]) GetItem(_ context.Context, key string) (V, error) {
+	var value V
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var raw []byte
+		if bucket := tx.Bucket(b.bucket); bucket != nil {
+			raw = bucket.Get([]byte(key))
+		}
+		if raw == nil {
+			return fmt.Errorf("metric %s: %w", key, storages.ErrSeriesNotFound)
+		}
+
+		return json.Unmarshal(raw, &value)
+	})
+
+	return value, err
+}
+
+func (b *BoltRepo[V]) GetList(_ context.Context) (map[string]V, error) {
+	items := make(map[string]V)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(key, raw []byte) error {
+			var value V
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("%s %s: %w", b.bucket, key, err)
+			}
+			items[string(key)] = value
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (b *BoltRepo[V]) AddMetric(ctx context.Context, key string, value V) error {
+	return b.AddMetrics(ctx, map[string]V{key: value})
+}
+
+// AddMetrics writes every item in a single transaction, none of them is written when one fails.
+func (b *BoltRepo[V]) AddMetrics(_ context.Context, metrics map[string]V) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		updated, at, err := b.updatedBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range metrics {
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", b.bucket, key, err)
+			}
+
+			if err := bucket.Put([]byte(key), raw); err != nil {
+				return err
+			}
+
+			if err := updated.Put([]byte(key), at); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteMetrics deletes the series with the keys.
+func (b *BoltRepo[V]) DeleteMetrics(_ context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		updated := tx.Bucket(b.updated)
+		for _, key := range keys {
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+
+			if updated != nil {
+				if err := updated.Delete([]byte(key)); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdateMetrics reads and replaces the series of the keys in a single transaction.
+func (b *BoltRepo[V]) UpdateMetrics(_ context.Context, keys []string, fn storages.UpdateFunc[V]) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		updated, at, err := b.updatedBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			var value V
+			raw := bucket.Get([]byte(key))
+			if raw != nil {
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return fmt.Errorf("%s %s: %w", b.bucket, key, err)
+				}
+			}
+
+			value, err := fn(key, value, raw != nil)
+			if err != nil {
+				return err
+			}
+
+			if raw, err = json.Marshal(value); err != nil {
+				return fmt.Errorf("%s %s: %w", b.bucket, key, err)
+			}
+
+			if err := bucket.Put([]byte(key), raw); err != nil {
+				return err
+			}
+
+			if err := updated.Put([]byte(key), at); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdatedAt returns the last update time of every series.
+func (b *BoltRepo[V]) UpdatedAt(_ context.Context) (map[string]time.Time, error) {
+	updated := make(map[string]time.Time)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.updated)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(key, raw []byte) error {
+			var at time.Time
+			if err := at.UnmarshalText(raw); err != nil {
+				return fmt.Errorf("%s %s: %w", b.updated, key, err)
+			}
+			updated[string(key)] = at
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// ExpireMetrics deletes the series with the keys that weren't updated since before in a single transaction.
+func (b *BoltRepo[V]) ExpireMetrics(_ context.Context, keys []string, before time.Time) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		updated := tx.Bucket(b.updated)
+		if updated == nil {
+			return nil
+		}
+
+		for _, key := range keys {
+			raw := updated.Get([]byte(key))
+			if raw == nil {
+				continue
+			}
+
+			var at time.Time
+			if err := at.UnmarshalText(raw); err != nil {
+				return fmt.Errorf("%s %s: %w", b.updated, key, err)
+			}
+			if !at.Before(before) {
+				continue
+			}
+
+			if err := bucket.Delete([]byte(key)); err != nil {
+				return err
+			}
+
+			if err := updated.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// updatedBucket returns the bucket of the update times, created when missing, with the current time encoded for it.
+func (b *BoltRepo[V]) updatedBucket(tx *bolt.Tx) (*bolt.Bucket, []byte, error) {
+	updated, err := tx.CreateBucketIfNotExists(b.updated)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	at, err := time.Now().MarshalText()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return updated, at, nil
+}
@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// seriesRepo is the part of a repository of float series shared by every storage.
+type seriesRepo interface {
+	GetItem(ctx context.Context, key string) (float64, error)
+	GetList(ctx context.Context) (map[string]float64, error)
+	AddMetric(ctx context.Context, key string, value float64) error
+	AddMetrics(ctx context.Context, metrics map[string]float64) error
+	DeleteMetrics(ctx context.Context, keys []string) error
+}
+
+// testSeriesRepo checks the behaviour every repository of float series must have.
+func testSeriesRepo(t *testing.T, repo seriesRepo) {
+	ctx := context.Background()
+	labeled := `repo_test{host="web1"}`
+	defer func() { _ = repo.DeleteMetrics(ctx, []string{"repo_test", labeled, "repo_test_other"}) }()
+
+	assert.NoError(t, repo.AddMetric(ctx, "repo_test", 1.5))
+	value, err := repo.GetItem(ctx, "repo_test")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+
+	assert.NoError(t, repo.AddMetrics(ctx, map[string]float64{"repo_test": 2.5, labeled: 3, "repo_test_other": 4}))
+	assert.NoError(t, repo.AddMetrics(ctx, nil))
+	list, err := repo.GetList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, list["repo_test"])
+	assert.Equal(t, float64(3), list[labeled])
+	assert.Equal(t, float64(4), list["repo_test_other"])
+
+	assert.NoError(t, repo.DeleteMetrics(ctx, []string{labeled, "repo_test_missing"}))
+	assert.NoError(t, repo.DeleteMetrics(ctx, nil))
+	list, err = repo.GetList(ctx)
+	assert.NoError(t, err)
+	assert.NotContains(t, list, labeled)
+	assert.Contains(t, list, "repo_test")
+
+	_, err = repo.GetItem(ctx, labeled)
+	assert.ErrorIs(t, err, storages.ErrSeriesNotFound)
+}
+
+func TestBoltRepo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.bolt")
+	db, err := clients.OpenBolt(path, "gauge", "counter", "histogram")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("gauge", func(t *testing.T) {
+		testSeriesRepo(t, NewBoltRepo[float64](db, "gauge"))
+	})
+
+	t.Run("counter", func(t *testing.T) {
+		testSeriesRepo(t, NewBoltRepo[float64](db, "counter"))
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		repo := NewBoltRepo[float64](db, "gauge")
+		assert.Error(t, repo.AddMetrics(context.Background(), map[string]float64{"valid": 1, "invalid": math.NaN()}))
+
+		list, err := repo.GetList(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, list)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := NewBoltRepo[float64](db, "counter")
+		add := func(_ string, value float64, _ bool) (float64, error) { return value + 1, nil }
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 25; j++ {
+					assert.NoError(t, repo.UpdateMetrics(context.Background(), []string{"PollCount"}, add))
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Error(t, repo.UpdateMetrics(context.Background(), []string{"PollCount"}, func(string, float64, bool) (float64, error) {
+			return 0, errors.New("update failed")
+		}))
+
+		value, err := repo.GetItem(context.Background(), "PollCount")
+		assert.NoError(t, err)
+		assert.Equal(t, float64(200), value)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		ctx := context.Background()
+		repo := NewBoltRepo[float64](db, "gauge")
+		assert.NoError(t, repo.AddMetrics(ctx, map[string]float64{"Alloc": 1, "HeapAlloc": 2}))
+
+		time.Sleep(time.Millisecond)
+		cutoff := time.Now()
+		assert.NoError(t, repo.UpdateMetrics(ctx, []string{"HeapAlloc"}, func(_ string, value float64, _ bool) (float64, error) {
+			return value + 1, nil
+		}))
+
+		updated, err := repo.UpdatedAt(ctx)
+		assert.NoError(t, err)
+		assert.True(t, updated["Alloc"].Before(cutoff))
+		assert.False(t, updated["HeapAlloc"].Before(cutoff))
+
+		assert.NoError(t, repo.ExpireMetrics(ctx, []string{"Alloc", "HeapAlloc", "Missing"}, cutoff))
+		list, _ := repo.GetList(ctx)
+		assert.Equal(t, map[string]float64{"HeapAlloc": 3}, list)
+
+		assert.NoError(t, repo.DeleteMetrics(ctx, []string{"HeapAlloc"}))
+		updated, _ = repo.UpdatedAt(ctx)
+		assert.Empty(t, updated)
+
+		updated, err = NewBoltRepo[float64](db, "histogram").UpdatedAt(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, updated)
+	})
+
+	histogram := dto.Histogram{Bounds: []float64{1}, Counts: []uint64{1, 1}, Sum: 2.5, Count: 2}
+	assert.NoError(t, NewBoltRepo[dto.Histogram](db, "histogram").AddMetric(context.Background(), "latency", histogram))
+	assert.NoError(t, NewBoltRepo[float64](db, "gauge").AddMetric(context.Background(), "Alloc", 1))
+	assert.NoError(t, db.Close())
+	assert.Error(t, db.Ping())
+
+	restored, err := clients.OpenBolt(path, "gauge", "counter", "histogram")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+
+	histograms, err := NewBoltRepo[dto.Histogram](restored, "histogram").GetList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]dto.Histogram{"latency": histogram}, histograms)
+
+	_, err = NewBoltRepo[dto.Histogram](restored, "gauge").GetList(context.Background())
+	assert.Error(t, err)
+	assert.NoError(t, restored.Close())
+
+	readOnly, err := clients.OpenBoltReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readOnly.Close()
+
+	gauges, err := NewBoltRepo[float64](readOnly, "gauge").GetList(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"Alloc": 1}, gauges)
+	assert.Error(t, NewBoltRepo[float64](readOnly, "gauge").AddMetric(context.Background(), "Alloc", 2))
+
+	missing, err := NewBoltRepo[float64](readOnly, "summary").GetList(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	_, err = NewBoltRepo[float64](readOnly, "summary").GetItem(context.Background(), "Alloc")
+	assert.ErrorIs(t, err, storages.ErrSeriesNotFound)
+}
+
+func TestGaugeRepoPostgres(t *testing.T) {
+	dsn := os.Getenv("MIGRATE_TEST_CONN_STRING")
+	if dsn == "" {
+		t.Skip("MIGRATE_TEST_CONN_STRING is not set")
+	}
+
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(context.Background())
+
+	testSeriesRepo(t, NewGaugeRepo(clients.NewPostgres(conn)))
+}
+
+func TestCounterRepoPostgres(t *testing.T) {
+	dsn := os.Getenv("MIGRATE_TEST_CONN_STRING")
+	if dsn == "" {
+		t.Skip("MIGRATE_TEST_CONN_STRING is not set")
+	}
+
+	// Every repo has its own pool, like the servers sharing the database.
+	repos := make([]*CounterRepo, 2)
+	for i := range repos {
+		db, err := clients.NewPostgresPool(context.Background(), dsn, clients.PoolConfig{MaxConns: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		repos[i] = NewCounterRepo(db)
+	}
+	defer func() { _ = repos[0].DeleteMetrics(context.Background(), []string{"repo_test_increment"}) }()
+
+	var wg sync.WaitGroup
+	for _, repo := range repos {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(repo *CounterRepo) {
+				defer wg.Done()
+				for j := 0; j < 25; j++ {
+					_, err := repo.IncrementMetrics(context.Background(), map[string]float64{"repo_test_increment": 1})
+					assert.NoError(t, err)
+				}
+			}(repo)
+		}
+	}
+	wg.Wait()
+
+	value, err := repos[1].GetItem(context.Background(), "repo_test_increment")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, value)
+}
@@ -3,13 +3,19 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
 	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/jackc/pgx/v5"
 )
 
+// CounterRepo keeps the series of a single tenant, the series of other tenants in the same table are never read or written.
 type CounterRepo struct {
-	pg *clients.Postgres
+	pg     *clients.Postgres
+	tenant string
 }
 
 func NewCounterRepo(pg *clients.Postgres) *CounterRepo {
@@ -20,44 +26,165 @@ func NewCounterRepo(pg *clients.Postgres) *CounterRepo {
 	return cr
 }
 
-func (c *CounterRepo) GetItem(ctx context.Context, name string) (int, error) {
+// WithTenant returns a repository of the series of the tenant, the repository of the default tenant is returned by NewCounterRepo.
+func (c *CounterRepo) WithTenant(tenant string) *CounterRepo {
+	return &CounterRepo{
+		pg:     c.pg,
+		tenant: tenant,
+	}
+}
+
+func (c *CounterRepo) GetItem(ctx context.Context, key string) (float64, error) {
 	var value int
-	err := c.pg.QueryRow(ctx, "SELECT value FROM counter WHERE name = $1", name).Scan(&value)
-	return value, err
+	name, labels := dto.SplitSeriesKey(key)
+	err := c.pg.QueryRow(ctx, "SELECT value FROM counter WHERE tenant = $1 AND name = $2 AND labels = $3", c.tenant, name, labels).Scan(&value)
+	return float64(value), itemError(err)
 }
 
 func (c *CounterRepo) GetList(ctx context.Context) (map[string]float64, error) {
-	rows, err := c.pg.Query(ctx, "SELECT * FROM counter")
+	rows, err := c.pg.Query(ctx, "SELECT name, labels, value FROM counter WHERE tenant = $1", c.tenant)
 
 	if err != nil {
 		return nil, err
 	}
 	items := make(map[string]float64)
 	for rows.Next() {
-		var name string
+		var name, labels string
 		var value int
-		_ = rows.Scan(&name, &value)
-		items[name] = float64(value)
+		_ = rows.Scan(&name, &labels, &value)
+		items[dto.JoinSeriesKey(name, labels)] = float64(value)
 	}
 
 	return items, nil
 }
 
-func (c *CounterRepo) AddMetric(ctx context.Context, name string, value int) error {
-	_, err := c.pg.Exec(ctx, "INSERT INTO counter (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = $2", name, value)
+func (c *CounterRepo) AddMetric(ctx context.Context, key string, value int) error {
+	name, labels := dto.SplitSeriesKey(key)
+	_, err := c.pg.Exec(ctx, "INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = $4, updated_at = now()", c.tenant, name, labels, value)
 	return err
 }
 
+// AddMetrics sets the counters with a multi-row INSERT, batches above copyThreshold are copied through a staging table.
 func (c *CounterRepo) AddMetrics(ctx context.Context, metrics map[string]float64) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	if len(metrics) > copyThreshold {
+		return c.pg.InTx(ctx, func(tx pgx.Tx) error {
+			return copyUpsertSeries(ctx, tx, "counter", c.tenant, counterRows(metrics), "value = EXCLUDED.value")
+		})
+	}
+
 	var valueStrings []string
-	var valueArgs []interface{}
-	i := 1
-	for name, value := range metrics {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d)", i, i+1))
-		valueArgs = append(valueArgs, name, int(value))
-		i += 2
-	}
-	query := fmt.Sprintf("INSERT INTO counter (name, value) VALUES %s ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value", strings.Join(valueStrings, ","))
+	valueArgs := []interface{}{c.tenant}
+	i := 2
+	for key, value := range metrics {
+		name, labels := dto.SplitSeriesKey(key)
+		valueStrings = append(valueStrings, fmt.Sprintf("($1, $%d, $%d, $%d)", i, i+1, i+2))
+		valueArgs = append(valueArgs, name, labels, int(value))
+		i += 3
+	}
+	query := fmt.Sprintf("INSERT INTO counter (tenant, name, labels, value) VALUES %s ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value, updated_at = now()", strings.Join(valueStrings, ","))
 	_, err := c.pg.Exec(ctx, query, valueArgs...)
 	return err
 }
+
+// IncrementMetrics adds the deltas to the stored counters in one transaction, batches above copyThreshold are copied through a staging table.
+// The rows are upserted in the order of their keys, so concurrent batches of several servers lock them in the same order.
+// The totals are returned by the upsert itself.
+func (c *CounterRepo) IncrementMetrics(ctx context.Context, deltas map[string]float64) (map[string]float64, error) {
+	if len(deltas) == 0 {
+		return nil, nil
+	}
+
+	var totals map[string]float64
+	if len(deltas) > copyThreshold {
+		err := c.pg.InTx(ctx, func(tx pgx.Tx) error {
+			query, err := copySeries(ctx, tx, "counter", counterRows(deltas), "value = counter.value + EXCLUDED.value")
+			if err != nil {
+				return err
+			}
+
+			totals, err = counterTotals(tx.Query(ctx, query+" RETURNING name, labels, value", c.tenant))
+			return err
+		})
+
+		return totals, err
+	}
+
+	keys := make([]string, 0, len(deltas))
+	for key := range deltas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var valueStrings []string
+	valueArgs := []interface{}{c.tenant}
+	for i, key := range keys {
+		name, labels := dto.SplitSeriesKey(key)
+		valueStrings = append(valueStrings, fmt.Sprintf("($1, $%d, $%d, $%d)", i*3+2, i*3+3, i*3+4))
+		valueArgs = append(valueArgs, name, labels, int(deltas[key]))
+	}
+	query := fmt.Sprintf("INSERT INTO counter (tenant, name, labels, value) VALUES %s ON CONFLICT (tenant, name, labels) DO UPDATE SET value = counter.value + EXCLUDED.value, updated_at = now() RETURNING name, labels, value", strings.Join(valueStrings, ","))
+
+	err := c.pg.InTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		totals, err = counterTotals(tx.Query(ctx, query, valueArgs...))
+		return err
+	})
+
+	return totals, err
+}
+
+// UpdatedAt returns the last update time of every series.
+func (c *CounterRepo) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return seriesUpdatedAt(ctx, c.pg, "counter", c.tenant)
+}
+
+// ExpireMetrics deletes the series with the keys that weren't updated since before.
+func (c *CounterRepo) ExpireMetrics(ctx context.Context, keys []string, before time.Time) error {
+	return expireSeries(ctx, c.pg, "counter", c.tenant, keys, before)
+}
+
+// DeleteMetrics deletes the series with the keys.
+func (c *CounterRepo) DeleteMetrics(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query, args := deleteSeriesQuery("counter", c.tenant, keys)
+	_, err := c.pg.Exec(ctx, query, args...)
+	return err
+}
+
+// counterRows returns the rows of name, labels and value of the counters for a COPY.
+func counterRows(metrics map[string]float64) [][]interface{} {
+	rows := make([][]interface{}, 0, len(metrics))
+	for key, value := range metrics {
+		name, labels := dto.SplitSeriesKey(key)
+		rows = append(rows, []interface{}{name, labels, int64(value)})
+	}
+
+	return rows
+}
+
+// counterTotals reads the name, labels and value of the counters returned by an upsert.
+func counterTotals(rows pgx.Rows, err error) (map[string]float64, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string]float64)
+	for rows.Next() {
+		var name, labels string
+		var value int64
+		if err := rows.Scan(&name, &labels, &value); err != nil {
+			return nil, err
+		}
+		totals[dto.JoinSeriesKey(name, labels)] = float64(value)
+	}
+
+	return totals, rows.Err()
+}
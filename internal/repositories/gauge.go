@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
 	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/jackc/pgx/v5"
 )
 
+// GaugeRepo keeps the series of a single tenant, the series of other tenants in the same table are never read or written.
 type GaugeRepo struct {
-	pg *clients.Postgres
+	pg     *clients.Postgres
+	tenant string
 }
 
 func NewGaugeRepo(pg *clients.Postgres) *GaugeRepo {
@@ -20,14 +25,23 @@ func NewGaugeRepo(pg *clients.Postgres) *GaugeRepo {
 	return cr
 }
 
-func (g *GaugeRepo) GetItem(ctx context.Context, name string) (float64, error) {
+// WithTenant returns a repository of the series of the tenant, the repository of the default tenant is returned by NewGaugeRepo.
+func (g *GaugeRepo) WithTenant(tenant string) *GaugeRepo {
+	return &GaugeRepo{
+		pg:     g.pg,
+		tenant: tenant,
+	}
+}
+
+func (g *GaugeRepo) GetItem(ctx context.Context, key string) (float64, error) {
 	var value float64
-	err := g.pg.QueryRow(ctx, "SELECT value FROM gauge WHERE name = $1", name).Scan(&value)
-	return value, err
+	name, labels := dto.SplitSeriesKey(key)
+	err := g.pg.QueryRow(ctx, "SELECT value FROM gauge WHERE tenant = $1 AND name = $2 AND labels = $3", g.tenant, name, labels).Scan(&value)
+	return value, itemError(err)
 }
 
 func (g *GaugeRepo) GetList(ctx context.Context) (map[string]float64, error) {
-	rows, err := g.pg.Query(ctx, "SELECT * FROM gauge")
+	rows, err := g.pg.Query(ctx, "SELECT name, labels, value FROM gauge WHERE tenant = $1", g.tenant)
 
 	if err != nil {
 		return nil, err
@@ -35,30 +49,75 @@ func (g *GaugeRepo) GetList(ctx context.Context) (map[string]float64, error) {
 
 	items := make(map[string]float64)
 	for rows.Next() {
-		var name string
+		var name, labels string
 		var value float64
-		_ = rows.Scan(&name, &value)
-		items[name] = value
+		_ = rows.Scan(&name, &labels, &value)
+		items[dto.JoinSeriesKey(name, labels)] = value
 	}
 
 	return items, nil
 }
 
-func (g *GaugeRepo) AddMetric(ctx context.Context, name string, value float64) error {
-	_, err := g.pg.Exec(ctx, "INSERT INTO gauge (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = $2", name, value)
+func (g *GaugeRepo) AddMetric(ctx context.Context, key string, value float64) error {
+	name, labels := dto.SplitSeriesKey(key)
+	_, err := g.pg.Exec(ctx, "INSERT INTO gauge (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = $4, updated_at = now()", g.tenant, name, labels, value)
 	return err
 }
 
+// AddMetrics upserts the series with a multi-row INSERT, batches above copyThreshold are copied through a staging table.
 func (g *GaugeRepo) AddMetrics(ctx context.Context, metrics map[string]float64) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	if len(metrics) > copyThreshold {
+		return g.pg.InTx(ctx, func(tx pgx.Tx) error {
+			return copyUpsertSeries(ctx, tx, "gauge", g.tenant, gaugeRows(metrics), "value = EXCLUDED.value")
+		})
+	}
+
 	var valueStrings []string
-	var valueArgs []interface{}
-	i := 1
-	for name, value := range metrics {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d)", i, i+1))
-		valueArgs = append(valueArgs, name, value)
-		i += 2
+	valueArgs := []interface{}{g.tenant}
+	i := 2
+	for key, value := range metrics {
+		name, labels := dto.SplitSeriesKey(key)
+		valueStrings = append(valueStrings, fmt.Sprintf("($1, $%d, $%d, $%d)", i, i+1, i+2))
+		valueArgs = append(valueArgs, name, labels, value)
+		i += 3
 	}
-	query := fmt.Sprintf("INSERT INTO gauge (name, value) VALUES %s ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value", strings.Join(valueStrings, ","))
+	query := fmt.Sprintf("INSERT INTO gauge (tenant, name, labels, value) VALUES %s ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value, updated_at = now()", strings.Join(valueStrings, ","))
 	_, err := g.pg.Exec(ctx, query, valueArgs...)
 	return err
 }
+
+// UpdatedAt returns the last update time of every series.
+func (g *GaugeRepo) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return seriesUpdatedAt(ctx, g.pg, "gauge", g.tenant)
+}
+
+// ExpireMetrics deletes the series with the keys that weren't updated since before.
+func (g *GaugeRepo) ExpireMetrics(ctx context.Context, keys []string, before time.Time) error {
+	return expireSeries(ctx, g.pg, "gauge", g.tenant, keys, before)
+}
+
+// DeleteMetrics deletes the series with the keys.
+func (g *GaugeRepo) DeleteMetrics(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query, args := deleteSeriesQuery("gauge", g.tenant, keys)
+	_, err := g.pg.Exec(ctx, query, args...)
+	return err
+}
+
+// gaugeRows returns the rows of name, labels and value of the gauges for a COPY.
+func gaugeRows(metrics map[string]float64) [][]interface{} {
+	rows := make([][]interface{}, 0, len(metrics))
+	for key, value := range metrics {
+		name, labels := dto.SplitSeriesKey(key)
+		rows = append(rows, []interface{}{name, labels, value})
+	}
+
+	return rows
+}
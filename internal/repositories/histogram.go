@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+)
+
+// HistogramRepo keeps the series of a single tenant, the series of other tenants in the same table are never read or written.
+type HistogramRepo struct {
+	pg     *clients.Postgres
+	tenant string
+}
+
+func NewHistogramRepo(pg *clients.Postgres) *HistogramRepo {
+	hr := &HistogramRepo{
+		pg: pg,
+	}
+
+	return hr
+}
+
+// WithTenant returns a repository of the series of the tenant, the repository of the default tenant is returned by NewHistogramRepo.
+func (h *HistogramRepo) WithTenant(tenant string) *HistogramRepo {
+	return &HistogramRepo{
+		pg:     h.pg,
+		tenant: tenant,
+	}
+}
+
+func (h *HistogramRepo) GetItem(ctx context.Context, key string) (dto.Histogram, error) {
+	name, labels := dto.SplitSeriesKey(key)
+	var bounds []float64
+	var counts []int64
+	var sum float64
+	var count int64
+	err := h.pg.QueryRow(ctx, "SELECT bounds, counts, sum, count FROM histogram WHERE tenant = $1 AND name = $2 AND labels = $3", h.tenant, name, labels).Scan(&bounds, &counts, &sum, &count)
+	return newHistogram(bounds, counts, sum, count), itemError(err)
+}
+
+func (h *HistogramRepo) GetList(ctx context.Context) (map[string]dto.Histogram, error) {
+	rows, err := h.pg.Query(ctx, "SELECT name, labels, bounds, counts, sum, count FROM histogram WHERE tenant = $1", h.tenant)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]dto.Histogram)
+	for rows.Next() {
+		var name, labels string
+		var bounds []float64
+		var counts []int64
+		var sum float64
+		var count int64
+		_ = rows.Scan(&name, &labels, &bounds, &counts, &sum, &count)
+		items[dto.JoinSeriesKey(name, labels)] = newHistogram(bounds, counts, sum, count)
+	}
+
+	return items, nil
+}
+
+func (h *HistogramRepo) AddMetric(ctx context.Context, key string, value dto.Histogram) error {
+	name, labels := dto.SplitSeriesKey(key)
+	_, err := h.pg.Exec(ctx, "INSERT INTO histogram (tenant, name, labels, bounds, counts, sum, count) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (tenant, name, labels) DO UPDATE SET bounds = $4, counts = $5, sum = $6, count = $7, updated_at = now()",
+		h.tenant, name, labels, value.Bounds, toInt64s(value.Counts), value.Sum, int64(value.Count))
+	return err
+}
+
+func (h *HistogramRepo) AddMetrics(ctx context.Context, metrics map[string]dto.Histogram) error {
+	var valueStrings []string
+	valueArgs := []interface{}{h.tenant}
+	i := 2
+	for key, value := range metrics {
+		name, labels := dto.SplitSeriesKey(key)
+		valueStrings = append(valueStrings, fmt.Sprintf("($1, $%d, $%d, $%d, $%d, $%d, $%d)", i, i+1, i+2, i+3, i+4, i+5))
+		valueArgs = append(valueArgs, name, labels, value.Bounds, toInt64s(value.Counts), value.Sum, int64(value.Count))
+		i += 6
+	}
+	query := fmt.Sprintf("INSERT INTO histogram (tenant, name, labels, bounds, counts, sum, count) VALUES %s ON CONFLICT (tenant, name, labels) DO UPDATE SET bounds = EXCLUDED.bounds, counts = EXCLUDED.counts, sum = EXCLUDED.sum, count = EXCLUDED.count, updated_at = now()", strings.Join(valueStrings, ","))
+	_, err := h.pg.Exec(ctx, query, valueArgs...)
+	return err
+}
+
+func newHistogram(bounds []float64, counts []int64, sum float64, count int64) dto.Histogram {
+	histogram := dto.Histogram{
+		Bounds: bounds,
+		Counts: make([]uint64, len(counts)),
+		Sum:    sum,
+		Count:  uint64(count),
+	}
+
+	for i, c := range counts {
+		histogram.Counts[i] = uint64(c)
+	}
+
+	return histogram
+}
+
+func toInt64s(values []uint64) []int64 {
+	result := make([]int64, len(values))
+	for i, v := range values {
+		result[i] = int64(v)
+	}
+
+	return result
+}
+
+// UpdatedAt returns the last update time of every series.
+func (h *HistogramRepo) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return seriesUpdatedAt(ctx, h.pg, "histogram", h.tenant)
+}
+
+// ExpireMetrics deletes the series with the keys that weren't updated since before.
+func (h *HistogramRepo) ExpireMetrics(ctx context.Context, keys []string, before time.Time) error {
+	return expireSeries(ctx, h.pg, "histogram", h.tenant, keys, before)
+}
+
+// DeleteMetrics deletes the series with the keys.
+func (h *HistogramRepo) DeleteMetrics(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query, args := deleteSeriesQuery("histogram", h.tenant, keys)
+	_, err := h.pg.Exec(ctx, query, args...)
+	return err
+}
@@ -2,10 +2,16 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"regexp"
 	"testing"
+	"time"
 
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
 	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,8 +36,8 @@ func TestCounterRepo_Test(t *testing.T) {
 		{
 			name: "GetItem",
 			expect: func(mock pgxmock.PgxPoolIface) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT value FROM counter WHERE name = $1")).
-					WithArgs("test").
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT value FROM counter WHERE tenant = $1 AND name = $2 AND labels = $3")).
+					WithArgs("", "test", "").
 					WillReturnRows(pgxmock.NewRows([]string{"value"}).AddRow(100))
 			},
 			check: func(mockDB *clients.Postgres) {
@@ -40,14 +46,30 @@ func TestCounterRepo_Test(t *testing.T) {
 				}
 				actual, err := cr.GetItem(context.Background(), "test")
 				assert.NoError(t, err, "GetItem", err)
-				assert.Equal(t, 100, actual)
+				assert.Equal(t, float64(100), actual)
+			},
+		},
+		{
+			name: "GetItem not found",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT value FROM counter WHERE tenant = $1 AND name = $2 AND labels = $3")).
+					WithArgs("", "missing", "").
+					WillReturnError(pgx.ErrNoRows)
+			},
+			check: func(mockDB *clients.Postgres) {
+				cr := &CounterRepo{
+					pg: mockDB,
+				}
+				_, err := cr.GetItem(context.Background(), "missing")
+				assert.ErrorIs(t, err, storages.ErrSeriesNotFound)
 			},
 		},
 		{
 			name: "GetList",
 			expect: func(mock pgxmock.PgxPoolIface) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM counter")).
-					WillReturnRows(pgxmock.NewRows([]string{"name", "value"}).AddRow("test", 100))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+					WithArgs("").
+					WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", 100))
 			},
 			check: func(mockDB *clients.Postgres) {
 				cr := &CounterRepo{
@@ -70,8 +92,8 @@ func TestCounterRepo_Test(t *testing.T) {
 		{
 			name: "AddMetric",
 			expect: func(mock pgxmock.PgxPoolIface) {
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = $2")).
-					WithArgs("test", 100).
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = $4, updated_at = now()")).
+					WithArgs("", "test", "", 100).
 					WillReturnResult(pgxmock.NewResult("INSERT", 1))
 			},
 			check: func(mockDB *clients.Postgres) {
@@ -86,8 +108,8 @@ func TestCounterRepo_Test(t *testing.T) {
 			name: "AddMetrics",
 			expect: func(mock pgxmock.PgxPoolIface) {
 				var valueArgs []interface{}
-				valueArgs = append(valueArgs, "test", 500)
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value")).
+				valueArgs = append(valueArgs, "", "test", "", 500)
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value, updated_at = now()")).
 					WithArgs(valueArgs...).
 					WillReturnResult(pgxmock.NewResult("INSERT", 1))
 			},
@@ -99,6 +121,32 @@ func TestCounterRepo_Test(t *testing.T) {
 				assert.NoError(t, err, "AddMetrics", err)
 			},
 		},
+		{
+			name: "IncrementMetrics",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4),($1, $5, $6, $7) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = counter.value + EXCLUDED.value, updated_at = now() RETURNING name, labels, value")).
+					WithArgs("", "PollCount", "", 5, "PollCount", `host="web1"`, 2).
+					WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("PollCount", "", int64(12)).AddRow("PollCount", `host="web1"`, int64(2)))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO counter")).
+					WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			check: func(mockDB *clients.Postgres) {
+				cr := &CounterRepo{
+					pg: mockDB,
+				}
+				totals, err := cr.IncrementMetrics(context.Background(), map[string]float64{`PollCount{host="web1"}`: 2, "PollCount": 5})
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]float64{"PollCount": 12, `PollCount{host="web1"}`: 2}, totals)
+				_, err = cr.IncrementMetrics(context.Background(), nil)
+				assert.NoError(t, err)
+				_, err = cr.IncrementMetrics(context.Background(), map[string]float64{"PollCount": 1})
+				assert.Error(t, err)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -136,8 +184,8 @@ func TestGaugeRepo_Test(t *testing.T) {
 		{
 			name: "GetItem",
 			expect: func(mock pgxmock.PgxPoolIface) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT value FROM gauge WHERE name = $1")).
-					WithArgs("test").
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT value FROM gauge WHERE tenant = $1 AND name = $2 AND labels = $3")).
+					WithArgs("", "test", "").
 					WillReturnRows(pgxmock.NewRows([]string{"value"}).AddRow(float64(100)))
 			},
 			check: func(mockDB *clients.Postgres) {
@@ -152,8 +200,9 @@ func TestGaugeRepo_Test(t *testing.T) {
 		{
 			name: "GetList",
 			expect: func(mock pgxmock.PgxPoolIface) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM gauge")).
-					WillReturnRows(pgxmock.NewRows([]string{"name", "value"}).AddRow("test", float64(100)))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).
+					WithArgs("").
+					WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", "", float64(100)))
 			},
 			check: func(mockDB *clients.Postgres) {
 				cr := &GaugeRepo{
@@ -176,8 +225,8 @@ func TestGaugeRepo_Test(t *testing.T) {
 		{
 			name: "AddMetric",
 			expect: func(mock pgxmock.PgxPoolIface) {
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = $2")).
-					WithArgs("test", float64(100)).
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = $4, updated_at = now()")).
+					WithArgs("", "test", "", float64(100)).
 					WillReturnResult(pgxmock.NewResult("INSERT", 1))
 			},
 			check: func(mockDB *clients.Postgres) {
@@ -188,12 +237,34 @@ func TestGaugeRepo_Test(t *testing.T) {
 				assert.NoError(t, err, "AddMetric", err)
 			},
 		},
+		{
+			name: "AddMetricLabels",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = $4, updated_at = now()")).
+					WithArgs("", "test", `host="web1"`, float64(100)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).
+					WithArgs("").
+					WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("test", `host="web1"`, float64(100)))
+			},
+			check: func(mockDB *clients.Postgres) {
+				cr := &GaugeRepo{
+					pg: mockDB,
+				}
+				err := cr.AddMetric(context.Background(), `test{host="web1"}`, float64(100))
+				assert.NoError(t, err, "AddMetric", err)
+
+				actual, err := cr.GetList(context.Background())
+				assert.NoError(t, err, "GetList", err)
+				assert.Equal(t, map[string]float64{`test{host="web1"}`: 100}, actual)
+			},
+		},
 		{
 			name: "AddMetrics",
 			expect: func(mock pgxmock.PgxPoolIface) {
 				var valueArgs []interface{}
-				valueArgs = append(valueArgs, "test", 500.50)
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (name, value) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value")).
+				valueArgs = append(valueArgs, "", "test", "", 500.50)
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO gauge (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value, updated_at = now()")).
 					WithArgs(valueArgs...).
 					WillReturnResult(pgxmock.NewResult("INSERT", 1))
 			},
@@ -221,3 +292,374 @@ func TestGaugeRepo_Test(t *testing.T) {
 		})
 	}
 }
+
+func TestHistogramRepo_Test(t *testing.T) {
+	histogram := dto.Histogram{Bounds: []float64{1, 5}, Counts: []uint64{1, 0, 2}, Sum: 12.5, Count: 3}
+
+	testCases := []struct {
+		name   string
+		expect func(mock pgxmock.PgxPoolIface)
+		check  func(mockDB *clients.Postgres)
+	}{
+		{
+			name: "GetItem",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT bounds, counts, sum, count FROM histogram WHERE tenant = $1 AND name = $2 AND labels = $3")).
+					WithArgs("", "test", "").
+					WillReturnRows(pgxmock.NewRows([]string{"bounds", "counts", "sum", "count"}).AddRow([]float64{1, 5}, []int64{1, 0, 2}, 12.5, int64(3)))
+			},
+			check: func(mockDB *clients.Postgres) {
+				hr := NewHistogramRepo(mockDB)
+				actual, err := hr.GetItem(context.Background(), "test")
+				assert.NoError(t, err, "GetItem", err)
+				assert.Equal(t, histogram, actual)
+			},
+		},
+		{
+			name: "GetList",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, bounds, counts, sum, count FROM histogram")).
+					WithArgs("").
+					WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "bounds", "counts", "sum", "count"}).AddRow("test", "", []float64{1, 5}, []int64{1, 0, 2}, 12.5, int64(3)))
+			},
+			check: func(mockDB *clients.Postgres) {
+				hr := NewHistogramRepo(mockDB)
+				actual, err := hr.GetList(context.Background())
+				assert.NoError(t, err, "GetList", err)
+				assert.Equal(t, map[string]dto.Histogram{"test": histogram}, actual)
+			},
+		},
+		{
+			name: "AddMetric",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO histogram (tenant, name, labels, bounds, counts, sum, count) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (tenant, name, labels) DO UPDATE SET bounds = $4, counts = $5, sum = $6, count = $7, updated_at = now()")).
+					WithArgs("", "test", "", []float64{1, 5}, []int64{1, 0, 2}, 12.5, int64(3)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			},
+			check: func(mockDB *clients.Postgres) {
+				hr := NewHistogramRepo(mockDB)
+				err := hr.AddMetric(context.Background(), "test", histogram)
+				assert.NoError(t, err, "AddMetric", err)
+			},
+		},
+		{
+			name: "AddMetrics",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO histogram (tenant, name, labels, bounds, counts, sum, count) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (tenant, name, labels) DO UPDATE SET bounds = EXCLUDED.bounds, counts = EXCLUDED.counts, sum = EXCLUDED.sum, count = EXCLUDED.count, updated_at = now()")).
+					WithArgs("", "test", "", []float64{1, 5}, []int64{1, 0, 2}, 12.5, int64(3)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			},
+			check: func(mockDB *clients.Postgres) {
+				hr := NewHistogramRepo(mockDB)
+				err := hr.AddMetrics(context.Background(), map[string]dto.Histogram{"test": histogram})
+				assert.NoError(t, err, "AddMetrics", err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mock.Close()
+
+			testCase.expect(mock)
+			mockDB := clients.NewPostgres(mock)
+			testCase.check(mockDB)
+		})
+	}
+}
+
+func TestSummaryRepo_Test(t *testing.T) {
+	summary := dto.NewSummary(dto.DefaultRelativeAccuracy)
+	summary.Observe(2)
+	sketch, _ := json.Marshal(summary)
+
+	testCases := []struct {
+		name   string
+		expect func(mock pgxmock.PgxPoolIface)
+		check  func(mockDB *clients.Postgres)
+	}{
+		{
+			name: "GetItem",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT sketch FROM summary WHERE tenant = $1 AND name = $2 AND labels = $3")).
+					WithArgs("", "test", "").
+					WillReturnRows(pgxmock.NewRows([]string{"sketch"}).AddRow(sketch))
+			},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSummaryRepo(mockDB)
+				actual, err := sr.GetItem(context.Background(), "test")
+				assert.NoError(t, err, "GetItem", err)
+				assert.Equal(t, summary, actual)
+			},
+		},
+		{
+			name: "GetList",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, sketch FROM summary")).
+					WithArgs("").
+					WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "sketch"}).AddRow("test", "", sketch))
+			},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSummaryRepo(mockDB)
+				actual, err := sr.GetList(context.Background())
+				assert.NoError(t, err, "GetList", err)
+				assert.Equal(t, map[string]dto.Summary{"test": summary}, actual)
+			},
+		},
+		{
+			name: "GetListBrokenSketch",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, sketch FROM summary")).
+					WithArgs("").
+					WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "sketch"}).AddRow("test", "", []byte("{")))
+			},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSummaryRepo(mockDB)
+				_, err := sr.GetList(context.Background())
+				assert.Error(t, err, "GetList")
+			},
+		},
+		{
+			name: "AddMetric",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO summary (tenant, name, labels, sketch) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET sketch = $4, updated_at = now()")).
+					WithArgs("", "test", "", sketch).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSummaryRepo(mockDB)
+				err := sr.AddMetric(context.Background(), "test", summary)
+				assert.NoError(t, err, "AddMetric", err)
+			},
+		},
+		{
+			name: "AddMetrics",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO summary (tenant, name, labels, sketch) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET sketch = EXCLUDED.sketch, updated_at = now()")).
+					WithArgs("", "test", "", sketch).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSummaryRepo(mockDB)
+				err := sr.AddMetrics(context.Background(), map[string]dto.Summary{"test": summary})
+				assert.NoError(t, err, "AddMetrics", err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mock.Close()
+
+			testCase.expect(mock)
+			mockDB := clients.NewPostgres(mock)
+			testCase.check(mockDB)
+		})
+	}
+}
+
+func TestSampleRepo_Test(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name   string
+		expect func(mock pgxmock.PgxPoolIface)
+		check  func(mockDB *clients.Postgres)
+	}{
+		{
+			name: "Record",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO samples (tenant, type, name, labels, ts, value) VALUES ($1, $2, $3, $4, $5, $6)")).
+					WithArgs("", "gauge", "Alloc", `host="web1"`, at, 1.5).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSampleRepo(mockDB)
+				err := sr.Record(context.Background(), "gauge", map[string]float64{`Alloc{host="web1"}`: 1.5}, at)
+				assert.NoError(t, err, "Record", err)
+			},
+		},
+		{
+			name:   "RecordEmpty",
+			expect: func(mock pgxmock.PgxPoolIface) {},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSampleRepo(mockDB)
+				err := sr.Record(context.Background(), "gauge", nil, at)
+				assert.NoError(t, err, "Record", err)
+			},
+		},
+		{
+			name: "Range",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT ts, value FROM samples WHERE tenant = $1 AND type = $2 AND name = $3 AND labels = $4 AND ts BETWEEN $5 AND $6 ORDER BY ts")).
+					WithArgs("", "gauge", "Alloc", "", at, at.Add(time.Minute)).
+					WillReturnRows(pgxmock.NewRows([]string{"ts", "value"}).AddRow(at, 1.5).AddRow(at.Add(time.Second), 2.5))
+			},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSampleRepo(mockDB)
+				actual, err := sr.Range(context.Background(), "gauge", "Alloc", at, at.Add(time.Minute))
+				assert.NoError(t, err, "Range", err)
+				assert.Equal(t, []dto.Sample{{Time: at, Value: 1.5}, {Time: at.Add(time.Second), Value: 2.5}}, actual)
+			},
+		},
+		{
+			name: "Prune",
+			expect: func(mock pgxmock.PgxPoolIface) {
+				mock.ExpectExec(regexp.QuoteMeta("DELETE FROM samples WHERE ctid IN (SELECT ctid FROM (SELECT ctid, row_number() OVER (PARTITION BY type, name, labels ORDER BY ts DESC) AS n FROM samples WHERE tenant = $1) ranked WHERE n > $2)")).
+					WithArgs("team-a", 100).
+					WillReturnResult(pgxmock.NewResult("DELETE", 3))
+			},
+			check: func(mockDB *clients.Postgres) {
+				sr := NewSampleRepo(mockDB).WithTenant("team-a")
+				err := sr.Prune(context.Background(), 100)
+				assert.NoError(t, err, "Prune", err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mock.Close()
+
+			testCase.expect(mock)
+			mockDB := clients.NewPostgres(mock)
+			testCase.check(mockDB)
+		})
+	}
+}
+
+func TestDeleteMetrics(t *testing.T) {
+	testCases := []struct {
+		table  string
+		delete func(pg *clients.Postgres, keys []string) error
+	}{
+		{table: "gauge", delete: func(pg *clients.Postgres, keys []string) error {
+			return NewGaugeRepo(pg).DeleteMetrics(context.Background(), keys)
+		}},
+		{table: "counter", delete: func(pg *clients.Postgres, keys []string) error {
+			return NewCounterRepo(pg).DeleteMetrics(context.Background(), keys)
+		}},
+		{table: "histogram", delete: func(pg *clients.Postgres, keys []string) error {
+			return NewHistogramRepo(pg).DeleteMetrics(context.Background(), keys)
+		}},
+		{table: "summary", delete: func(pg *clients.Postgres, keys []string) error {
+			return NewSummaryRepo(pg).DeleteMetrics(context.Background(), keys)
+		}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.table, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mock.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta("DELETE FROM "+testCase.table+" WHERE tenant = $1 AND (name, labels) IN (($2, $3),($4, $5))")).
+				WithArgs("", "test", "", "test", `host="web1"`).
+				WillReturnResult(pgxmock.NewResult("DELETE", 2))
+
+			mockDB := clients.NewPostgres(mock)
+			assert.NoError(t, testCase.delete(mockDB, []string{"test", `test{host="web1"}`}))
+			assert.NoError(t, testCase.delete(mockDB, nil))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExpireMetrics(t *testing.T) {
+	testCases := []struct {
+		table string
+		repo  func(pg *clients.Postgres) storages.Expirer
+	}{
+		{table: "gauge", repo: func(pg *clients.Postgres) storages.Expirer { return NewGaugeRepo(pg) }},
+		{table: "counter", repo: func(pg *clients.Postgres) storages.Expirer { return NewCounterRepo(pg) }},
+		{table: "histogram", repo: func(pg *clients.Postgres) storages.Expirer { return NewHistogramRepo(pg) }},
+		{table: "summary", repo: func(pg *clients.Postgres) storages.Expirer { return NewSummaryRepo(pg) }},
+	}
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, testCase := range testCases {
+		t.Run(testCase.table, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mock.Close()
+
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, updated_at FROM " + testCase.table)).
+				WithArgs("").
+				WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "updated_at"}).AddRow("test", "", at).AddRow("test", `host="web1"`, at))
+			mock.ExpectExec(regexp.QuoteMeta("DELETE FROM "+testCase.table+" WHERE tenant = $1 AND (name, labels) IN (($2, $3),($4, $5)) AND updated_at < $6")).
+				WithArgs("", "test", "", "test", `host="web1"`, at).
+				WillReturnResult(pgxmock.NewResult("DELETE", 2))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, updated_at FROM " + testCase.table)).
+				WithArgs("").
+				WillReturnError(errors.New("query failed"))
+
+			repo := testCase.repo(clients.NewPostgres(mock))
+			updated, err := repo.UpdatedAt(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]time.Time{"test": at, `test{host="web1"}`: at}, updated)
+
+			assert.NoError(t, repo.ExpireMetrics(context.Background(), []string{"test", `test{host="web1"}`}, at))
+			assert.NoError(t, repo.ExpireMetrics(context.Background(), nil, at))
+
+			_, err = repo.UpdatedAt(context.Background())
+			assert.Error(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWithTenant(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge WHERE tenant = $1")).
+		WithArgs("team-a").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("Alloc", "", 1.5))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value, updated_at = now()")).
+		WithArgs("team-a", "PollCount", "", 5).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, bounds, counts, sum, count FROM histogram WHERE tenant = $1")).
+		WithArgs("team-a").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "bounds", "counts", "sum", "count"}))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM summary WHERE tenant = $1 AND (name, labels) IN (($2, $3))")).
+		WithArgs("team-a", "latency", "").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT ts, value FROM samples WHERE tenant = $1 AND type = $2 AND name = $3 AND labels = $4 AND ts BETWEEN $5 AND $6 ORDER BY ts")).
+		WithArgs("team-a", "gauge", "Alloc", "", at, at).
+		WillReturnRows(pgxmock.NewRows([]string{"ts", "value"}))
+
+	pg := clients.NewPostgres(mock)
+	ctx := context.Background()
+
+	gauges, err := NewGaugeRepo(pg).WithTenant("team-a").GetList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"Alloc": 1.5}, gauges)
+	assert.NoError(t, NewCounterRepo(pg).WithTenant("team-a").AddMetrics(ctx, map[string]float64{"PollCount": 5}))
+	histograms, err := NewHistogramRepo(pg).WithTenant("team-a").GetList(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, histograms)
+	assert.NoError(t, NewSummaryRepo(pg).WithTenant("team-a").DeleteMetrics(ctx, []string{"latency"}))
+	_, err = NewSampleRepo(pg).WithTenant("team-a").Range(ctx, "gauge", "Alloc", at, at)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
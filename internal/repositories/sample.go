@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+)
+
+// SampleRepo keeps the samples of a single tenant, the samples of other tenants in the same table are never read or written.
+type SampleRepo struct {
+	pg     *clients.Postgres
+	tenant string
+}
+
+func NewSampleRepo(pg *clients.Postgres) *SampleRepo {
+	sr := &SampleRepo{
+		pg: pg,
+	}
+
+	return sr
+}
+
+// WithTenant returns a repository of the samples of the tenant, the repository of the default tenant is returned by NewSampleRepo.
+func (s *SampleRepo) WithTenant(tenant string) *SampleRepo {
+	return &SampleRepo{
+		pg:     s.pg,
+		tenant: tenant,
+	}
+}
+
+// Record writes a sample of every series taken at the same time.
+func (s *SampleRepo) Record(ctx context.Context, metricType string, samples map[string]float64, at time.Time) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var valueStrings []string
+	valueArgs := []interface{}{s.tenant}
+	i := 2
+	for key, value := range samples {
+		name, labels := dto.SplitSeriesKey(key)
+		valueStrings = append(valueStrings, fmt.Sprintf("($1, $%d, $%d, $%d, $%d, $%d)", i, i+1, i+2, i+3, i+4))
+		valueArgs = append(valueArgs, metricType, name, labels, at, value)
+		i += 5
+	}
+	query := fmt.Sprintf("INSERT INTO samples (tenant, type, name, labels, ts, value) VALUES %s", strings.Join(valueStrings, ","))
+	_, err := s.pg.Exec(ctx, query, valueArgs...)
+	return err
+}
+
+// Range returns the samples of the series within [from, to] ordered by time.
+func (s *SampleRepo) Range(ctx context.Context, metricType string, key string, from time.Time, to time.Time) ([]dto.Sample, error) {
+	name, labels := dto.SplitSeriesKey(key)
+	rows, err := s.pg.Query(ctx, "SELECT ts, value FROM samples WHERE tenant = $1 AND type = $2 AND name = $3 AND labels = $4 AND ts BETWEEN $5 AND $6 ORDER BY ts", s.tenant, metricType, name, labels, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []dto.Sample
+	for rows.Next() {
+		var sample dto.Sample
+		_ = rows.Scan(&sample.Time, &sample.Value)
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+// Prune deletes all but the newest size samples of every series of the tenant, the same cap MemHistory keeps.
+func (s *SampleRepo) Prune(ctx context.Context, size int) error {
+	_, err := s.pg.Exec(ctx, "DELETE FROM samples WHERE ctid IN (SELECT ctid FROM (SELECT ctid, row_number() OVER (PARTITION BY type, name, labels ORDER BY ts DESC) AS n FROM samples WHERE tenant = $1) ranked WHERE n > $2)", s.tenant, size)
+	return err
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/jackc/pgx/v5"
+)
+
+// copyThreshold is the number of series above which a batch is copied into a staging table instead of a multi-row INSERT.
+// A multi-row INSERT of three columns can't hold more than 21845 series because of the limit of 65535 parameters.
+var copyThreshold = 1000
+
+// seriesColumns are the columns of a staging table of series that hold a single value, the tenant is set when the rows are merged.
+var seriesColumns = []string{"name", "labels", "value"}
+
+// deleteSeriesQuery builds a DELETE of the series of the tenant with the keys from a table keyed by tenant, name and labels.
+func deleteSeriesQuery(table string, tenant string, keys []string) (string, []interface{}) {
+	var valueStrings []string
+	valueArgs := []interface{}{tenant}
+	i := 2
+	for _, key := range keys {
+		name, labels := dto.SplitSeriesKey(key)
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d)", i, i+1))
+		valueArgs = append(valueArgs, name, labels)
+		i += 2
+	}
+
+	return fmt.Sprintf("DELETE FROM %s WHERE tenant = $1 AND (name, labels) IN (%s)", table, strings.Join(valueStrings, ",")), valueArgs
+}
+
+// seriesUpdatedAt returns the last update time of every series of the tenant from a table keyed by tenant, name and labels.
+func seriesUpdatedAt(ctx context.Context, pg *clients.Postgres, table string, tenant string) (map[string]time.Time, error) {
+	rows, err := pg.Query(ctx, fmt.Sprintf("SELECT name, labels, updated_at FROM %s WHERE tenant = $1", table), tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	updated := make(map[string]time.Time)
+	for rows.Next() {
+		var name, labels string
+		var at time.Time
+		if err := rows.Scan(&name, &labels, &at); err != nil {
+			return nil, err
+		}
+		updated[dto.JoinSeriesKey(name, labels)] = at
+	}
+
+	return updated, rows.Err()
+}
+
+// expireSeries deletes the series of the tenant with the keys that weren't updated since before from a table keyed by tenant, name and labels.
+// The update time is checked by the DELETE itself, so a series updated in the meantime is kept.
+func expireSeries(ctx context.Context, pg *clients.Postgres, table string, tenant string, keys []string, before time.Time) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query, args := deleteSeriesQuery(table, tenant, keys)
+	args = append(args, before)
+	_, err := pg.Exec(ctx, fmt.Sprintf("%s AND updated_at < $%d", query, len(args)), args...)
+	return err
+}
+
+// copyUpsertSeries copies the rows of name, labels and value into a staging table dropped on commit and merges them into the series of the tenant.
+// The set clause updates the series that already exist, the rows are merged in the order of their keys.
+func copyUpsertSeries(ctx context.Context, tx pgx.Tx, table string, tenant string, rows [][]interface{}, set string) error {
+	query, err := copySeries(ctx, tx, table, rows, set)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, tenant)
+	return err
+}
+
+// copySeries copies the rows of name, labels and value into a staging table dropped on commit
+// and returns the query that merges them into the series of the tenant given as $1 with the set clause.
+func copySeries(ctx context.Context, tx pgx.Tx, table string, rows [][]interface{}, set string) (string, error) {
+	staging := table + "_staging"
+	if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMPORARY TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", staging, table)); err != nil {
+		return "", err
+	}
+
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{staging}, seriesColumns, pgx.CopyFromRows(rows)); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("INSERT INTO %[1]s (tenant, name, labels, value) SELECT $1, name, labels, value FROM %[2]s ORDER BY name, labels ON CONFLICT (tenant, name, labels) DO UPDATE SET %[3]s, updated_at = now()", table, staging, set), nil
+}
+
+// itemError reports the missing row of a GetItem as storages.ErrSeriesNotFound.
+func itemError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storages.ErrSeriesNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyUpsertSeries(t *testing.T) {
+	copyThreshold = 1
+	defer func() { copyThreshold = 1000 }()
+
+	testCases := []struct {
+		name      string
+		table     string
+		tenant    string
+		set       string
+		returning bool
+		write     func(pg *clients.Postgres) error
+	}{
+		{
+			name:  "gauge",
+			table: "gauge",
+			set:   "value = EXCLUDED.value",
+			write: func(pg *clients.Postgres) error {
+				return NewGaugeRepo(pg).AddMetrics(context.Background(), map[string]float64{"Alloc": 1.5, `Alloc{host="web1"}`: 2})
+			},
+		},
+		{
+			name:  "counter",
+			table: "counter",
+			set:   "value = EXCLUDED.value",
+			write: func(pg *clients.Postgres) error {
+				return NewCounterRepo(pg).AddMetrics(context.Background(), map[string]float64{"PollCount": 1, `PollCount{host="web1"}`: 2})
+			},
+		},
+		{
+			name:      "counter increment",
+			table:     "counter",
+			tenant:    "team-a",
+			set:       "value = counter.value + EXCLUDED.value",
+			returning: true,
+			write: func(pg *clients.Postgres) error {
+				_, err := NewCounterRepo(pg).WithTenant("team-a").IncrementMetrics(context.Background(), map[string]float64{"PollCount": 1, `PollCount{host="web1"}`: 2})
+				return err
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mock.Close()
+
+			staging := testCase.table + "_staging"
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("CREATE TEMPORARY TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", staging, testCase.table))).
+				WillReturnResult(pgxmock.NewResult("CREATE", 0))
+			mock.ExpectCopyFrom(pgx.Identifier{staging}, []string{"name", "labels", "value"}).
+				WillReturnResult(2)
+			merge := regexp.QuoteMeta(fmt.Sprintf("INSERT INTO %s (tenant, name, labels, value) SELECT $1, name, labels, value FROM %s ORDER BY name, labels ON CONFLICT (tenant, name, labels) DO UPDATE SET %s, updated_at = now()", testCase.table, staging, testCase.set))
+			if testCase.returning {
+				mock.ExpectQuery(merge + regexp.QuoteMeta(" RETURNING name, labels, value")).
+					WithArgs(testCase.tenant).
+					WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("PollCount", "", int64(1)).AddRow("PollCount", `host="web1"`, int64(2)))
+			} else {
+				mock.ExpectExec(merge).
+					WithArgs(testCase.tenant).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+			}
+			mock.ExpectCommit()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("CREATE TEMPORARY TABLE")).
+				WillReturnResult(pgxmock.NewResult("CREATE", 0))
+			mock.ExpectCopyFrom(pgx.Identifier{staging}, []string{"name", "labels", "value"}).
+				WillReturnError(fmt.Errorf("copy failed"))
+			mock.ExpectRollback()
+
+			pg := clients.NewPostgres(mock)
+			assert.NoError(t, testCase.write(pg))
+			assert.Error(t, testCase.write(pg))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// benchmarkSeries returns the batch of gauges sent by an agent with the number of series.
+func benchmarkSeries(size int) map[string]float64 {
+	metrics := make(map[string]float64, size)
+	for i := 0; i < size; i++ {
+		metrics[fmt.Sprintf(`bench_series{host="web%d"}`, i)] = float64(i)
+	}
+
+	return metrics
+}
+
+// BenchmarkGaugeAddMetrics compares the multi-row INSERT with the COPY through a staging table on batches of growing size.
+// The INSERT can't write more than 21845 series at once.
+func BenchmarkGaugeAddMetrics(b *testing.B) {
+	dsn := os.Getenv("MIGRATE_TEST_CONN_STRING")
+	if dsn == "" {
+		b.Skip("MIGRATE_TEST_CONN_STRING is not set")
+	}
+
+	db, err := clients.NewPostgresPool(context.Background(), dsn, clients.PoolConfig{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewGaugeRepo(db)
+	defer func() { copyThreshold = 1000 }()
+
+	for _, size := range []int{100, 1000, 10000, 50000} {
+		metrics := benchmarkSeries(size)
+		keys := make([]string, 0, size)
+		for key := range metrics {
+			keys = append(keys, key)
+		}
+
+		for _, path := range []struct {
+			name      string
+			threshold int
+		}{{"insert", size}, {"copy", 0}} {
+			if path.name == "insert" && size*len(seriesColumns) > 65535 {
+				continue
+			}
+
+			b.Run(fmt.Sprintf("%s/%d", path.name, size), func(b *testing.B) {
+				copyThreshold = path.threshold
+				for i := 0; i < b.N; i++ {
+					if err := repo.AddMetrics(context.Background(), metrics); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+
+		_ = repo.DeleteMetrics(context.Background(), keys)
+	}
+}
+
+// BenchmarkCounterIncrementMetrics compares the multi-row INSERT with the COPY through a staging table for counter deltas.
+func BenchmarkCounterIncrementMetrics(b *testing.B) {
+	dsn := os.Getenv("MIGRATE_TEST_CONN_STRING")
+	if dsn == "" {
+		b.Skip("MIGRATE_TEST_CONN_STRING is not set")
+	}
+
+	db, err := clients.NewPostgresPool(context.Background(), dsn, clients.PoolConfig{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewCounterRepo(db)
+	defer func() { copyThreshold = 1000 }()
+
+	for _, size := range []int{100, 1000, 10000} {
+		metrics := benchmarkSeries(size)
+		keys := make([]string, 0, size)
+		for key := range metrics {
+			keys = append(keys, key)
+		}
+
+		for _, path := range []struct {
+			name      string
+			threshold int
+		}{{"insert", size}, {"copy", 0}} {
+			b.Run(fmt.Sprintf("%s/%d", path.name, size), func(b *testing.B) {
+				copyThreshold = path.threshold
+				for i := 0; i < b.N; i++ {
+					if _, err := repo.IncrementMetrics(context.Background(), metrics); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+
+		_ = repo.DeleteMetrics(context.Background(), keys)
+	}
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+)
+
+// SummaryRepo keeps the series of a single tenant, the series of other tenants in the same table are never read or written.
+type SummaryRepo struct {
+	pg     *clients.Postgres
+	tenant string
+}
+
+func NewSummaryRepo(pg *clients.Postgres) *SummaryRepo {
+	sr := &SummaryRepo{
+		pg: pg,
+	}
+
+	return sr
+}
+
+// WithTenant returns a repository of the series of the tenant, the repository of the default tenant is returned by NewSummaryRepo.
+func (s *SummaryRepo) WithTenant(tenant string) *SummaryRepo {
+	return &SummaryRepo{
+		pg:     s.pg,
+		tenant: tenant,
+	}
+}
+
+func (s *SummaryRepo) GetItem(ctx context.Context, key string) (dto.Summary, error) {
+	var summary dto.Summary
+	var sketch []byte
+	name, labels := dto.SplitSeriesKey(key)
+	if err := s.pg.QueryRow(ctx, "SELECT sketch FROM summary WHERE tenant = $1 AND name = $2 AND labels = $3", s.tenant, name, labels).Scan(&sketch); err != nil {
+		return summary, itemError(err)
+	}
+
+	err := json.Unmarshal(sketch, &summary)
+	return summary, err
+}
+
+func (s *SummaryRepo) GetList(ctx context.Context) (map[string]dto.Summary, error) {
+	rows, err := s.pg.Query(ctx, "SELECT name, labels, sketch FROM summary WHERE tenant = $1", s.tenant)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]dto.Summary)
+	for rows.Next() {
+		var name, labels string
+		var sketch []byte
+		_ = rows.Scan(&name, &labels, &sketch)
+
+		key := dto.JoinSeriesKey(name, labels)
+		var summary dto.Summary
+		if err := json.Unmarshal(sketch, &summary); err != nil {
+			return nil, fmt.Errorf("summary %s: %w", key, err)
+		}
+		items[key] = summary
+	}
+
+	return items, nil
+}
+
+func (s *SummaryRepo) AddMetric(ctx context.Context, key string, value dto.Summary) error {
+	sketch, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	name, labels := dto.SplitSeriesKey(key)
+	_, err = s.pg.Exec(ctx, "INSERT INTO summary (tenant, name, labels, sketch) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET sketch = $4, updated_at = now()", s.tenant, name, labels, sketch)
+	return err
+}
+
+func (s *SummaryRepo) AddMetrics(ctx context.Context, metrics map[string]dto.Summary) error {
+	var valueStrings []string
+	valueArgs := []interface{}{s.tenant}
+	i := 2
+	for key, value := range metrics {
+		sketch, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		name, labels := dto.SplitSeriesKey(key)
+		valueStrings = append(valueStrings, fmt.Sprintf("($1, $%d, $%d, $%d)", i, i+1, i+2))
+		valueArgs = append(valueArgs, name, labels, sketch)
+		i += 3
+	}
+	query := fmt.Sprintf("INSERT INTO summary (tenant, name, labels, sketch) VALUES %s ON CONFLICT (tenant, name, labels) DO UPDATE SET sketch = EXCLUDED.sketch, updated_at = now()", strings.Join(valueStrings, ","))
+	_, err := s.pg.Exec(ctx, query, valueArgs...)
+	return err
+}
+
+// UpdatedAt returns the last update time of every series.
+func (s *SummaryRepo) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return seriesUpdatedAt(ctx, s.pg, "summary", s.tenant)
+}
+
+// ExpireMetrics deletes the series with the keys that weren't updated since before.
+func (s *SummaryRepo) ExpireMetrics(ctx context.Context, keys []string, before time.Time) error {
+	return expireSeries(ctx, s.pg, "summary", s.tenant, keys, before)
+}
+
+// DeleteMetrics deletes the series with the keys.
+func (s *SummaryRepo) DeleteMetrics(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query, args := deleteSeriesQuery("summary", s.tenant, keys)
+	_, err := s.pg.Exec(ctx, query, args...)
+	return err
+}
@@ -2,7 +2,9 @@ package agent
 
 import (
 	"net/http"
+	"time"
 
+	pb "github.com/AnatolySnegovskiy/metric/internal/proto"
 	"github.com/AnatolySnegovskiy/metric/internal/services/interfase"
 )
 
@@ -17,8 +19,16 @@ type Agent struct {
 	reportInterval int
 	sendAddr       string
 	maxRetries     int
+	rateLimit      int
 	shaKey         string
 	cryptoKey      string
+	grpcClient     pb.MetricsClient
+	labels         map[string]string
+
+	// retryBackoff, retryMaxBackoff and retryJitter are the backoff policy of the retried reports.
+	retryBackoff    time.Duration
+	retryMaxBackoff time.Duration
+	retryJitter     float64
 }
 
 type Options struct {
@@ -27,20 +37,40 @@ type Options struct {
 	PollInterval   int
 	ReportInterval int
 	SendAddr       string
-	MaxRetries     int
-	ShaKey         string
-	CryptoKey      string
+	// MaxRetries is the number of times a report that failed with a network error or a 5xx status is sent again before it is dropped.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry of a report, it doubles with every next retry up to RetryMaxBackoff.
+	// The reports are retried without delay when it is zero.
+	RetryBackoff time.Duration
+	// RetryMaxBackoff caps the delay between the retries, the delay is capped at an hour when it is zero.
+	RetryMaxBackoff time.Duration
+	// RetryJitter is the fraction of the delay, from 0 to 1, the delay is randomly moved by.
+	RetryJitter float64
+	// RateLimit is the number of senders, so the number of reports sent at the same time, a single sender is used when it is zero.
+	RateLimit int
+	ShaKey    string
+	CryptoKey string
+	// GRPCClient replaces the HTTP report with UpdateMetrics calls when set.
+	GRPCClient pb.MetricsClient
+	// Labels are attached to every reported metric.
+	Labels map[string]string
 }
 
 func New(options Options) *Agent {
 	return &Agent{
-		client:         options.Client,
-		storage:        options.Storage,
-		pollInterval:   options.PollInterval,
-		reportInterval: options.ReportInterval,
-		sendAddr:       options.SendAddr,
-		maxRetries:     options.MaxRetries,
-		shaKey:         options.ShaKey,
-		cryptoKey:      options.CryptoKey,
+		client:          options.Client,
+		storage:         options.Storage,
+		pollInterval:    options.PollInterval,
+		reportInterval:  options.ReportInterval,
+		sendAddr:        options.SendAddr,
+		maxRetries:      options.MaxRetries,
+		rateLimit:       options.RateLimit,
+		retryBackoff:    options.RetryBackoff,
+		retryMaxBackoff: options.RetryMaxBackoff,
+		retryJitter:     options.RetryJitter,
+		shaKey:          options.ShaKey,
+		cryptoKey:       options.CryptoKey,
+		grpcClient:      options.GRPCClient,
+		labels:          options.Labels,
 	}
 }
@@ -8,18 +8,25 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
 	"github.com/AnatolySnegovskiy/metric/internal/mocks"
+	pb "github.com/AnatolySnegovskiy/metric/internal/proto"
 	"github.com/AnatolySnegovskiy/metric/internal/storages"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestNew(t *testing.T) {
@@ -206,7 +213,7 @@ func TestAgentReportTickerEmpty(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		httpClient := mocks.NewMockHTTPClient(ctrl)
-		resp := &http.Response{}
+		resp := &http.Response{Body: http.NoBody}
 		httpClient.EXPECT().Do(gomock.Any()).Return(resp, nil).AnyTimes()
 		a := &Agent{
 			storage:        storages.NewMemStorage(),
@@ -233,7 +240,7 @@ func TestAgentErrorCrypto(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		httpClient := mocks.NewMockHTTPClient(ctrl)
-		resp := &http.Response{}
+		resp := &http.Response{Body: http.NoBody}
 		httpClient.EXPECT().Do(gomock.Any()).Return(resp, nil).AnyTimes()
 		a := Agent{
 			storage:        mockStorage,
@@ -247,12 +254,234 @@ func TestAgentErrorCrypto(t *testing.T) {
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer cancel()
-		err := a.sendMetricsPeriodically(ctx)
+		err := a.sendMetricsHTTP(ctx, a.collectMetrics(ctx))
 
 		assert.Error(t, err)
 	})
 }
 
+// blockingClient holds every request until its context is done and counts the requests sent at the same time.
+type blockingClient struct {
+	mu          sync.Mutex
+	calls       int
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *blockingClient) Do(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.calls++
+	c.inFlight++
+	c.maxInFlight = max(c.maxInFlight, c.inFlight)
+	c.mu.Unlock()
+
+	<-req.Context().Done()
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+
+	return nil, req.Context().Err()
+}
+
+func TestAgentSenderPool(t *testing.T) {
+	mockStorage := storages.NewMemStorage()
+	mockStorage.AddMetric("gauge", metrics.NewGauge(nil))
+	mockStorage.AddMetric("counter", metrics.NewCounter(nil))
+	client := &blockingClient{}
+	a := New(Options{
+		Storage:        mockStorage,
+		Client:         client,
+		PollInterval:   1,
+		ReportInterval: 1,
+		SendAddr:       "testAddr",
+		MaxRetries:     10,
+		RateLimit:      2,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3500*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, a.Run(ctx))
+	assert.Equal(t, 2, client.calls)
+	assert.Equal(t, 2, client.maxInFlight)
+	assert.Equal(t, 0, client.inFlight)
+}
+
+func TestAgentServerOutage(t *testing.T) {
+	mockStorage := storages.NewMemStorage()
+	mockStorage.AddMetric("gauge", metrics.NewGauge(nil))
+	mockStorage.AddMetric("counter", metrics.NewCounter(nil))
+	ctrl := gomock.NewController(t)
+	httpClient := mocks.NewMockHTTPClient(ctrl)
+	httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}, nil
+	}).MinTimes(4)
+	a := New(Options{
+		Storage:        mockStorage,
+		Client:         httpClient,
+		PollInterval:   1,
+		ReportInterval: 1,
+		SendAddr:       "testAddr",
+		MaxRetries:     1,
+		RetryBackoff:   10 * time.Millisecond,
+		RateLimit:      3,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3500*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, a.Run(ctx))
+}
+
+func TestIsRetriable(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retriable bool
+	}{
+		{"InternalServerError", &statusError{code: http.StatusInternalServerError}, true},
+		{"BadGateway", fmt.Errorf("wrapped: %w", &statusError{code: http.StatusBadGateway}), true},
+		{"BadRequest", &statusError{code: http.StatusBadRequest}, false},
+		{"Forbidden", &statusError{code: http.StatusForbidden}, false},
+		{"NetworkError", &url.Error{Op: "Post", URL: "http://testAddr/updates/", Err: errors.New("connection refused")}, true},
+		{"GRPCUnavailable", status.Error(codes.Unavailable, "unavailable"), true},
+		{"GRPCInvalidArgument", status.Error(codes.InvalidArgument, "invalid"), false},
+		{"GRPCResourceExhausted", status.Error(codes.ResourceExhausted, "quota"), false},
+		{"LocalError", errors.New("no such file"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.retriable, isRetriable(tc.err))
+		})
+	}
+}
+
+func TestAgentBackoff(t *testing.T) {
+	a := New(Options{RetryBackoff: 100 * time.Millisecond, RetryMaxBackoff: 300 * time.Millisecond})
+	assert.Equal(t, 100*time.Millisecond, a.backoff(0))
+	assert.Equal(t, 200*time.Millisecond, a.backoff(1))
+	assert.Equal(t, 300*time.Millisecond, a.backoff(2))
+	assert.Equal(t, 300*time.Millisecond, a.backoff(10))
+
+	a.retryMaxBackoff = 0
+	assert.Equal(t, 800*time.Millisecond, a.backoff(3))
+	assert.Equal(t, maxRetryBackoff, a.backoff(100))
+
+	a.retryJitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := a.backoff(0)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+		assert.LessOrEqual(t, delay, 150*time.Millisecond)
+	}
+
+	a.retryBackoff = time.Second
+	for attempt := 0; attempt < 100; attempt++ {
+		assert.Greater(t, a.backoff(attempt), time.Duration(0))
+	}
+}
+
+func TestAgentSendWithRetry(t *testing.T) {
+	testCases := []struct {
+		name     string
+		resp     *http.Response
+		err      error
+		attempts int
+		expected string
+	}{
+		{"ServerError", &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil, 3, "giving up after 2 retries"},
+		{"NetworkError", nil, &url.Error{Op: "Post", URL: "http://testAddr/updates/", Err: errors.New("connection refused")}, 3, "giving up after 2 retries"},
+		{"ClientError", &http.Response{StatusCode: http.StatusBadRequest, Body: http.NoBody}, nil, 1, "permanent error"},
+		{"Success", &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil, 1, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			httpClient := mocks.NewMockHTTPClient(ctrl)
+			httpClient.EXPECT().Do(gomock.Any()).Return(tc.resp, tc.err).Times(tc.attempts)
+			a := New(Options{
+				Storage:      storages.NewMemStorage(),
+				Client:       httpClient,
+				SendAddr:     "testAddr",
+				MaxRetries:   2,
+				RetryBackoff: time.Millisecond,
+				RetryJitter:  0.2,
+			})
+
+			err := a.sendWithRetry(context.Background(), a.collectMetrics(context.Background()))
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expected)
+			}
+		})
+	}
+
+	t.Run("ContextDone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		httpClient := mocks.NewMockHTTPClient(ctrl)
+		httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}, nil).Times(1)
+		a := New(Options{
+			Storage:      storages.NewMemStorage(),
+			Client:       httpClient,
+			SendAddr:     "testAddr",
+			MaxRetries:   5,
+			RetryBackoff: time.Hour,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := a.sendWithRetry(ctx, a.collectMetrics(ctx))
+		assert.ErrorContains(t, err, "unexpected status code: 500")
+	})
+}
+
+type fakeMetricsClient struct {
+	pb.MetricsClient
+	requests []*pb.UpdateMetricsRequest
+	err      error
+}
+
+func (f *fakeMetricsClient) UpdateMetrics(_ context.Context, in *pb.UpdateMetricsRequest, _ ...grpc.CallOption) (*pb.UpdateMetricsResponse, error) {
+	f.requests = append(f.requests, in)
+	return &pb.UpdateMetricsResponse{Metrics: in.GetMetrics()}, f.err
+}
+
+func TestAgentSendMetricsGRPC(t *testing.T) {
+	mockStorage := storages.NewMemStorage()
+	gauge := metrics.NewGauge(nil)
+	counter := metrics.NewCounter(nil)
+	_ = gauge.Process(context.Background(), "Alloc", "1.5")
+	_ = counter.Process(context.Background(), "PollCount", "3")
+	mockStorage.AddMetric("gauge", gauge)
+	mockStorage.AddMetric("counter", counter)
+
+	client := &fakeMetricsClient{}
+	a := New(Options{
+		Storage:    mockStorage,
+		GRPCClient: client,
+	})
+
+	assert.NoError(t, a.sendMetrics(context.Background(), a.collectMetrics(context.Background())))
+	assert.Len(t, client.requests, 1)
+	assert.Len(t, client.requests[0].GetMetrics(), 2)
+
+	for _, m := range client.requests[0].GetMetrics() {
+		switch m.GetType() {
+		case "gauge":
+			assert.Equal(t, 1.5, m.GetValue())
+		case "counter":
+			assert.Equal(t, int64(3), m.GetDelta())
+		}
+	}
+
+	client.err = errors.New("unavailable")
+	assert.Error(t, a.sendMetrics(context.Background(), a.collectMetrics(context.Background())))
+}
+
 func TestEncryptMessage(t *testing.T) {
 	mockPublicKeyData := []byte("mocked_public_key_data")
 	mockPublicKeyBlock := &pem.Block{Bytes: []byte("mocked_public_key_block")}
@@ -346,3 +575,21 @@ func generateRSAKeys() (string, string) {
 	publicKeyPath, _ := filepath.Abs(publicKeyFile.Name())
 	return privateKeyPath, publicKeyPath
 }
+
+func TestAgentCollectMetricsLabels(t *testing.T) {
+	mockStorage := storages.NewMemStorage()
+	gauge := metrics.NewGauge(nil)
+	_ = gauge.Process(context.Background(), "Alloc", "1.5")
+	mockStorage.AddMetric("gauge", gauge)
+
+	labels := map[string]string{"host": "web1", "env": "prod"}
+	a := New(Options{
+		Storage: mockStorage,
+		Labels:  labels,
+	})
+
+	collection := a.collectMetrics(context.Background())
+	assert.Len(t, collection, 1)
+	assert.Equal(t, labels, collection[0].Labels)
+	assert.Equal(t, labels, pb.FromDTO(collection[0]).GetLabels())
+}
@@ -15,40 +15,16 @@ import (
 	"net/http"
 	"os"
 
+	pb "github.com/AnatolySnegovskiy/metric/internal/proto"
 	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
 	"github.com/mailru/easyjson"
+	"google.golang.org/grpc"
+	grpcgzip "google.golang.org/grpc/encoding/gzip"
 )
 
-func (a *Agent) sendMetricsPeriodically(ctx context.Context) error {
-	metricDtoCollection := dto.MetricsCollection{}
-
-	for storageType, storage := range a.storage.GetList() {
-		if storage == nil {
-			continue
-		}
-
-		list, _ := storage.GetList(ctx)
-
-		for metricName, metric := range list {
-
-			metricDto := dto.Metrics{
-				ID:    metricName,
-				MType: storageType,
-			}
-
-			if storageType == "counter" {
-				iv := int64(metric)
-				newIv := iv
-				metricDto.Delta = &newIv
-			} else {
-				newMetric := metric
-				metricDto.Value = &newMetric
-			}
-
-			metricDtoCollection = append(metricDtoCollection, metricDto)
-		}
-	}
-	body, _ := easyjson.Marshal(metricDtoCollection)
+// sendMetricsHTTP posts the batch gzipped to /updates/, the body is signed with the key and encrypted with the public key when they are set.
+func (a *Agent) sendMetricsHTTP(ctx context.Context, batch dto.MetricsCollection) error {
+	body, _ := easyjson.Marshal(batch)
 	var buf bytes.Buffer
 	gw := gzip.NewWriter(&buf)
 	_, _ = gw.Write(body)
@@ -82,13 +58,57 @@ func (a *Agent) sendMetricsPeriodically(ctx context.Context) error {
 		defer resp.Body.Close()
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("unexpected status code: %d - %s", resp.StatusCode, string(body))
+			return &statusError{code: resp.StatusCode, body: string(body)}
 		}
 	}
 
 	return err
 }
 
+// sendMetricsGRPC sends the batch in a single UpdateMetrics call, replacing the HTTP report.
+func (a *Agent) sendMetricsGRPC(ctx context.Context, batch dto.MetricsCollection) error {
+	req := &pb.UpdateMetricsRequest{Metrics: pb.FromCollection(batch)}
+	_, err := a.grpcClient.UpdateMetrics(ctx, req, grpc.UseCompressor(grpcgzip.Name))
+
+	return err
+}
+
+// collectMetrics converts every metric in the agent storage to a DTO with the static labels of the agent,
+// counters are reported as deltas.
+func (a *Agent) collectMetrics(ctx context.Context) dto.MetricsCollection {
+	metricDtoCollection := dto.MetricsCollection{}
+
+	for storageType, storage := range a.storage.GetList() {
+		if storage == nil {
+			continue
+		}
+
+		list, _ := storage.GetList(ctx)
+
+		for metricName, metric := range list {
+
+			metricDto := dto.Metrics{
+				ID:     metricName,
+				MType:  storageType,
+				Labels: a.labels,
+			}
+
+			if storageType == "counter" {
+				iv := int64(metric)
+				newIv := iv
+				metricDto.Delta = &newIv
+			} else {
+				newMetric := metric
+				metricDto.Value = &newMetric
+			}
+
+			metricDtoCollection = append(metricDtoCollection, metricDto)
+		}
+	}
+
+	return metricDtoCollection
+}
+
 func encryptMessage(message []byte, publicKeyPath string) ([]byte, error) {
 	publicKeyData, err := os.ReadFile(publicKeyPath)
 	if err != nil {
@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError is the error of a report the server answered with a status other than 200 OK.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d - %s", e.code, e.body)
+}
+
+// isRetriable reports whether a report that failed with the error can succeed when it is sent again:
+// network errors, 5xx statuses and the gRPC codes of an unavailable or overloaded server.
+// 4xx statuses and the errors raised before anything was sent, such as a missing key, are permanent.
+func isRetriable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= http.StatusInternalServerError
+	}
+
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		switch st.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.Internal:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// maxRetryBackoff caps the delay between the retries when no maximum backoff is set, so the doubling can't overflow.
+const maxRetryBackoff = time.Hour
+
+// backoff returns the delay before the retry of the attempt: the initial backoff doubled with every attempt
+// up to the maximum backoff, moved by a random part of the jitter fraction so that the agents don't retry in step.
+func (a *Agent) backoff(attempt int) time.Duration {
+	limit := a.retryMaxBackoff
+	if limit <= 0 {
+		limit = maxRetryBackoff
+	}
+
+	delay := a.retryBackoff
+	for i := 0; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+
+	if a.retryJitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * a.retryJitter * float64(delay))
+	}
+
+	return delay
+}
+
+// sendWithRetry sends the batch and sends it again up to maxRetries times while it fails with a retriable error.
+// The wait before the next attempt ends with the error of the last attempt when the context is done.
+func (a *Agent) sendWithRetry(ctx context.Context, batch dto.MetricsCollection) error {
+	for attempt := 0; ; attempt++ {
+		err := a.sendMetrics(ctx, batch)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if !isRetriable(err) {
+			return fmt.Errorf("permanent error: %w", err)
+		}
+		if attempt >= a.maxRetries {
+			return fmt.Errorf("giving up after %d retries: %w", attempt, err)
+		}
+
+		delay := a.backoff(attempt)
+		log.Printf("error occurred while sending metrics, retry %d in %s: %s", attempt+1, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
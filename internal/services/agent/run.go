@@ -4,42 +4,125 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync"
 	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
 )
 
+// Run collects the metrics at the poll interval and reports them at the report interval until the context is done.
+// The collectors write their samples into a channel that is drained into the agent storage,
+// every report is a batch handed to one of rateLimit senders, so no more than rateLimit requests are in flight.
+// A report is skipped when every sender is busy, the next one carries the same series.
+// A report that fails with a network error or a 5xx status is retried with backoff by its sender, a report that fails otherwise
+// or runs out of retries is logged and dropped, so an outage of the server never stops the agent.
+// Run fails when the samples can't be stored, the collectors and the senders are stopped before it returns.
 func (a *Agent) Run(ctx context.Context) error {
-	pollTicker := time.NewTicker(time.Duration(a.pollInterval) * time.Second)
+	ctx, cancel := context.WithCancel(ctx)
+
+	samples := make(chan []metricSample)
+	batches := make(chan dto.MetricsCollection)
+	results := make(chan error)
+
+	var wg sync.WaitGroup
+	for _, collector := range []func() []metricSample{runtimeSamples, gopsutilSamples} {
+		wg.Add(1)
+		go a.collect(ctx, &wg, collector, samples)
+	}
+
+	for i := 0; i < a.senders(); i++ {
+		wg.Add(1)
+		go a.sendWorker(ctx, &wg, batches, results)
+	}
+
+	err := a.aggregate(ctx, samples, batches, results)
+
+	cancel()
+	close(batches)
+	wg.Wait()
+
+	return err
+}
+
+// senders returns the size of the sender pool, a single sender when the rate limit isn't set.
+func (a *Agent) senders() int {
+	if a.rateLimit < 1 {
+		return 1
+	}
+
+	return a.rateLimit
+}
+
+// collect writes the samples of the collector into the channel at the poll interval until the context is done.
+func (a *Agent) collect(ctx context.Context, wg *sync.WaitGroup, collector func() []metricSample, samples chan<- []metricSample) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(time.Duration(a.pollInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			select {
+			case samples <- collector():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// sendWorker sends the batches with retries until the channel is closed and reports the result of every send.
+func (a *Agent) sendWorker(ctx context.Context, wg *sync.WaitGroup, batches <-chan dto.MetricsCollection, results chan<- error) {
+	defer wg.Done()
+
+	for batch := range batches {
+		err := a.sendWithRetry(ctx, batch)
+
+		select {
+		case results <- err:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// aggregate stores the collected samples and hands a batch of the stored metrics to an idle sender at the report interval.
+func (a *Agent) aggregate(ctx context.Context, samples <-chan []metricSample, batches chan<- dto.MetricsCollection, results <-chan error) error {
 	reportTicker := time.NewTicker(time.Duration(a.reportInterval) * time.Second)
-	retrievableCounter := 0
+	defer reportTicker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-pollTicker.C:
-			err := a.updateStoragePeriodically(ctx)
-			if err != nil {
-				return fmt.Errorf("error occurred while updating storage: %w", err)
-			}
-			log.Println("storage updated")
-		case <-pollTicker.C:
-			err := a.updateGopsutil(ctx)
-			if err != nil {
+		case batch := <-samples:
+			if err := a.storeSamples(ctx, batch); err != nil {
 				return fmt.Errorf("error occurred while updating storage: %w", err)
 			}
 			log.Println("storage updated")
 		case <-reportTicker.C:
-			err := a.sendMetricsPeriodically(ctx)
+			select {
+			case batches <- a.collectMetrics(ctx):
+			default:
+				log.Println("report skipped, every sender is busy")
+			}
+		case err := <-results:
 			if err != nil {
-				if retrievableCounter < a.maxRetries {
-					retrievableCounter++
-					log.Println(err)
-					continue
-				}
-				return fmt.Errorf("error occurred while sending metrics: %w", err)
+				log.Printf("metrics dropped: %s", err)
+				continue
 			}
-			retrievableCounter = 0
 			log.Println("metrics sent")
 		}
 	}
 }
+
+// sendMetrics reports the batch over gRPC when a gRPC client is configured and over HTTP otherwise.
+func (a *Agent) sendMetrics(ctx context.Context, batch dto.MetricsCollection) error {
+	if a.grpcClient != nil {
+		return a.sendMetricsGRPC(ctx, batch)
+	}
+
+	return a.sendMetricsHTTP(ctx, batch)
+}
@@ -10,58 +10,57 @@ import (
 	"github.com/shirou/gopsutil/mem"
 )
 
-var m runtime.MemStats
 var runtimeEntityArray = []string{"Alloc", "BuckHashSys", "Frees", "GCCPUFraction", "GCSys", "HeapAlloc", "HeapIdle", "HeapInuse",
 	"HeapObjects", "HeapReleased", "HeapSys", "LastGC", "Lookups", "MCacheInuse", "MCacheSys", "MSpanInuse",
 	"MSpanSys", "Mallocs", "NextGC", "NumForcedGC", "NumGC", "OtherSys", "PauseTotalNs", "StackInuse",
 	"StackSys", "Sys", "TotalAlloc"}
 
-func (a *Agent) updateStoragePeriodically(ctx context.Context) error {
-	runtime.ReadMemStats(&m)
-
-	gauge, err := a.storage.GetMetricType("gauge")
-	if err != nil {
-		return fmt.Errorf("error getting gauge: %w", err)
-	}
+// metricSample is a value read by a collector, it is stored with EntityMetric.Process of its metric type.
+type metricSample struct {
+	metricType string
+	name       string
+	value      string
+}
 
-	counter, err := a.storage.GetMetricType("counter")
-	if err != nil {
-		return fmt.Errorf("error getting counter: %w", err)
-	}
+// runtimeSamples reads the memory statistics of the runtime, a PollCount increment and a random value.
+func runtimeSamples() []metricSample {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
 
-	if counter.Process(ctx, "PollCount", "1") != nil {
-		return fmt.Errorf("error while processing field: PollCount")
-	}
-	if gauge.Process(ctx, "RandomValue", fmt.Sprintf("%v", time.Now().UnixNano())) != nil {
-		return fmt.Errorf("error while processing field: RandomValue")
+	samples := []metricSample{
+		{metricType: "counter", name: "PollCount", value: "1"},
+		{metricType: "gauge", name: "RandomValue", value: fmt.Sprintf("%v", time.Now().UnixNano())},
 	}
-
 	for _, field := range runtimeEntityArray {
-		if gauge.Process(ctx, field, fmt.Sprintf("%v", reflect.ValueOf(m).FieldByName(field))) != nil {
-			return fmt.Errorf("error while processing field: %s", field)
-		}
+		samples = append(samples, metricSample{metricType: "gauge", name: field, value: fmt.Sprintf("%v", reflect.ValueOf(m).FieldByName(field))})
 	}
 
-	return nil
+	return samples
 }
-func (a *Agent) updateGopsutil(ctx context.Context) error {
-	gauge, err := a.storage.GetMetricType("gauge")
-	if err != nil {
-		return fmt.Errorf("error getting gauge: %w", err)
-	}
+
+// gopsutilSamples reads the virtual memory statistics of the host.
+func gopsutilSamples() []metricSample {
 	v, _ := mem.VirtualMemory()
 
-	if err := gauge.Process(ctx, "TotalMemory", fmt.Sprintf("%v", v.Total)); err != nil {
-		return err
+	return []metricSample{
+		{metricType: "gauge", name: "TotalMemory", value: fmt.Sprintf("%v", v.Total)},
+		{metricType: "gauge", name: "FreeMemory", value: fmt.Sprintf("%v", v.Free)},
+		{metricType: "gauge", name: "CPUutilization1", value: fmt.Sprintf("%v", v.UsedPercent)},
 	}
+}
 
-	if err := gauge.Process(ctx, "FreeMemory", fmt.Sprintf("%v", v.Free)); err != nil {
-		return err
-	}
+// storeSamples adds the samples to the agent storage.
+func (a *Agent) storeSamples(ctx context.Context, samples []metricSample) error {
+	for _, sample := range samples {
+		entity, err := a.storage.GetMetricType(sample.metricType)
+		if err != nil {
+			return fmt.Errorf("error getting %s: %w", sample.metricType, err)
+		}
 
-	if err := gauge.Process(ctx, "CPUutilization1", fmt.Sprintf("%v", v.UsedPercent)); err != nil {
-		return err
+		if err := entity.Process(ctx, sample.name, sample.value); err != nil {
+			return fmt.Errorf("error while processing field: %s", sample.name)
+		}
 	}
 
-	return err
+	return nil
 }
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+//go:generate easyjson -all
+
+// Alert is the state of an alert rule for a single series.
+type Alert struct {
+	Rule       string            `json:"rule"`
+	Tenant     string            `json:"tenant,omitempty"`
+	State      string            `json:"state"`
+	MType      string            `json:"type"`
+	ID         string            `json:"id"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Value      float64           `json:"value"`
+	ActiveAt   time.Time         `json:"active_at"`
+	FiredAt    *time.Time        `json:"fired_at,omitempty"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+}
+
+//easyjson:json
+type AlertCollection []Alert
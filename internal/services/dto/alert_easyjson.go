@@ -0,0 +1,269 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package dto
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson76a405cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(in *jlexer.Lexer, out *AlertCollection) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(AlertCollection, 0, 0)
+			} else {
+				*out = AlertCollection{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 Alert
+			(v1).UnmarshalEasyJSON(in)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson76a405cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(out *jwriter.Writer, in AlertCollection) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AlertCollection) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson76a405cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AlertCollection) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson76a405cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AlertCollection) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson76a405cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AlertCollection) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson76a405cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(l, v)
+}
+func easyjson76a405cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(in *jlexer.Lexer, out *Alert) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "rule":
+			out.Rule = string(in.String())
+		case "tenant":
+			out.Tenant = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		case "type":
+			out.MType = string(in.String())
+		case "id":
+			out.ID = string(in.String())
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					v4 = string(in.String())
+					(out.Labels)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "value":
+			out.Value = float64(in.Float64())
+		case "active_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ActiveAt).UnmarshalJSON(data))
+			}
+		case "fired_at":
+			if in.IsNull() {
+				in.Skip()
+				out.FiredAt = nil
+			} else {
+				if out.FiredAt == nil {
+					out.FiredAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.FiredAt).UnmarshalJSON(data))
+				}
+			}
+		case "resolved_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ResolvedAt = nil
+			} else {
+				if out.ResolvedAt == nil {
+					out.ResolvedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ResolvedAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson76a405cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(out *jwriter.Writer, in Alert) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rule\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Rule))
+	}
+	if in.Tenant != "" {
+		const prefix string = ",\"tenant\":"
+		out.RawString(prefix)
+		out.String(string(in.Tenant))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.MType))
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.String(string(in.ID))
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v5First := true
+			for v5Name, v5Value := range in.Labels {
+				if v5First {
+					v5First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v5Name))
+				out.RawByte(':')
+				out.String(string(v5Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	{
+		const prefix string = ",\"active_at\":"
+		out.RawString(prefix)
+		out.Raw((in.ActiveAt).MarshalJSON())
+	}
+	if in.FiredAt != nil {
+		const prefix string = ",\"fired_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.FiredAt).MarshalJSON())
+	}
+	if in.ResolvedAt != nil {
+		const prefix string = ",\"resolved_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ResolvedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Alert) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson76a405cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Alert) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson76a405cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Alert) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson76a405cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Alert) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson76a405cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(l, v)
+}
@@ -0,0 +1,98 @@
+package dto
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrHistogramBounds is returned when histograms with different bucket bounds are merged.
+var ErrHistogramBounds = errors.New("histogram bucket bounds do not match")
+
+// ErrNonFiniteValue is returned when NaN or an infinity is observed, such values can't be placed in a bucket or a bin.
+var ErrNonFiniteValue = errors.New("observed value must be a finite number")
+
+// isFinite reports whether the value is neither NaN nor an infinity.
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// NewHistogram creates an empty histogram with the given bucket upper bounds.
+func NewHistogram(bounds []float64) Histogram {
+	return Histogram{
+		Bounds: append([]float64(nil), bounds...),
+		Counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe adds a single observation to the first bucket whose upper bound is not less than the value.
+func (h *Histogram) Observe(value float64) error {
+	if !isFinite(value) {
+		return ErrNonFiniteValue
+	}
+
+	i := sort.SearchFloat64s(h.Bounds, value)
+	h.Counts[i]++
+	h.Sum += value
+	h.Count++
+
+	return nil
+}
+
+// Merge adds the observations of another histogram with the same bounds.
+func (h *Histogram) Merge(other Histogram) error {
+	if err := other.Validate(); err != nil {
+		return err
+	}
+
+	if len(h.Bounds) != len(other.Bounds) {
+		return ErrHistogramBounds
+	}
+
+	for i, bound := range h.Bounds {
+		if bound != other.Bounds[i] {
+			return ErrHistogramBounds
+		}
+	}
+
+	for i, count := range other.Counts {
+		h.Counts[i] += count
+	}
+	h.Sum += other.Sum
+	h.Count += other.Count
+
+	return nil
+}
+
+// Validate checks that bounds are finite and sorted, the sum is finite and there is a count for every bucket including +Inf.
+func (h *Histogram) Validate() error {
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return errors.New("histogram must have one count per bound plus the +Inf bucket")
+	}
+
+	for _, bound := range h.Bounds {
+		if !isFinite(bound) {
+			return errors.New("histogram bounds must be finite")
+		}
+	}
+
+	if !sort.Float64sAreSorted(h.Bounds) {
+		return errors.New("histogram bounds must be sorted")
+	}
+
+	if !isFinite(h.Sum) {
+		return ErrNonFiniteValue
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy of the histogram.
+func (h *Histogram) Clone() Histogram {
+	return Histogram{
+		Bounds: append([]float64(nil), h.Bounds...),
+		Counts: append([]uint64(nil), h.Counts...),
+		Sum:    h.Sum,
+		Count:  h.Count,
+	}
+}
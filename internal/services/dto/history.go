@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+//go:generate easyjson -all
+
+// Sample is the value of a series at a point in time.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// HistoryPoint aggregates the samples of a series within one step, Time is the start of the step.
+type HistoryPoint struct {
+	Time  time.Time `json:"time"`
+	Avg   float64   `json:"avg"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Last  float64   `json:"last"`
+	Count int       `json:"count"`
+}
+
+// HistorySeries is the downsampled history of a series.
+type HistorySeries struct {
+	ID     string            `json:"id"`
+	MType  string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Points []HistoryPoint    `json:"points"`
+}
+
+//easyjson:json
+type HistorySeriesCollection []HistorySeries
@@ -0,0 +1,414 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package dto
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(in *jlexer.Lexer, out *Sample) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "time":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Time).UnmarshalJSON(data))
+			}
+		case "value":
+			out.Value = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(out *jwriter.Writer, in Sample) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"time\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.Time).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Sample) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Sample) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Sample) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Sample) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(l, v)
+}
+func easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(in *jlexer.Lexer, out *HistorySeriesCollection) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(HistorySeriesCollection, 0, 1)
+			} else {
+				*out = HistorySeriesCollection{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 HistorySeries
+			(v1).UnmarshalEasyJSON(in)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(out *jwriter.Writer, in HistorySeriesCollection) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HistorySeriesCollection) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HistorySeriesCollection) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HistorySeriesCollection) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HistorySeriesCollection) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(l, v)
+}
+func easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(in *jlexer.Lexer, out *HistorySeries) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = string(in.String())
+		case "type":
+			out.MType = string(in.String())
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					v4 = string(in.String())
+					(out.Labels)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "points":
+			if in.IsNull() {
+				in.Skip()
+				out.Points = nil
+			} else {
+				in.Delim('[')
+				if out.Points == nil {
+					if !in.IsDelim(']') {
+						out.Points = make([]HistoryPoint, 0, 1)
+					} else {
+						out.Points = []HistoryPoint{}
+					}
+				} else {
+					out.Points = (out.Points)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 HistoryPoint
+					(v5).UnmarshalEasyJSON(in)
+					out.Points = append(out.Points, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(out *jwriter.Writer, in HistorySeries) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.MType))
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v6First := true
+			for v6Name, v6Value := range in.Labels {
+				if v6First {
+					v6First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v6Name))
+				out.RawByte(':')
+				out.String(string(v6Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"points\":"
+		out.RawString(prefix)
+		if in.Points == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v7, v8 := range in.Points {
+				if v7 > 0 {
+					out.RawByte(',')
+				}
+				(v8).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HistorySeries) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HistorySeries) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HistorySeries) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HistorySeries) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(l, v)
+}
+func easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(in *jlexer.Lexer, out *HistoryPoint) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "time":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Time).UnmarshalJSON(data))
+			}
+		case "avg":
+			out.Avg = float64(in.Float64())
+		case "min":
+			out.Min = float64(in.Float64())
+		case "max":
+			out.Max = float64(in.Float64())
+		case "last":
+			out.Last = float64(in.Float64())
+		case "count":
+			out.Count = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(out *jwriter.Writer, in HistoryPoint) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"time\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.Time).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"avg\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Avg))
+	}
+	{
+		const prefix string = ",\"min\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Min))
+	}
+	{
+		const prefix string = ",\"max\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Max))
+	}
+	{
+		const prefix string = ",\"last\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Last))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Int(int(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HistoryPoint) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HistoryPoint) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson40eb0d12EncodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HistoryPoint) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HistoryPoint) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson40eb0d12DecodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(l, v)
+}
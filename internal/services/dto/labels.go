@@ -0,0 +1,171 @@
+package dto
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SeriesKey returns the key of a series, the name followed by the label set in braces.
+// Label names are sorted, so equal label sets always give the same key, a series without labels is keyed by its name.
+func SeriesKey(name string, labels map[string]string) string {
+	return JoinSeriesKey(name, FormatLabels(labels))
+}
+
+// JoinSeriesKey joins a name with labels formatted by FormatLabels.
+func JoinSeriesKey(name string, labels string) string {
+	if labels == "" {
+		return name
+	}
+
+	return name + "{" + labels + "}"
+}
+
+// SplitSeriesKey splits a series key into the name and the formatted labels.
+func SplitSeriesKey(key string) (string, string) {
+	i := strings.IndexByte(key, '{')
+	if i < 0 || !strings.HasSuffix(key, "}") {
+		return key, ""
+	}
+
+	return key[:i], key[i+1 : len(key)-1]
+}
+
+// ParseSeriesKey splits a series key into the name and the label set.
+// A key with malformed labels is treated as a name without labels.
+func ParseSeriesKey(key string) (string, map[string]string) {
+	name, formatted := SplitSeriesKey(key)
+	if formatted == "" {
+		return name, nil
+	}
+
+	labels, err := ParseLabels(formatted)
+	if err != nil {
+		return key, nil
+	}
+
+	return name, labels
+}
+
+// SeriesKeys returns the keys of a batch of series.
+func SeriesKeys[V any](items map[string]V) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// FormatLabels formats a label set as name="value" pairs sorted by name.
+// Backslashes, double quotes and line feeds in values are escaped.
+func FormatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(labels[name]))
+		b.WriteByte('"')
+	}
+
+	return b.String()
+}
+
+// ParseLabels parses labels formatted by FormatLabels.
+func ParseLabels(formatted string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for rest := formatted; rest != ""; {
+		eq := strings.Index(rest, `="`)
+		if eq < 0 {
+			return nil, fmt.Errorf("malformed labels: %s", formatted)
+		}
+		name := rest[:eq]
+		rest = rest[eq+2:]
+
+		var value strings.Builder
+		closed := false
+		for i := 0; i < len(rest); i++ {
+			c := rest[i]
+			if c == '\\' && i+1 < len(rest) {
+				i++
+				if rest[i] == 'n' {
+					value.WriteByte('\n')
+				} else {
+					value.WriteByte(rest[i])
+				}
+				continue
+			}
+
+			if c == '"' {
+				rest = rest[i+1:]
+				closed = true
+				break
+			}
+			value.WriteByte(c)
+		}
+
+		if !closed {
+			return nil, fmt.Errorf("malformed labels: %s", formatted)
+		}
+		labels[name] = value.String()
+
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("malformed labels: %s", formatted)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	return labels, nil
+}
+
+// ValidateMetricName checks that the name has none of the delimiters of the label set in a series key,
+// so the name can't address a labelled series and the key splits back into the same name.
+func ValidateMetricName(name string) error {
+	if strings.ContainsAny(name, `{}"`) {
+		return fmt.Errorf("invalid metric name %q: braces and double quotes are not allowed", name)
+	}
+
+	return nil
+}
+
+// ValidateLabels checks that every label name matches [a-zA-Z_][a-zA-Z0-9_]*.
+func ValidateLabels(labels map[string]string) error {
+	for name := range labels {
+		if !isLabelName(name) {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+	}
+
+	return nil
+}
+
+func isLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -1,12 +1,68 @@
 package dto
 
+import "time"
+
+// Metrics is a single series of a metric type.
+// UpdatedAt is the time the series was updated last, it is only filled in responses and is ignored on updates.
+//
 //go:generate easyjson -all
 type Metrics struct {
-	ID    string   `json:"id"`
-	MType string   `json:"type"`
-	Delta *int64   `json:"delta,omitempty"`
-	Value *float64 `json:"value,omitempty"`
+	ID        string            `json:"id"`
+	MType     string            `json:"type"`
+	Delta     *int64            `json:"delta,omitempty"`
+	Value     *float64          `json:"value,omitempty"`
+	Histogram *Histogram        `json:"histogram,omitempty"`
+	Summary   *Summary          `json:"summary,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	UpdatedAt *time.Time        `json:"updated_at,omitempty"`
 }
 
 //easyjson:json
 type MetricsCollection []Metrics
+
+// MetricsFilter selects the metrics of a bulk read, empty fields match every metric.
+// Regex is anchored at both ends, Labels holds label matchers such as host=web1,env!=dev.
+type MetricsFilter struct {
+	Types  []string `json:"types,omitempty"`
+	Names  []string `json:"names,omitempty"`
+	Prefix string   `json:"prefix,omitempty"`
+	Regex  string   `json:"regex,omitempty"`
+	Labels string   `json:"labels,omitempty"`
+}
+
+// Histogram carries bucket observations of a histogram metric.
+// Counts holds one non-cumulative count per bound plus the +Inf bucket as the last element.
+type Histogram struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Sum    float64   `json:"sum"`
+	Count  uint64    `json:"count"`
+}
+
+// Summary carries the state of a DDSketch quantile sketch.
+// Positive holds the bins of positive values, Negative the bins of absolute values of negative ones,
+// values too close to zero to be indexed are counted in Zero.
+// Quantiles is only filled in responses and is ignored on updates.
+type Summary struct {
+	RelativeAccuracy float64     `json:"relative_accuracy"`
+	Positive         []SketchBin `json:"positive,omitempty"`
+	Negative         []SketchBin `json:"negative,omitempty"`
+	Zero             uint64      `json:"zero,omitempty"`
+	Sum              float64     `json:"sum"`
+	Count            uint64      `json:"count"`
+	Min              float64     `json:"min"`
+	Max              float64     `json:"max"`
+	Quantiles        []Quantile  `json:"quantiles,omitempty"`
+}
+
+// SketchBin is the number of observations that fell into the logarithmic bucket with the given index.
+type SketchBin struct {
+	Index int32  `json:"index"`
+	Count uint64 `json:"count"`
+}
+
+// Quantile is an estimated value of a summary at the given quantile.
+type Quantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
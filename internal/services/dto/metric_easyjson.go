@@ -4,10 +4,10 @@ package dto
 
 import (
 	json "encoding/json"
-
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -18,7 +18,540 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(in *jlexer.Lexer, out *MetricsCollection) {
+func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(in *jlexer.Lexer, out *Summary) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "relative_accuracy":
+			out.RelativeAccuracy = float64(in.Float64())
+		case "positive":
+			if in.IsNull() {
+				in.Skip()
+				out.Positive = nil
+			} else {
+				in.Delim('[')
+				if out.Positive == nil {
+					if !in.IsDelim(']') {
+						out.Positive = make([]SketchBin, 0, 4)
+					} else {
+						out.Positive = []SketchBin{}
+					}
+				} else {
+					out.Positive = (out.Positive)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 SketchBin
+					(v1).UnmarshalEasyJSON(in)
+					out.Positive = append(out.Positive, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "negative":
+			if in.IsNull() {
+				in.Skip()
+				out.Negative = nil
+			} else {
+				in.Delim('[')
+				if out.Negative == nil {
+					if !in.IsDelim(']') {
+						out.Negative = make([]SketchBin, 0, 4)
+					} else {
+						out.Negative = []SketchBin{}
+					}
+				} else {
+					out.Negative = (out.Negative)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 SketchBin
+					(v2).UnmarshalEasyJSON(in)
+					out.Negative = append(out.Negative, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "zero":
+			out.Zero = uint64(in.Uint64())
+		case "sum":
+			out.Sum = float64(in.Float64())
+		case "count":
+			out.Count = uint64(in.Uint64())
+		case "min":
+			out.Min = float64(in.Float64())
+		case "max":
+			out.Max = float64(in.Float64())
+		case "quantiles":
+			if in.IsNull() {
+				in.Skip()
+				out.Quantiles = nil
+			} else {
+				in.Delim('[')
+				if out.Quantiles == nil {
+					if !in.IsDelim(']') {
+						out.Quantiles = make([]Quantile, 0, 4)
+					} else {
+						out.Quantiles = []Quantile{}
+					}
+				} else {
+					out.Quantiles = (out.Quantiles)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v3 Quantile
+					(v3).UnmarshalEasyJSON(in)
+					out.Quantiles = append(out.Quantiles, v3)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(out *jwriter.Writer, in Summary) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"relative_accuracy\":"
+		out.RawString(prefix[1:])
+		out.Float64(float64(in.RelativeAccuracy))
+	}
+	if len(in.Positive) != 0 {
+		const prefix string = ",\"positive\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v4, v5 := range in.Positive {
+				if v4 > 0 {
+					out.RawByte(',')
+				}
+				(v5).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Negative) != 0 {
+		const prefix string = ",\"negative\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v6, v7 := range in.Negative {
+				if v6 > 0 {
+					out.RawByte(',')
+				}
+				(v7).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Zero != 0 {
+		const prefix string = ",\"zero\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Zero))
+	}
+	{
+		const prefix string = ",\"sum\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sum))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	{
+		const prefix string = ",\"min\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Min))
+	}
+	{
+		const prefix string = ",\"max\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Max))
+	}
+	if len(in.Quantiles) != 0 {
+		const prefix string = ",\"quantiles\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v8, v9 := range in.Quantiles {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				(v9).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Summary) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Summary) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Summary) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Summary) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(l, v)
+}
+func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(in *jlexer.Lexer, out *SketchBin) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "index":
+			out.Index = int32(in.Int32())
+		case "count":
+			out.Count = uint64(in.Uint64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(out *jwriter.Writer, in SketchBin) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"index\":"
+		out.RawString(prefix[1:])
+		out.Int32(int32(in.Index))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SketchBin) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SketchBin) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SketchBin) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SketchBin) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(l, v)
+}
+func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(in *jlexer.Lexer, out *Quantile) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "quantile":
+			out.Quantile = float64(in.Float64())
+		case "value":
+			out.Value = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(out *jwriter.Writer, in Quantile) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"quantile\":"
+		out.RawString(prefix[1:])
+		out.Float64(float64(in.Quantile))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Quantile) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Quantile) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Quantile) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Quantile) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto2(l, v)
+}
+func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(in *jlexer.Lexer, out *MetricsFilter) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "types":
+			if in.IsNull() {
+				in.Skip()
+				out.Types = nil
+			} else {
+				in.Delim('[')
+				if out.Types == nil {
+					if !in.IsDelim(']') {
+						out.Types = make([]string, 0, 4)
+					} else {
+						out.Types = []string{}
+					}
+				} else {
+					out.Types = (out.Types)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v10 string
+					v10 = string(in.String())
+					out.Types = append(out.Types, v10)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "names":
+			if in.IsNull() {
+				in.Skip()
+				out.Names = nil
+			} else {
+				in.Delim('[')
+				if out.Names == nil {
+					if !in.IsDelim(']') {
+						out.Names = make([]string, 0, 4)
+					} else {
+						out.Names = []string{}
+					}
+				} else {
+					out.Names = (out.Names)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v11 string
+					v11 = string(in.String())
+					out.Names = append(out.Names, v11)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "prefix":
+			out.Prefix = string(in.String())
+		case "regex":
+			out.Regex = string(in.String())
+		case "labels":
+			out.Labels = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(out *jwriter.Writer, in MetricsFilter) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Types) != 0 {
+		const prefix string = ",\"types\":"
+		first = false
+		out.RawString(prefix[1:])
+		{
+			out.RawByte('[')
+			for v12, v13 := range in.Types {
+				if v12 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v13))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Names) != 0 {
+		const prefix string = ",\"names\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v14, v15 := range in.Names {
+				if v14 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v15))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Prefix != "" {
+		const prefix string = ",\"prefix\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Prefix))
+	}
+	if in.Regex != "" {
+		const prefix string = ",\"regex\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Regex))
+	}
+	if in.Labels != "" {
+		const prefix string = ",\"labels\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Labels))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MetricsFilter) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MetricsFilter) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MetricsFilter) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MetricsFilter) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto3(l, v)
+}
+func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto4(in *jlexer.Lexer, out *MetricsCollection) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -27,7 +560,7 @@ func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(i
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(MetricsCollection, 0, 1)
+				*out = make(MetricsCollection, 0, 0)
 			} else {
 				*out = MetricsCollection{}
 			}
@@ -35,9 +568,9 @@ func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(i
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v1 Metrics
-			(v1).UnmarshalEasyJSON(in)
-			*out = append(*out, v1)
+			var v16 Metrics
+			(v16).UnmarshalEasyJSON(in)
+			*out = append(*out, v16)
 			in.WantComma()
 		}
 		in.Delim(']')
@@ -46,16 +579,16 @@ func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(i
 		in.Consumed()
 	}
 }
-func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(out *jwriter.Writer, in MetricsCollection) {
+func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto4(out *jwriter.Writer, in MetricsCollection) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
 		out.RawByte('[')
-		for v2, v3 := range in {
-			if v2 > 0 {
+		for v17, v18 := range in {
+			if v17 > 0 {
 				out.RawByte(',')
 			}
-			(v3).MarshalEasyJSON(out)
+			(v18).MarshalEasyJSON(out)
 		}
 		out.RawByte(']')
 	}
@@ -64,27 +597,27 @@ func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(o
 // MarshalJSON supports json.Marshaler interface
 func (v MetricsCollection) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(&w, v)
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v MetricsCollection) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto(w, v)
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *MetricsCollection) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(&r, v)
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *MetricsCollection) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto(l, v)
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto4(l, v)
 }
-func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(in *jlexer.Lexer, out *Metrics) {
+func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto5(in *jlexer.Lexer, out *Metrics) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -127,6 +660,58 @@ func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(
 				}
 				*out.Value = float64(in.Float64())
 			}
+		case "histogram":
+			if in.IsNull() {
+				in.Skip()
+				out.Histogram = nil
+			} else {
+				if out.Histogram == nil {
+					out.Histogram = new(Histogram)
+				}
+				(*out.Histogram).UnmarshalEasyJSON(in)
+			}
+		case "summary":
+			if in.IsNull() {
+				in.Skip()
+				out.Summary = nil
+			} else {
+				if out.Summary == nil {
+					out.Summary = new(Summary)
+				}
+				(*out.Summary).UnmarshalEasyJSON(in)
+			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v19 string
+					v19 = string(in.String())
+					(out.Labels)[key] = v19
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "updated_at":
+			if in.IsNull() {
+				in.Skip()
+				out.UpdatedAt = nil
+			} else {
+				if out.UpdatedAt == nil {
+					out.UpdatedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.UpdatedAt).UnmarshalJSON(data))
+				}
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -137,7 +722,7 @@ func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(
 		in.Consumed()
 	}
 }
-func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(out *jwriter.Writer, in Metrics) {
+func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto5(out *jwriter.Writer, in Metrics) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -161,29 +746,214 @@ func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(
 		out.RawString(prefix)
 		out.Float64(float64(*in.Value))
 	}
+	if in.Histogram != nil {
+		const prefix string = ",\"histogram\":"
+		out.RawString(prefix)
+		(*in.Histogram).MarshalEasyJSON(out)
+	}
+	if in.Summary != nil {
+		const prefix string = ",\"summary\":"
+		out.RawString(prefix)
+		(*in.Summary).MarshalEasyJSON(out)
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v20First := true
+			for v20Name, v20Value := range in.Labels {
+				if v20First {
+					v20First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v20Name))
+				out.RawByte(':')
+				out.String(string(v20Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if in.UpdatedAt != nil {
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.UpdatedAt).MarshalJSON())
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Metrics) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(&w, v)
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto5(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Metrics) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(w, v)
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto5(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Metrics) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(&r, v)
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto5(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Metrics) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto1(l, v)
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto5(l, v)
+}
+func easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto6(in *jlexer.Lexer, out *Histogram) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "bounds":
+			if in.IsNull() {
+				in.Skip()
+				out.Bounds = nil
+			} else {
+				in.Delim('[')
+				if out.Bounds == nil {
+					if !in.IsDelim(']') {
+						out.Bounds = make([]float64, 0, 8)
+					} else {
+						out.Bounds = []float64{}
+					}
+				} else {
+					out.Bounds = (out.Bounds)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v21 float64
+					v21 = float64(in.Float64())
+					out.Bounds = append(out.Bounds, v21)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "counts":
+			if in.IsNull() {
+				in.Skip()
+				out.Counts = nil
+			} else {
+				in.Delim('[')
+				if out.Counts == nil {
+					if !in.IsDelim(']') {
+						out.Counts = make([]uint64, 0, 8)
+					} else {
+						out.Counts = []uint64{}
+					}
+				} else {
+					out.Counts = (out.Counts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v22 uint64
+					v22 = uint64(in.Uint64())
+					out.Counts = append(out.Counts, v22)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "sum":
+			out.Sum = float64(in.Float64())
+		case "count":
+			out.Count = uint64(in.Uint64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto6(out *jwriter.Writer, in Histogram) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"bounds\":"
+		out.RawString(prefix[1:])
+		if in.Bounds == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v23, v24 := range in.Bounds {
+				if v23 > 0 {
+					out.RawByte(',')
+				}
+				out.Float64(float64(v24))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"counts\":"
+		out.RawString(prefix)
+		if in.Counts == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v25, v26 := range in.Counts {
+				if v25 > 0 {
+					out.RawByte(',')
+				}
+				out.Uint64(uint64(v26))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"sum\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sum))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Histogram) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Histogram) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9478868cEncodeGithubComAnatolySnegovskiyMetricInternalServicesDto6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Histogram) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Histogram) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9478868cDecodeGithubComAnatolySnegovskiyMetricInternalServicesDto6(l, v)
 }
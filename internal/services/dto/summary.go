@@ -0,0 +1,220 @@
+package dto
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// DefaultRelativeAccuracy is the relative accuracy of quantiles of new summaries.
+const DefaultRelativeAccuracy = 0.01
+
+// minRelativeAccuracy keeps bin indexes of every finite value within int32.
+const minRelativeAccuracy = 0.0001
+
+// minIndexableValue is the smallest absolute value that gets its own bin, smaller values are counted as zero.
+const minIndexableValue = 1e-9
+
+// maxSketchBins limits the number of bins per sign, the lowest bins are collapsed once the limit is hit.
+const maxSketchBins = 2048
+
+// DefaultQuantiles are the quantiles reported when no quantiles are requested.
+var DefaultQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// ErrSummaryAccuracy is returned when summaries with different relative accuracy are merged.
+var ErrSummaryAccuracy = errors.New("summary relative accuracy does not match")
+
+// NewSummary creates an empty summary with the given relative accuracy.
+func NewSummary(relativeAccuracy float64) Summary {
+	return Summary{RelativeAccuracy: relativeAccuracy}
+}
+
+// Observe adds a single observation to the bin of its logarithmic bucket.
+func (s *Summary) Observe(value float64) error {
+	if !isFinite(value) {
+		return ErrNonFiniteValue
+	}
+
+	switch {
+	case value >= minIndexableValue:
+		s.Positive = addBin(s.Positive, s.index(value), 1)
+	case value <= -minIndexableValue:
+		s.Negative = addBin(s.Negative, s.index(-value), 1)
+	default:
+		s.Zero++
+	}
+
+	if s.Count == 0 || value < s.Min {
+		s.Min = value
+	}
+	if s.Count == 0 || value > s.Max {
+		s.Max = value
+	}
+	s.Sum += value
+	s.Count++
+
+	return nil
+}
+
+// Merge adds the observations of another summary with the same relative accuracy.
+func (s *Summary) Merge(other Summary) error {
+	if err := other.Validate(); err != nil {
+		return err
+	}
+
+	if s.RelativeAccuracy != other.RelativeAccuracy {
+		return ErrSummaryAccuracy
+	}
+
+	if other.Count == 0 {
+		return nil
+	}
+
+	for _, bin := range other.Positive {
+		s.Positive = addBin(s.Positive, bin.Index, bin.Count)
+	}
+	for _, bin := range other.Negative {
+		s.Negative = addBin(s.Negative, bin.Index, bin.Count)
+	}
+
+	if s.Count == 0 || other.Min < s.Min {
+		s.Min = other.Min
+	}
+	if s.Count == 0 || other.Max > s.Max {
+		s.Max = other.Max
+	}
+	s.Zero += other.Zero
+	s.Sum += other.Sum
+	s.Count += other.Count
+
+	return nil
+}
+
+// Quantile estimates the value at the quantile q in [0, 1], NaN is returned for an empty summary.
+// The bounds of the range are exact, they are the observed minimum and maximum.
+func (s *Summary) Quantile(q float64) float64 {
+	switch {
+	case s.Count == 0:
+		return math.NaN()
+	case q <= 0:
+		return s.Min
+	case q >= 1:
+		return s.Max
+	}
+
+	rank := q * float64(s.Count-1)
+	var cumulative uint64
+
+	for i := len(s.Negative) - 1; i >= 0; i-- {
+		cumulative += s.Negative[i].Count
+		if float64(cumulative) > rank {
+			return s.clamp(-s.value(s.Negative[i].Index))
+		}
+	}
+
+	cumulative += s.Zero
+	if float64(cumulative) > rank {
+		return 0
+	}
+
+	for _, bin := range s.Positive {
+		cumulative += bin.Count
+		if float64(cumulative) > rank {
+			return s.clamp(s.value(bin.Index))
+		}
+	}
+
+	return s.Max
+}
+
+// WithQuantiles returns a copy of the summary with the estimated values of the given quantiles.
+func (s *Summary) WithQuantiles(quantiles []float64) Summary {
+	summary := s.Clone()
+	for _, q := range quantiles {
+		summary.Quantiles = append(summary.Quantiles, Quantile{Quantile: q, Value: s.Quantile(q)})
+	}
+
+	return summary
+}
+
+// Validate checks the relative accuracy, the order of bins, that every observation is in a bin and that the sum and the range are finite.
+func (s *Summary) Validate() error {
+	if s.RelativeAccuracy < minRelativeAccuracy || s.RelativeAccuracy >= 1 {
+		return errors.New("summary relative accuracy must be in [0.0001, 1)")
+	}
+
+	count := s.Zero
+	for _, bins := range [][]SketchBin{s.Positive, s.Negative} {
+		for i, bin := range bins {
+			if i > 0 && bin.Index <= bins[i-1].Index {
+				return errors.New("summary bins must be sorted by index")
+			}
+			count += bin.Count
+		}
+	}
+
+	if count != s.Count {
+		return errors.New("summary count does not match the bins")
+	}
+
+	if !isFinite(s.Sum) || !isFinite(s.Min) || !isFinite(s.Max) {
+		return ErrNonFiniteValue
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy of the summary without quantiles.
+func (s *Summary) Clone() Summary {
+	return Summary{
+		RelativeAccuracy: s.RelativeAccuracy,
+		Positive:         append([]SketchBin(nil), s.Positive...),
+		Negative:         append([]SketchBin(nil), s.Negative...),
+		Zero:             s.Zero,
+		Sum:              s.Sum,
+		Count:            s.Count,
+		Min:              s.Min,
+		Max:              s.Max,
+	}
+}
+
+// gamma is the ratio between the upper bounds of two neighbouring bins.
+func (s *Summary) gamma() float64 {
+	return (1 + s.RelativeAccuracy) / (1 - s.RelativeAccuracy)
+}
+
+// index returns the bin index of a positive value.
+func (s *Summary) index(value float64) int32 {
+	return int32(math.Ceil(math.Log(value) / math.Log(s.gamma())))
+}
+
+// value returns the value of a bin that is within the relative accuracy of every value in the bin.
+func (s *Summary) value(index int32) float64 {
+	gamma := s.gamma()
+	return 2 * math.Pow(gamma, float64(index)) / (1 + gamma)
+}
+
+// clamp keeps an estimated value within the observed range.
+func (s *Summary) clamp(value float64) float64 {
+	return math.Max(s.Min, math.Min(s.Max, value))
+}
+
+// addBin adds count observations to the bin with the index, keeping the bins sorted.
+func addBin(bins []SketchBin, index int32, count uint64) []SketchBin {
+	i := sort.Search(len(bins), func(i int) bool { return bins[i].Index >= index })
+	if i < len(bins) && bins[i].Index == index {
+		bins[i].Count += count
+		return bins
+	}
+
+	bins = append(bins, SketchBin{})
+	copy(bins[i+1:], bins[i:])
+	bins[i] = SketchBin{Index: index, Count: count}
+
+	if len(bins) > maxSketchBins {
+		bins[1].Count += bins[0].Count
+		bins = bins[1:]
+	}
+
+	return bins
+}
@@ -0,0 +1,451 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/services/interfase"
+	"github.com/gookit/gsr"
+	"github.com/mailru/easyjson"
+)
+
+// States of an alert, an alert whose condition doesn't hold and never fired isn't listed at all.
+const (
+	alertPending  = "pending"
+	alertFiring   = "firing"
+	alertResolved = "resolved"
+)
+
+// defaultAlertInterval is the evaluation interval used when the configured one isn't positive.
+const defaultAlertInterval = 15 * time.Second
+
+// alertResolvedRetention is how long a resolved alert stays listed.
+const alertResolvedRetention = 15 * time.Minute
+
+// alertWebhookTimeout limits a single webhook call.
+const alertWebhookTimeout = 5 * time.Second
+
+// alertQuantities are the suffixes of thresholds, KB, MB, GB and TB are decimal, KiB, MiB, GiB and TiB are binary.
+// Longer suffixes go first, the first matching suffix wins.
+var alertQuantities = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"KB", 1e3},
+	{"MB", 1e6},
+	{"GB", 1e9},
+	{"TB", 1e12},
+	{"B", 1},
+}
+
+// alertRateUnits are the time units of rate thresholds in seconds.
+var alertRateUnits = map[string]float64{
+	"s":    1,
+	"sec":  1,
+	"m":    60,
+	"min":  60,
+	"h":    3600,
+	"hour": 3600,
+}
+
+// alertRule fires for every series of the metric type and name that match the matchers and the condition.
+type alertRule struct {
+	text       string
+	metricType string
+	name       string
+	matchers   []labelMatcher
+	rate       bool
+	op         string
+	threshold  float64
+	duration   time.Duration
+}
+
+// holds reports whether the value satisfies the condition of the rule, rates are per second.
+func (r alertRule) holds(value float64) bool {
+	switch r.op {
+	case ">":
+		return value > r.threshold
+	case ">=":
+		return value >= r.threshold
+	case "<":
+		return value < r.threshold
+	case "<=":
+		return value <= r.threshold
+	case "==":
+		return value == r.threshold
+	default:
+		return value != r.threshold
+	}
+}
+
+// loadAlertRules reads the alert rules file.
+func loadAlertRules(filePath string) ([]alertRule, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseAlertRules(file)
+}
+
+// parseAlertRules parses one rule per line, empty lines and lines starting with # are skipped.
+// A rule is "<type> <name>[{matchers}] [rate] <op> <threshold> [for <duration>]", for example
+// "gauge HeapAlloc > 500MB for 2m" or "counter PollCount{host=web1} rate < 1/min".
+func parseAlertRules(r io.Reader) ([]alertRule, error) {
+	var rules []alertRule
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		rule, err := parseAlertRule(text)
+		if err != nil {
+			return nil, fmt.Errorf("alert rules line %d: %w", line, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+func parseAlertRule(text string) (alertRule, error) {
+	fields := strings.Fields(text)
+	rule := alertRule{text: strings.Join(fields, " ")}
+
+	if len(fields) < 4 {
+		return rule, fmt.Errorf("invalid rule %s", text)
+	}
+	rule.metricType = fields[0]
+	rule.name = fields[1]
+	fields = fields[2:]
+
+	if i := strings.IndexByte(rule.name, '{'); i >= 0 {
+		if !strings.HasSuffix(rule.name, "}") {
+			return rule, fmt.Errorf("invalid series %s", rule.name)
+		}
+
+		matchers, err := parseLabelMatchers(rule.name[i+1 : len(rule.name)-1])
+		if err != nil {
+			return rule, err
+		}
+		rule.name, rule.matchers = rule.name[:i], matchers
+	}
+
+	if fields[0] == "rate" {
+		rule.rate = true
+		fields = fields[1:]
+	}
+
+	if len(fields) != 2 && len(fields) != 4 {
+		return rule, fmt.Errorf("invalid rule %s", text)
+	}
+
+	switch fields[0] {
+	case ">", ">=", "<", "<=", "==", "!=":
+		rule.op = fields[0]
+	default:
+		return rule, fmt.Errorf("invalid operator %s", fields[0])
+	}
+
+	var err error
+	if rule.threshold, err = parseAlertThreshold(fields[1], rule.rate); err != nil {
+		return rule, err
+	}
+
+	if len(fields) == 4 {
+		if fields[2] != "for" {
+			return rule, fmt.Errorf("invalid rule %s", text)
+		}
+
+		if rule.duration, err = time.ParseDuration(fields[3]); err != nil || rule.duration < 0 {
+			return rule, fmt.Errorf("invalid duration %s", fields[3])
+		}
+	}
+
+	return rule, nil
+}
+
+// parseAlertThreshold parses a number with an optional quantity suffix such as 500MB.
+// A rate threshold may end with a time unit such as 1/min, it is converted to a rate per second.
+func parseAlertThreshold(v string, rate bool) (float64, error) {
+	per := 1.0
+	if i := strings.IndexByte(v, '/'); i >= 0 && rate {
+		unit, ok := alertRateUnits[v[i+1:]]
+		if !ok {
+			return 0, fmt.Errorf("invalid rate unit %s", v[i+1:])
+		}
+		v, per = v[:i], unit
+	}
+
+	multiplier := 1.0
+	for _, quantity := range alertQuantities {
+		if strings.HasSuffix(v, quantity.suffix) {
+			v, multiplier = strings.TrimSuffix(v, quantity.suffix), quantity.multiplier
+			break
+		}
+	}
+
+	threshold, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid threshold %s", v)
+	}
+
+	return threshold * multiplier / per, nil
+}
+
+// alertKey identifies the alert of a rule for a single series.
+type alertKey struct {
+	rule int
+	key  string
+}
+
+// alertManager evaluates alert rules against the storage of a tenant and sends firing and resolved alerts to the webhook.
+type alertManager struct {
+	mu      sync.RWMutex
+	tenant  string
+	rules   []alertRule
+	alerts  map[alertKey]*dto.Alert
+	samples map[alertKey]dto.Sample
+	webhook string
+	client  *http.Client
+	logger  gsr.GenLogger
+}
+
+// newAlertManager returns the alert manager of the tenant, the alerts of the default tenant carry no tenant name.
+func newAlertManager(tenant string, rules []alertRule, webhook string, logger gsr.GenLogger) *alertManager {
+	return &alertManager{
+		tenant:  tenant,
+		rules:   rules,
+		alerts:  make(map[alertKey]*dto.Alert),
+		samples: make(map[alertKey]dto.Sample),
+		webhook: webhook,
+		client:  &http.Client{Timeout: alertWebhookTimeout},
+		logger:  logger,
+	}
+}
+
+// run evaluates the rules every interval until the context is done.
+func (m *alertManager) run(ctx context.Context, storage interfase.Storage, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultAlertInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.evaluate(ctx, storage, now)
+		}
+	}
+}
+
+// evaluate moves the alerts of every rule through the pending, firing and resolved states and notifies the webhook.
+// A pending alert fires once its condition held for the duration of the rule, a firing alert is resolved once it doesn't.
+func (m *alertManager) evaluate(ctx context.Context, storage interfase.Storage, now time.Time) {
+	var events []dto.Alert
+
+	m.mu.Lock()
+	for i, rule := range m.rules {
+		entity, err := storage.GetMetricType(rule.metricType)
+		if err != nil {
+			m.logger.Error(fmt.Errorf("alert rule %s: %w", rule.text, err))
+			continue
+		}
+
+		list, err := entity.GetList(ctx)
+		if err != nil {
+			m.logger.Error(fmt.Errorf("alert rule %s: %w", rule.text, err))
+			continue
+		}
+
+		active := make(map[string]bool)
+		for _, key := range selectSeries(list, rule.name, rule.matchers) {
+			k := alertKey{rule: i, key: key}
+			value := list[key]
+
+			if rule.rate {
+				prev, ok := m.samples[k]
+				m.samples[k] = dto.Sample{Time: now, Value: value}
+				if !ok || !now.After(prev.Time) {
+					active[key] = m.alerts[k] != nil && m.alerts[k].State != alertResolved
+					continue
+				}
+
+				delta := value - prev.Value
+				if delta < 0 {
+					delta = value
+				}
+				value = delta / now.Sub(prev.Time).Seconds()
+			}
+
+			if !rule.holds(value) {
+				continue
+			}
+
+			active[key] = true
+			if event, ok := m.activate(k, rule, key, value, now); ok {
+				events = append(events, event)
+			}
+		}
+
+		for k := range m.alerts {
+			if k.rule == i && !active[k.key] {
+				if event, ok := m.deactivate(k, now); ok {
+					events = append(events, event)
+				}
+			}
+		}
+
+		for k := range m.samples {
+			if k.rule == i {
+				if _, ok := list[k.key]; !ok {
+					delete(m.samples, k)
+				}
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for _, event := range events {
+		if err := m.notify(ctx, event); err != nil {
+			m.logger.Error(fmt.Errorf("alert webhook: %w", err))
+		}
+	}
+}
+
+// activate records that the condition of the rule holds, the alert is returned when it starts firing.
+func (m *alertManager) activate(k alertKey, rule alertRule, key string, value float64, now time.Time) (dto.Alert, bool) {
+	alert, ok := m.alerts[k]
+	if !ok || alert.State == alertResolved {
+		name, labels := dto.ParseSeriesKey(key)
+		alert = &dto.Alert{
+			Rule:     rule.text,
+			Tenant:   m.tenant,
+			State:    alertPending,
+			MType:    rule.metricType,
+			ID:       name,
+			Labels:   labels,
+			ActiveAt: now,
+		}
+		m.alerts[k] = alert
+	}
+	alert.Value = value
+
+	if alert.State == alertPending && now.Sub(alert.ActiveAt) >= rule.duration {
+		firedAt := now
+		alert.State = alertFiring
+		alert.FiredAt = &firedAt
+		return *alert, true
+	}
+
+	return dto.Alert{}, false
+}
+
+// deactivate records that the condition of the rule doesn't hold, the alert is returned when it gets resolved.
+func (m *alertManager) deactivate(k alertKey, now time.Time) (dto.Alert, bool) {
+	alert := m.alerts[k]
+
+	switch alert.State {
+	case alertPending:
+		delete(m.alerts, k)
+	case alertFiring:
+		resolvedAt := now
+		alert.State = alertResolved
+		alert.ResolvedAt = &resolvedAt
+		return *alert, true
+	default:
+		if now.Sub(*alert.ResolvedAt) > alertResolvedRetention {
+			delete(m.alerts, k)
+		}
+	}
+
+	return dto.Alert{}, false
+}
+
+// notify posts the alert to the webhook, nothing is sent when no webhook is configured.
+func (m *alertManager) notify(ctx context.Context, alert dto.Alert) error {
+	if m.webhook == "" {
+		return nil
+	}
+
+	body, err := easyjson.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// list returns the current alerts ordered by rule and series.
+func (m *alertManager) list() dto.AlertCollection {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]alertKey, 0, len(m.alerts))
+	for k := range m.alerts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].rule != keys[j].rule {
+			return keys[i].rule < keys[j].rule
+		}
+		return keys[i].key < keys[j].key
+	})
+
+	alerts := make(dto.AlertCollection, 0, len(keys))
+	for _, k := range keys {
+		alerts = append(alerts, *m.alerts[k])
+	}
+
+	return alerts
+}
+
+// alertsHandler writes the current alerts of the tenant of the request, the list is empty when no rules are configured.
+func (s *Server) alertsHandler(rw http.ResponseWriter, req *http.Request) {
+	alerts := dto.AlertCollection{}
+	if m, ok := s.alerts[s.tenantOf(req.Context()).name]; ok {
+		alerts = m.list()
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	json, _ := easyjson.Marshal(alerts)
+	fmt.Fprintf(rw, "%v", string(json))
+}
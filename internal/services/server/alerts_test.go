@@ -0,0 +1,233 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/mocks"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAlertRules(t *testing.T) {
+	rules, err := parseAlertRules(strings.NewReader(`
+# memory
+gauge HeapAlloc > 500MB for 2m
+counter PollCount{host=~web.*} rate < 1/min
+gauge Load   >=  1.5
+`))
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 3) {
+		assert.Equal(t, "gauge HeapAlloc > 500MB for 2m", rules[0].text)
+		assert.Equal(t, 500e6, rules[0].threshold)
+		assert.Equal(t, 2*time.Minute, rules[0].duration)
+		assert.False(t, rules[0].rate)
+
+		assert.Equal(t, "PollCount", rules[1].name)
+		assert.Len(t, rules[1].matchers, 1)
+		assert.True(t, rules[1].rate)
+		assert.InDelta(t, 1.0/60, rules[1].threshold, 1e-12)
+		assert.Equal(t, "<", rules[1].op)
+
+		assert.Equal(t, "gauge Load >= 1.5", rules[2].text)
+		assert.Equal(t, 1.5, rules[2].threshold)
+	}
+
+	testCases := []struct {
+		name string
+		rule string
+	}{
+		{name: "too short", rule: "gauge HeapAlloc >"},
+		{name: "bad operator", rule: "gauge HeapAlloc => 1"},
+		{name: "bad threshold", rule: "gauge HeapAlloc > much"},
+		{name: "bad quantity", rule: "gauge HeapAlloc > 5XB"},
+		{name: "bad rate unit", rule: "counter PollCount rate > 1/day"},
+		{name: "unit without rate", rule: "counter PollCount > 1/min"},
+		{name: "bad duration", rule: "gauge HeapAlloc > 1 for ever"},
+		{name: "bad keyword", rule: "gauge HeapAlloc > 1 during 2m"},
+		{name: "bad matcher", rule: "gauge HeapAlloc{host} > 1"},
+		{name: "unclosed matcher", rule: "gauge HeapAlloc{host=web > 1"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := parseAlertRules(strings.NewReader(testCase.rule))
+			assert.Error(t, err)
+		})
+	}
+
+	threshold, err := parseAlertThreshold("2KiB", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2048.0, threshold)
+}
+
+func TestAlertManager(t *testing.T) {
+	gauge := metrics.NewGauge(nil)
+	counter := metrics.NewCounter(nil)
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", gauge)
+	stg.AddMetric("counter", counter)
+
+	var events []dto.Alert
+	webhook := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		var alert dto.Alert
+		assert.NoError(t, easyjson.Unmarshal(body, &alert))
+		events = append(events, alert)
+	}))
+	defer webhook.Close()
+
+	rules, err := parseAlertRules(strings.NewReader("gauge HeapAlloc > 500MB for 2m\ncounter PollCount rate < 1/min"))
+	assert.NoError(t, err)
+	m := newAlertManager("", rules, webhook.URL, slog.New())
+
+	ctx := context.Background()
+	start := time.Unix(1000, 0)
+	_ = gauge.Process(ctx, `HeapAlloc{host="web1"}`, "600000000")
+	_ = gauge.Process(ctx, `HeapAlloc{host="web2"}`, "100")
+	_ = counter.Process(ctx, "PollCount", "10")
+
+	m.evaluate(ctx, stg, start)
+	alerts := m.list()
+	if assert.Len(t, alerts, 1) {
+		assert.Equal(t, alertPending, alerts[0].State)
+		assert.Equal(t, map[string]string{"host": "web1"}, alerts[0].Labels)
+	}
+	assert.Empty(t, events)
+
+	_ = counter.Process(ctx, "PollCount", "1")
+	m.evaluate(ctx, stg, start.Add(2*time.Minute))
+	alerts = m.list()
+	if assert.Len(t, alerts, 2) {
+		assert.Equal(t, alertFiring, alerts[0].State)
+		assert.Equal(t, "PollCount", alerts[1].ID)
+		assert.Equal(t, alertFiring, alerts[1].State)
+		assert.InDelta(t, 1.0/120, alerts[1].Value, 1e-12)
+	}
+	assert.Len(t, events, 2)
+
+	_ = gauge.Process(ctx, `HeapAlloc{host="web1"}`, "100")
+	_ = counter.Process(ctx, "PollCount", "100")
+	m.evaluate(ctx, stg, start.Add(3*time.Minute))
+	alerts = m.list()
+	if assert.Len(t, alerts, 2) {
+		assert.Equal(t, alertResolved, alerts[0].State)
+		assert.NotNil(t, alerts[0].ResolvedAt)
+		assert.Equal(t, alertResolved, alerts[1].State)
+	}
+	if assert.Len(t, events, 4) {
+		assert.Equal(t, alertResolved, events[2].State)
+	}
+
+	_ = counter.Process(ctx, "PollCount", "100")
+	m.evaluate(ctx, stg, start.Add(time.Hour))
+	assert.Empty(t, m.list())
+
+	_ = gauge.Process(ctx, `HeapAlloc{host="web2"}`, "700000000")
+	_ = counter.Process(ctx, "PollCount", "100")
+	m.evaluate(ctx, stg, start.Add(2*time.Hour))
+	_ = gauge.Process(ctx, `HeapAlloc{host="web2"}`, "1")
+	_ = counter.Process(ctx, "PollCount", "100")
+	m.evaluate(ctx, stg, start.Add(2*time.Hour+time.Minute))
+	assert.Empty(t, m.list())
+	assert.Len(t, events, 4)
+}
+
+func TestAlertManagerWebhookError(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer webhook.Close()
+
+	m := newAlertManager("", nil, webhook.URL, slog.New())
+	assert.Error(t, m.notify(context.Background(), dto.Alert{Rule: "gauge HeapAlloc > 1"}))
+
+	m = newAlertManager("", nil, "", slog.New())
+	assert.NoError(t, m.notify(context.Background(), dto.Alert{Rule: "gauge HeapAlloc > 1"}))
+}
+
+func TestAlertsHandler(t *testing.T) {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(nil))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/alerts", s.alertsHandler)
+	testHandler(t, r, http.MethodGet, "/alerts", http.StatusOK, "[]", nil, nil)
+
+	rules, _ := parseAlertRules(strings.NewReader("gauge Alloc > 1"))
+	m := newAlertManager("", rules, "", slog.New())
+	s.alerts = map[string]*alertManager{defaultTenant: m}
+	_ = stg.GetList()["gauge"].Process(context.Background(), "Alloc", "2")
+	m.evaluate(context.Background(), stg, time.Unix(1000, 0).UTC())
+
+	testHandler(t, r, http.MethodGet, "/alerts", http.StatusOK, `[{"rule":"gauge Alloc \u003e 1","state":"firing","type":"gauge","id":"Alloc","value":2,"active_at":"1970-01-01T00:16:40Z","fired_at":"1970-01-01T00:16:40Z"}]`, nil, nil)
+}
+
+func TestUpAlerts(t *testing.T) {
+	conf := getMockConf(t)
+	conf.EXPECT().GetAlertRulesFile().Return("alerts.rules").AnyTimes()
+	conf.EXPECT().GetAlertWebhook().Return("").AnyTimes()
+	conf.EXPECT().GetAlertInterval().Return(1).AnyTimes()
+	s := &Server{
+		storage: storages.NewMemStorage(),
+		conf:    conf,
+		logger:  slog.New(),
+	}
+
+	assert.Error(t, s.upAlerts(context.Background()))
+
+	_ = os.WriteFile("alerts.rules", []byte("gauge Alloc > 1\n"), 0644)
+	defer os.Remove("alerts.rules")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, s.upAlerts(ctx))
+	assert.Contains(t, s.alerts, defaultTenant)
+}
+
+func TestTenantAlerts(t *testing.T) {
+	_ = os.WriteFile("tenant-alerts.rules", []byte("gauge Alloc > 1\n"), 0644)
+	defer os.Remove("tenant-alerts.rules")
+	s := newTenantTestServer(t)
+	conf := s.conf.(*mocks.MockConfig)
+	conf.EXPECT().GetAlertRulesFile().Return("tenant-alerts.rules").AnyTimes()
+	conf.EXPECT().GetAlertWebhook().Return("").AnyTimes()
+	conf.EXPECT().GetAlertInterval().Return(3600).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, s.upAlerts(ctx))
+	assert.Len(t, s.alerts, 3)
+
+	_ = s.storage.GetList()["gauge"].Process(ctx, `Alloc{host="web1"}`, "2")
+	_ = s.tenants["team-b"].storage.GetList()["gauge"].Process(ctx, "Alloc", "3")
+	for _, tn := range s.tenantList() {
+		s.alerts[tn.name].evaluate(ctx, tn.storage, time.Unix(1000, 0).UTC())
+	}
+
+	r := chi.NewRouter()
+	r.Use(s.tenantMiddleware)
+	r.Get("/alerts", s.alertsHandler)
+	testHandler(t, r, http.MethodGet, "/alerts", http.StatusOK, `[{"rule":"gauge Alloc \u003e 1","state":"firing","type":"gauge","id":"Alloc","labels":{"host":"web1"},"value":2,"active_at":"1970-01-01T00:16:40Z","fired_at":"1970-01-01T00:16:40Z"}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/alerts", http.StatusOK, "[]", nil, map[string]string{"Authorization": "Bearer token-a"})
+
+	alerts := s.alerts["team-b"].list()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "team-b", alerts[0].Tenant)
+	assert.Equal(t, float64(3), alerts[0].Value)
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConcurrencyTestServer() (*Server, chi.Router) {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(nil))
+	stg.AddMetric("counter", metrics.NewCounter(nil))
+	stg.AddMetric("histogram", metrics.NewHistogram(nil, nil))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+		history: storages.NewMemHistory(16),
+	}
+
+	r := chi.NewRouter()
+	r.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
+	r.With(s.JSONContentTypeMiddleware).Post("/updates/", s.writeMassPostMetricHandler)
+	r.Get("/values/", s.showValuesHandler)
+	r.Get("/metrics", s.prometheusMetricsHandler)
+	r.Delete("/values/", s.deleteValuesHandler)
+
+	return s, r
+}
+
+func serve(r http.Handler, method string, path string, body []byte) int {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	_, r := newConcurrencyTestServer()
+	delta := int64(1)
+	batch, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "PollCount", MType: "counter", Delta: &delta},
+		{ID: "PollCount", MType: "counter", Delta: &delta, Labels: map[string]string{"host": "web1"}},
+	})
+
+	const workers, requests = 16, 200
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				serve(r, http.MethodPost, "/update/counter/PollCount/1", nil)
+				serve(r, http.MethodPost, "/updates/", batch)
+				serve(r, http.MethodPost, fmt.Sprintf("/update/gauge/Random%d/%d", i, j), nil)
+				serve(r, http.MethodPost, fmt.Sprintf("/update/histogram/latency/%d", j), nil)
+				serve(r, http.MethodGet, "/values/", nil)
+				serve(r, http.MethodGet, "/metrics", nil)
+				serve(r, http.MethodDelete, fmt.Sprintf("/values/?prefix=Random%d", (i+1)%workers), nil)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	testHandler(t, r, http.MethodGet, "/values/?type=counter", http.StatusOK, fmt.Sprintf(
+		`[{"id":"PollCount","type":"counter","delta":%d},{"id":"PollCount","type":"counter","delta":%d,"labels":{"host":"web1"}}]`,
+		2*workers*requests, workers*requests,
+	), nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/values/?type=histogram", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"count":%d`, workers*requests))
+}
+
+func BenchmarkUpdateHandlerParallel(b *testing.B) {
+	_, r := newConcurrencyTestServer()
+	paths := make([]string, 64)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("/update/counter/Metric%d/1", i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if code := serve(r, http.MethodPost, paths[i%len(paths)], nil); code != http.StatusOK {
+				b.Fatalf("unexpected status %d", code)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkUpdateHandlerParallelSameSeries(b *testing.B) {
+	_, r := newConcurrencyTestServer()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			serve(r, http.MethodPost, "/update/counter/PollCount/1", nil)
+		}
+	})
+}
+
+func TestConcurrentUpdatesCount(t *testing.T) {
+	s, r := newConcurrencyTestServer()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.Equal(t, http.StatusOK, serve(r, http.MethodPost, "/update/counter/PollCount/2", nil))
+			}
+		}()
+	}
+	wg.Wait()
+
+	counter, _ := s.storage.GetMetricType("counter")
+	list, _ := counter.GetList(httptest.NewRequest(http.MethodGet, "/", nil).Context())
+	assert.Equal(t, float64(1600), list["PollCount"])
+}
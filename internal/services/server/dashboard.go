@@ -0,0 +1,121 @@
+package server
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+)
+
+// defaultDashboardRefresh is the auto refresh interval of the dashboard in seconds.
+const defaultDashboardRefresh = 10
+
+//go:embed dashboard
+var dashboardFS embed.FS
+
+var dashboardTemplate = template.Must(template.ParseFS(dashboardFS, "dashboard/index.html"))
+
+// dashboardData is the view of the dashboard, metrics grouped by type and sorted by series key.
+type dashboardData struct {
+	Query   string
+	Refresh int
+	Groups  []dashboardGroup
+}
+
+type dashboardGroup struct {
+	Type   string
+	Series []dashboardSeries
+}
+
+type dashboardSeries struct {
+	Key   string
+	Value string
+}
+
+// dashboardAssetsHandler serves the embedded dashboard assets under /assets/.
+func dashboardAssetsHandler() http.Handler {
+	assets, _ := fs.Sub(dashboardFS, "dashboard")
+	return http.StripPrefix("/assets/", http.FileServer(http.FS(assets)))
+}
+
+// showAllMetricHandler writes the dashboard of every metric.
+// Metrics are filtered by the q name substring and the page refreshes every refresh seconds, 0 disables refreshing.
+// Clients that accept text/plain but not text/html get the plain text listing instead.
+func (s *Server) showAllMetricHandler(rw http.ResponseWriter, req *http.Request) {
+	stgList := s.storageOf(req.Context()).GetList()
+
+	if len(stgList) == 0 {
+		s.notFoundHandler(rw, req)
+		return
+	}
+
+	query := req.URL.Query()
+	data := dashboardData{
+		Query:   query.Get("q"),
+		Refresh: defaultDashboardRefresh,
+	}
+
+	if v := query.Get("refresh"); v != "" {
+		refresh, err := strconv.Atoi(v)
+		if err != nil || refresh < 0 {
+			http.Error(rw, fmt.Sprintf("invalid refresh %s", v), http.StatusBadRequest)
+			return
+		}
+		data.Refresh = refresh
+	}
+
+	metricTypes := make([]string, 0, len(stgList))
+	for metricType := range stgList {
+		metricTypes = append(metricTypes, metricType)
+	}
+	sort.Strings(metricTypes)
+
+	filter := strings.ToLower(data.Query)
+	for _, metricType := range metricTypes {
+		list, err := stgList[metricType].GetList(req.Context())
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		group := dashboardGroup{Type: metricType}
+		for _, key := range selectSeries(list, "", nil) {
+			name, _ := dto.SplitSeriesKey(key)
+			if strings.Contains(strings.ToLower(name), filter) {
+				group.Series = append(group.Series, dashboardSeries{Key: key, Value: strconv.FormatFloat(list[key], 'f', -1, 64)})
+			}
+		}
+
+		if len(group.Series) > 0 {
+			data.Groups = append(data.Groups, group)
+		}
+	}
+
+	if prefersText(req) {
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, group := range data.Groups {
+			fmt.Fprintf(rw, "%s:\n", group.Type)
+			for _, series := range group.Series {
+				fmt.Fprintf(rw, "\t%s: %s\n", series.Key, series.Value)
+			}
+		}
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(rw, data); err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// prefersText reports whether the client accepts plain text but not HTML.
+func prefersText(req *http.Request) bool {
+	accept := req.Header.Get("Accept")
+	return strings.Contains(accept, "text/plain") && !strings.Contains(accept, "text/html")
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboard(t *testing.T) {
+	gauge := metrics.NewGauge(nil)
+	_ = gauge.ProcessMassive(context.Background(), map[string]float64{
+		"HeapAlloc":              2.5,
+		"Alloc":                  1,
+		`Alloc{host="<web1>"}`:   3,
+		"RandomValue":            0.5,
+		`heap.alloc{host="web"}`: 5,
+	})
+	counter := metrics.NewCounter(nil)
+	_ = counter.Process(context.Background(), "PollCount", "7")
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", gauge)
+	stg.AddMetric("counter", counter)
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/", s.showAllMetricHandler)
+	r.Handle("/assets/*", dashboardAssetsHandler())
+
+	testHandler(t, r, http.MethodGet, "/", http.StatusOK, "counter:\n\tPollCount: 7\ngauge:\n\tAlloc: 1\n\tAlloc{host=\"<web1>\"}: 3\n\tHeapAlloc: 2.5\n\tRandomValue: 0.5\n\theap.alloc{host=\"web\"}: 5\n", nil, map[string]string{"Accept": "text/plain"})
+	testHandler(t, r, http.MethodGet, "/?q=alloc", http.StatusOK, "gauge:\n\tAlloc: 1\n\tAlloc{host=\"<web1>\"}: 3\n\tHeapAlloc: 2.5\n\theap.alloc{host=\"web\"}: 5\n", nil, map[string]string{"Accept": "text/plain"})
+	testHandler(t, r, http.MethodGet, "/?refresh=soon", http.StatusBadRequest, "skip", nil, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/?q=Heap&refresh=5", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.8")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, body, `<meta http-equiv="refresh" content="5">`)
+	assert.Contains(t, body, `value="Heap"`)
+	assert.Contains(t, body, "<tr><td>HeapAlloc</td><td>2.5</td></tr>")
+	assert.NotContains(t, body, "PollCount")
+	assert.Less(t, strings.Index(body, "HeapAlloc"), strings.Index(body, "heap.alloc"))
+
+	req, _ = http.NewRequest(http.MethodGet, "/?q=<web1>&refresh=0", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.NotContains(t, rec.Body.String(), "http-equiv")
+	assert.Contains(t, rec.Body.String(), "No metrics match the filter.")
+	assert.NotContains(t, rec.Body.String(), "<web1>")
+
+	req, _ = http.NewRequest(http.MethodGet, "/assets/style.css", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/css")
+}
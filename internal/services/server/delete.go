@@ -0,0 +1,147 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+// deleteMetricHandler deletes the series with the name that match the label matchers.
+// Without matchers only the series without labels is deleted, the labelled series of the name are kept.
+func (s *Server) deleteMetricHandler(rw http.ResponseWriter, req *http.Request) {
+	metricType := chi.URLParam(req, "metricType")
+	metricName := chi.URLParam(req, "metricName")
+
+	entity, keys, ok := s.selectNamedSeries(rw, req, metricType, metricName, true)
+	if !ok {
+		return
+	}
+
+	if err := entity.Delete(req.Context(), keys); err != nil {
+		http.Error(rw, fmt.Sprintf("failed to delete metric: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	s.deleteHistory(req.Context(), metricType, keys)
+}
+
+// resetMetricHandler sets the series with the name that match the label matchers back to zero.
+// Only metric types that can be reset, such as counters, are accepted.
+func (s *Server) resetMetricHandler(rw http.ResponseWriter, req *http.Request) {
+	metricType := chi.URLParam(req, "metricType")
+	metricName := chi.URLParam(req, "metricName")
+
+	entity, keys, ok := s.selectNamedSeries(rw, req, metricType, metricName, false)
+	if !ok {
+		return
+	}
+
+	resettable, ok := entity.(storages.ResettableMetric)
+	if !ok {
+		http.Error(rw, fmt.Sprintf("metric type %s can't be reset", metricType), http.StatusBadRequest)
+		return
+	}
+
+	if err := resettable.Reset(req.Context(), keys); err != nil {
+		http.Error(rw, fmt.Sprintf("failed to reset metric: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	samples := make(map[string]float64, len(keys))
+	for _, key := range keys {
+		samples[key] = 0
+	}
+	s.recordHistory(req.Context(), metricType, samples)
+}
+
+// deleteValuesHandler deletes every series selected by the query filter of showValuesHandler and writes the deleted metrics.
+// The prefix parameter is required, so a request without it can't wipe out every metric.
+func (s *Server) deleteValuesHandler(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	query := req.URL.Query()
+
+	if query.Get("prefix") == "" {
+		rw.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(rw, "%v", `{"error":"prefix is required"}`)
+		return
+	}
+
+	filter, err := newMetricsFilter(dto.MetricsFilter{
+		Types:  splitQueryList(query["type"]),
+		Names:  splitQueryList(query["name"]),
+		Prefix: query.Get("prefix"),
+		Regex:  query.Get("regex"),
+		Labels: query.Get(labelsQueryParam),
+	})
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"%s"}`, err.Error()))
+		return
+	}
+
+	collection, err := s.listValues(req.Context(), filter)
+	if err != nil {
+		rw.WriteHeader(updateErrorStatus(err))
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"%s"}`, err.Error()))
+		return
+	}
+
+	keys := make(map[string][]string)
+	for _, metricDTO := range collection {
+		keys[metricDTO.MType] = append(keys[metricDTO.MType], dto.SeriesKey(metricDTO.ID, metricDTO.Labels))
+	}
+
+	for metricType, typeKeys := range keys {
+		entity, _ := s.storageOf(req.Context()).GetMetricType(metricType)
+
+		if err := entity.Delete(req.Context(), typeKeys); err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"failed to delete metrics: %s"}`, err.Error()))
+			return
+		}
+		s.deleteHistory(req.Context(), metricType, typeKeys)
+	}
+
+	json, _ := easyjson.Marshal(collection)
+	fmt.Fprintf(rw, "%v", string(json))
+}
+
+// selectNamedSeries returns the entity and the keys of the series with the name that match the label matchers of the request.
+// With exact set and no matchers only the series without labels is selected.
+// The error response is written when the metric type or the series are not found or the matchers are invalid.
+func (s *Server) selectNamedSeries(rw http.ResponseWriter, req *http.Request, metricType string, metricName string, exact bool) (storages.EntityMetric, []string, bool) {
+	entity, err := s.storageOf(req.Context()).GetMetricType(metricType)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("metric type %s not found", metricType), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	matchers, err := requestLabelMatchers(req)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	list, err := entity.GetList(req.Context())
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	keys := selectSeries(list, metricName, matchers)
+	if exact && len(matchers) == 0 {
+		keys = nil
+		if _, ok := list[metricName]; ok {
+			keys = []string{metricName}
+		}
+	}
+	if len(keys) == 0 {
+		http.Error(rw, fmt.Sprintf("metric %s not found", metricName), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	return entity, keys, true
+}
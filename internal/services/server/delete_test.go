@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/repositories"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDeleteTestRouter(s *Server) *chi.Mux {
+	r := chi.NewRouter()
+	r.Get("/values/", s.showValuesHandler)
+	r.Delete("/value/{metricType}/{metricName}", s.deleteMetricHandler)
+	r.Post("/reset/{metricType}/{metricName}", s.resetMetricHandler)
+	r.Delete("/values/", s.deleteValuesHandler)
+
+	return r
+}
+
+func TestDeleteHandlers(t *testing.T) {
+	s := newValuesTestServer()
+	r := newDeleteTestRouter(s)
+
+	testHandler(t, r, http.MethodDelete, "/value/gauge/Alloc?labels=host=web1", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?prefix=Alloc", http.StatusOK, `[{"id":"Alloc","type":"gauge","value":1}]`, nil, nil)
+	testHandler(t, r, http.MethodDelete, "/value/gauge/Alloc?labels=host=web1", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodDelete, "/value/gauge/Alloc?labels=host", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodDelete, "/value/unknown/Alloc", http.StatusNotFound, "skip", nil, nil)
+
+	testHandler(t, r, http.MethodPost, "/reset/counter/PollCount", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?type=counter", http.StatusOK, `[{"id":"PollCount","type":"counter","delta":0}]`, nil, nil)
+	testHandler(t, r, http.MethodPost, "/reset/gauge/HeapAlloc", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodPost, "/reset/counter/Missing", http.StatusNotFound, "skip", nil, nil)
+
+	testHandler(t, r, http.MethodDelete, "/values/", http.StatusBadRequest, `{"error":"prefix is required"}`, nil, nil)
+	testHandler(t, r, http.MethodDelete, "/values/?prefix=A&regex=(", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodDelete, "/values/?prefix=R&type=unknown", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodDelete, "/values/?prefix=R", http.StatusOK, `[{"id":"RandomValue","type":"gauge","value":4}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/", http.StatusOK, `[{"id":"PollCount","type":"counter","delta":0},{"id":"Alloc","type":"gauge","value":1},{"id":"HeapAlloc","type":"gauge","value":3}]`, nil, nil)
+}
+
+func TestDeleteMetricWithoutMatchers(t *testing.T) {
+	s := newValuesTestServer()
+	r := newDeleteTestRouter(s)
+
+	testHandler(t, r, http.MethodDelete, "/value/gauge/Alloc", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?prefix=Alloc", http.StatusOK, `[{"id":"Alloc","type":"gauge","value":2,"labels":{"host":"web1"}}]`, nil, nil)
+	testHandler(t, r, http.MethodDelete, "/value/gauge/Alloc", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodDelete, "/value/gauge/Alloc?labels=host=~.*", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?prefix=Alloc", http.StatusOK, "[]", nil, nil)
+}
+
+func TestDeleteHistory(t *testing.T) {
+	ctx := context.Background()
+	s := newValuesTestServer()
+	history := storages.NewMemHistory(10)
+	s.history = history
+	r := newDeleteTestRouter(s)
+	_ = history.Record(ctx, "gauge", map[string]float64{"Alloc": 1, `Alloc{host="web1"}`: 2, "RandomValue": 4}, time.Now())
+
+	testHandler(t, r, http.MethodDelete, "/value/gauge/Alloc?labels=host=web1", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodDelete, "/values/?prefix=R", http.StatusOK, "skip", nil, nil)
+
+	for key, count := range map[string]int{"Alloc": 1, `Alloc{host="web1"}`: 0, "RandomValue": 0} {
+		samples, _ := history.Range(ctx, "gauge", key, time.Unix(0, 0), time.Now())
+		assert.Len(t, samples, count, key)
+	}
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	pathName := "tmp/deleted.json"
+	projectDir, _ := os.Getwd()
+	defer os.RemoveAll(filepath.Join(projectDir, filepath.Dir(pathName)))
+
+	s := newFileStorageServer(t, pathName, 0)
+	gauge, _ := s.storage.GetMetricType("gauge")
+	_ = gauge.ProcessMassive(context.Background(), map[string]float64{"Alloc": 1, "HeapAlloc": 3})
+	counter, _ := s.storage.GetMetricType("counter")
+	_ = counter.Process(context.Background(), "PollCount", "5")
+	r := newDeleteTestRouter(s)
+
+	testHandler(t, r, http.MethodDelete, "/value/gauge/HeapAlloc", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodPost, "/reset/counter/PollCount", http.StatusOK, "", nil, nil)
+
+	s.Close()
+	restored := newFileStorageServer(t, pathName, 0)
+	assert.NoError(t, restored.snapshot.Load())
+
+	gaugeList, _ := restored.storage.GetList()["gauge"].GetList(context.Background())
+	assert.NotContains(t, gaugeList, "HeapAlloc")
+	assert.Contains(t, gaugeList, "Alloc")
+	counterList, _ := restored.storage.GetList()["counter"].GetList(context.Background())
+	assert.Equal(t, map[string]float64{"PollCount": 0}, counterList)
+}
+
+func TestDeleteHandlersDB(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("counter", metrics.NewCounter(repositories.NewCounterRepo(clients.NewPostgres(mock))))
+	s := &Server{storage: stg, logger: slog.New()}
+	r := newDeleteTestRouter(s)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("PollCount", "", 5))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("PollCount", "", 5))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO counter (tenant, name, labels, value) VALUES ($1, $2, $3, $4) ON CONFLICT (tenant, name, labels) DO UPDATE SET value = EXCLUDED.value")).
+		WithArgs("", "PollCount", "", 0).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	testHandler(t, r, http.MethodPost, "/reset/counter/PollCount", http.StatusOK, "", nil, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("PollCount", "", 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, updated_at FROM counter")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "updated_at"}).AddRow("PollCount", "", time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM counter WHERE tenant = $1 AND (name, labels) IN (($2, $3))")).
+		WithArgs("", "PollCount", "").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	testHandler(t, r, http.MethodDelete, "/values/?prefix=Poll", http.StatusOK, `[{"id":"PollCount","type":"counter","delta":0}]`, nil, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("PollCount", "", 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM counter WHERE tenant = $1 AND (name, labels) IN (($2, $3))")).
+		WithArgs("", "PollCount", "").
+		WillReturnError(context.DeadlineExceeded)
+	testHandler(t, r, http.MethodDelete, "/value/counter/PollCount", http.StatusInternalServerError, "skip", nil, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM counter")).
+		WithArgs("").
+		WillReturnError(context.DeadlineExceeded)
+	testHandler(t, r, http.MethodDelete, "/value/counter/PollCount", http.StatusInternalServerError, "skip", nil, nil)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,560 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/mailru/easyjson"
+)
+
+// Formats of a dump of the metrics.
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// Modes of an import of a dump.
+const (
+	ImportMerge   = "merge"
+	ImportReplace = "replace"
+	ImportDeltas  = "deltas"
+)
+
+// dumpContentTypes are the content types of the dump formats.
+var dumpContentTypes = map[string]string{
+	FormatJSON:   "application/json",
+	FormatNDJSON: "application/x-ndjson",
+	FormatCSV:    "text/csv",
+}
+
+// csvHeader is the first row of a CSV dump.
+var csvHeader = []string{"type", "id", "labels", "value", "updated_at"}
+
+// errDump is wrapped by the errors of a malformed dump.
+var errDump = errors.New("invalid dump")
+
+// Export writes every metric of the storage to w in the format.
+func (s *Server) Export(ctx context.Context, w io.Writer, format string) error {
+	if _, ok := dumpContentTypes[format]; !ok {
+		return fmt.Errorf("unknown dump format %s", format)
+	}
+
+	list, err := s.dumpMetrics(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeDump(w, format, list)
+}
+
+// Import stores the metrics of a dump and returns the number of imported metrics.
+func (s *Server) Import(ctx context.Context, r io.Reader, format string, mode string) (int, error) {
+	list, err := readDump(r, format)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(list), s.importMetrics(ctx, list, mode)
+}
+
+// exportHandler writes the dump of every metric.
+func (s *Server) exportHandler(rw http.ResponseWriter, req *http.Request) {
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = FormatJSON
+	}
+
+	contentType, ok := dumpContentTypes[format]
+	if !ok {
+		http.Error(rw, fmt.Sprintf("unknown dump format %s", format), http.StatusBadRequest)
+		return
+	}
+
+	list, err := s.dumpMetrics(req.Context())
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("failed to export metrics: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="metrics.%s"`, format))
+	if err := writeDump(rw, format, list); err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// importHandler stores the metrics of the dump in the request body.
+func (s *Server) importHandler(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = FormatJSON
+	}
+	mode := query.Get("mode")
+	if mode == "" {
+		mode = ImportMerge
+	}
+
+	list, err := readDump(req.Body, format)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.importMetrics(req.Context(), list, mode); err != nil {
+		var quotaErr *seriesQuotaError
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errDump):
+			status = http.StatusBadRequest
+		case errors.As(err, &quotaErr):
+			status = http.StatusForbidden
+		}
+		http.Error(rw, fmt.Sprintf("failed to import metrics: %s", err.Error()), status)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(rw, `{"imported":%d}`, len(list))
+}
+
+// dumpMetrics returns every metric of the storage sorted by type and series key.
+func (s *Server) dumpMetrics(ctx context.Context) (dto.MetricsCollection, error) {
+	stgList := s.storageOf(ctx).GetList()
+	types := make([]string, 0, len(stgList))
+	for metricType := range stgList {
+		types = append(types, metricType)
+	}
+	sort.Strings(types)
+
+	list := dto.MetricsCollection{}
+	for _, metricType := range types {
+		metrics, err := listMetricDtos(ctx, metricType, stgList[metricType])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", metricType, err)
+		}
+
+		keys := make([]string, 0, len(metrics))
+		for key := range metrics {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			list = append(list, metrics[key])
+		}
+	}
+
+	return list, nil
+}
+
+// dumpBatch holds the dumped series of a single metric type keyed by series key.
+type dumpBatch struct {
+	values     map[string]float64
+	histograms map[string]dto.Histogram
+	summaries  map[string]dto.Summary
+}
+
+// importMetrics checks every metric and stores them in the mode.
+func (s *Server) importMetrics(ctx context.Context, list dto.MetricsCollection, mode string) error {
+	switch mode {
+	case ImportMerge, ImportReplace, ImportDeltas:
+	default:
+		return fmt.Errorf("%w: unknown import mode %s", errDump, mode)
+	}
+
+	batches, err := s.dumpBatches(ctx, list)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string][]string, len(batches))
+	for metricType, batch := range batches {
+		keys[metricType] = append(keys[metricType], dto.SeriesKeys(batch.values)...)
+		keys[metricType] = append(keys[metricType], dto.SeriesKeys(batch.histograms)...)
+		keys[metricType] = append(keys[metricType], dto.SeriesKeys(batch.summaries)...)
+	}
+
+	storage := s.storageOf(ctx)
+	samples := make(map[string]map[string]float64, len(batches))
+	err = s.withQuota(ctx, keys, mode == ImportReplace, func() error {
+		if mode == ImportReplace {
+			for metricType, entity := range storage.GetList() {
+				if _, ok := batches[metricType]; !ok {
+					batches[metricType] = &dumpBatch{}
+				}
+				if err := deleteMissing(ctx, entity, batches[metricType]); err != nil {
+					return fmt.Errorf("%s: %w", metricType, err)
+				}
+			}
+		}
+
+		for metricType, batch := range batches {
+			if len(keys[metricType]) == 0 {
+				continue
+			}
+
+			entity, _ := storage.GetMetricType(metricType)
+			var err error
+			if samples[metricType], err = importBatch(ctx, metricType, entity, batch, mode); err != nil {
+				return fmt.Errorf("%s: %w", metricType, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for metricType, items := range samples {
+		s.recordHistory(ctx, metricType, items)
+	}
+
+	return nil
+}
+
+// dumpBatches groups the metrics by type and checks them before anything is stored.
+func (s *Server) dumpBatches(ctx context.Context, list dto.MetricsCollection) (map[string]*dumpBatch, error) {
+	batches := make(map[string]*dumpBatch)
+	for i, metric := range list {
+		entity, err := s.storageOf(ctx).GetMetricType(metric.MType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: metric %d: unknown type %s", errDump, i+1, metric.MType)
+		}
+		if metric.ID == "" {
+			return nil, fmt.Errorf("%w: metric %d: missing id", errDump, i+1)
+		}
+		if err := dto.ValidateMetricName(metric.ID); err != nil {
+			return nil, fmt.Errorf("%w: metric %d: %s", errDump, i+1, err)
+		}
+		if err := dto.ValidateLabels(metric.Labels); err != nil {
+			return nil, fmt.Errorf("%w: metric %d: %s", errDump, i+1, err)
+		}
+
+		batch, ok := batches[metric.MType]
+		if !ok {
+			batch = &dumpBatch{
+				values:     make(map[string]float64),
+				histograms: make(map[string]dto.Histogram),
+				summaries:  make(map[string]dto.Summary),
+			}
+			batches[metric.MType] = batch
+		}
+
+		key := dto.SeriesKey(metric.ID, metric.Labels)
+		switch entity := entity.(type) {
+		case storages.HistogramMetric:
+			if metric.Histogram == nil {
+				return nil, fmt.Errorf("%w: metric %d: missing histogram of %s", errDump, i+1, metric.ID)
+			}
+			histogram := dto.NewHistogram(entity.Bounds())
+			if err := histogram.Merge(*metric.Histogram); err != nil {
+				return nil, fmt.Errorf("%w: metric %d: %s: %s", errDump, i+1, metric.ID, err)
+			}
+			batch.histograms[key] = histogram
+		case storages.SummaryMetric:
+			if metric.Summary == nil {
+				return nil, fmt.Errorf("%w: metric %d: missing summary of %s", errDump, i+1, metric.ID)
+			}
+			summary := dto.NewSummary(entity.RelativeAccuracy())
+			if err := summary.Merge(*metric.Summary); err != nil {
+				return nil, fmt.Errorf("%w: metric %d: %s: %s", errDump, i+1, metric.ID, err)
+			}
+			batch.summaries[key] = summary
+		default:
+			switch {
+			case metric.Value != nil:
+				batch.values[key] = *metric.Value
+			case metric.Delta != nil:
+				batch.values[key] = float64(*metric.Delta)
+			default:
+				return nil, fmt.Errorf("%w: metric %d: missing value of %s", errDump, i+1, metric.ID)
+			}
+		}
+	}
+
+	return batches, nil
+}
+
+// deleteMissing deletes the series of the entity that aren't in the batch.
+func deleteMissing(ctx context.Context, entity storages.EntityMetric, batch *dumpBatch) error {
+	stored, err := entity.GetList(ctx)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	for key := range stored {
+		_, isValue := batch.values[key]
+		_, isHistogram := batch.histograms[key]
+		_, isSummary := batch.summaries[key]
+		if !isValue && !isHistogram && !isSummary {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return entity.Delete(ctx, keys)
+}
+
+// importBatch stores the series of the batch and returns their stored values.
+func importBatch(ctx context.Context, metricType string, entity storages.EntityMetric, batch *dumpBatch, mode string) (map[string]float64, error) {
+	if histogram, ok := entity.(storages.HistogramMetric); ok {
+		if mode != ImportDeltas {
+			if err := histogram.Delete(ctx, dto.SeriesKeys(batch.histograms)); err != nil {
+				return nil, err
+			}
+		}
+
+		return histogram.ProcessHistograms(ctx, batch.histograms)
+	}
+
+	if summary, ok := entity.(storages.SummaryMetric); ok {
+		if mode != ImportDeltas {
+			if err := summary.Delete(ctx, dto.SeriesKeys(batch.summaries)); err != nil {
+				return nil, err
+			}
+		}
+
+		return summary.ProcessSummaries(ctx, batch.summaries)
+	}
+
+	if metricType == "gauge" || mode == ImportDeltas {
+		return processSamples(ctx, entity, batch.values)
+	}
+
+	stored, err := entity.GetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make(map[string]float64, len(batch.values))
+	for key, value := range batch.values {
+		current, ok := stored[key]
+		if delta := value - current; delta != 0 || !ok {
+			deltas[key] = delta
+		}
+	}
+
+	totals, err := processSamples(ctx, entity, deltas)
+	if _, ok := entity.(storages.SampledMetric); err != nil || !ok {
+		return nil, err
+	}
+
+	// The series without a difference already hold the dumped value.
+	samples := make(map[string]float64, len(batch.values))
+	for key, value := range batch.values {
+		samples[key] = value
+	}
+	for key, total := range totals {
+		samples[key] = total
+	}
+
+	return samples, nil
+}
+
+// writeDump writes the metrics to w in the format.
+func writeDump(w io.Writer, format string, list dto.MetricsCollection) error {
+	switch format {
+	case FormatJSON:
+		_, err := easyjson.MarshalToWriter(list, w)
+		return err
+	case FormatNDJSON:
+		buf := bufio.NewWriter(w)
+		for _, metric := range list {
+			if _, err := easyjson.MarshalToWriter(metric, buf); err != nil {
+				return err
+			}
+			if err := buf.WriteByte('\n'); err != nil {
+				return err
+			}
+		}
+
+		return buf.Flush()
+	case FormatCSV:
+		return writeCSVDump(w, list)
+	default:
+		return fmt.Errorf("unknown dump format %s", format)
+	}
+}
+
+func writeCSVDump(w io.Writer, list dto.MetricsCollection) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, metric := range list {
+		var value string
+		switch {
+		case metric.Histogram != nil:
+			raw, err := easyjson.Marshal(metric.Histogram)
+			if err != nil {
+				return err
+			}
+			value = string(raw)
+		case metric.Summary != nil:
+			summary := *metric.Summary
+			summary.Quantiles = nil
+			raw, err := easyjson.Marshal(summary)
+			if err != nil {
+				return err
+			}
+			value = string(raw)
+		case metric.Value != nil:
+			value = strconv.FormatFloat(*metric.Value, 'g', -1, 64)
+		case metric.Delta != nil:
+			value = strconv.FormatInt(*metric.Delta, 10)
+		}
+
+		var updatedAt string
+		if metric.UpdatedAt != nil {
+			updatedAt = metric.UpdatedAt.Format(time.RFC3339Nano)
+		}
+
+		if err := writer.Write([]string{metric.MType, metric.ID, dto.FormatLabels(metric.Labels), value, updatedAt}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// readDump reads the metrics of a dump in the format from r.
+func readDump(r io.Reader, format string) (dto.MetricsCollection, error) {
+	switch format {
+	case FormatJSON:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		var list dto.MetricsCollection
+		if err := easyjson.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("%w: %s", errDump, err)
+		}
+
+		return list, nil
+	case FormatNDJSON:
+		return readNDJSONDump(r)
+	case FormatCSV:
+		return readCSVDump(r)
+	default:
+		return nil, fmt.Errorf("%w: unknown dump format %s", errDump, format)
+	}
+}
+
+func readNDJSONDump(r io.Reader) (dto.MetricsCollection, error) {
+	list := dto.MetricsCollection{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var metric dto.Metrics
+		if err := easyjson.Unmarshal(data, &metric); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", errDump, line, err)
+		}
+		list = append(list, metric)
+	}
+
+	return list, scanner.Err()
+}
+
+func readCSVDump(r io.Reader) (dto.MetricsCollection, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return dto.MetricsCollection{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errDump, err)
+	}
+	for i, column := range csvHeader {
+		if header[i] != column {
+			return nil, fmt.Errorf("%w: unexpected column %s, expected %s", errDump, header[i], column)
+		}
+	}
+
+	list := dto.MetricsCollection{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return list, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errDump, err)
+		}
+
+		metric, err := parseCSVMetric(record)
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("%w: line %d: %s", errDump, line, err)
+		}
+		list = append(list, metric)
+	}
+}
+
+// parseCSVMetric parses a row of a CSV dump.
+func parseCSVMetric(record []string) (dto.Metrics, error) {
+	metric := dto.Metrics{MType: record[0], ID: record[1]}
+	if record[2] != "" {
+		labels, err := dto.ParseLabels(record[2])
+		if err != nil {
+			return metric, err
+		}
+		metric.Labels = labels
+	}
+
+	value := record[3]
+	if len(value) > 0 && value[0] == '{' {
+		var histogram dto.Histogram
+		if err := easyjson.Unmarshal([]byte(value), &histogram); err == nil && histogram.Bounds != nil {
+			metric.Histogram = &histogram
+			return metric, nil
+		}
+
+		var summary dto.Summary
+		if err := easyjson.Unmarshal([]byte(value), &summary); err != nil {
+			return metric, fmt.Errorf("invalid value %s", value)
+		}
+		metric.Summary = &summary
+
+		return metric, nil
+	}
+
+	if delta, err := strconv.ParseInt(value, 10, 64); err == nil && metric.MType != "gauge" {
+		metric.Delta = &delta
+		return metric, nil
+	}
+
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return metric, fmt.Errorf("invalid value %s", value)
+	}
+	metric.Value = &floatValue
+
+	return metric, nil
+}
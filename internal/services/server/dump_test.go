@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDumpTestServer returns a server with an empty storage of every metric type.
+func newDumpTestServer() *Server {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(nil))
+	stg.AddMetric("counter", metrics.NewCounter(nil))
+	stg.AddMetric("histogram", metrics.NewHistogram(nil, []float64{0.1, 1}))
+	stg.AddMetric("summary", metrics.NewSummary(nil))
+
+	return &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	source := newDumpTestServer()
+	stgList := source.storage.GetList()
+	_ = stgList["gauge"].ProcessMassive(ctx, map[string]float64{"Alloc": 1.5, `Alloc{host="web1"}`: 2})
+	_ = stgList["counter"].ProcessMassive(ctx, map[string]float64{"PollCount": 5})
+	_ = stgList["histogram"].ProcessMassive(ctx, map[string]float64{"latency": 0.5})
+	_ = stgList["summary"].ProcessMassive(ctx, map[string]float64{"latency": 0.5})
+
+	var want bytes.Buffer
+	assert.NoError(t, source.Export(ctx, &want, FormatJSON))
+
+	for _, format := range []string{FormatJSON, FormatNDJSON, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var dump bytes.Buffer
+			assert.NoError(t, source.Export(ctx, &dump, format))
+
+			target := newDumpTestServer()
+			imported, err := target.Import(ctx, &dump, format, ImportReplace)
+			assert.NoError(t, err)
+			assert.Equal(t, 5, imported)
+
+			var got bytes.Buffer
+			assert.NoError(t, target.Export(ctx, &got, FormatJSON))
+			assert.Equal(t, updatedAtField.ReplaceAllString(want.String(), ""), updatedAtField.ReplaceAllString(got.String(), ""))
+		})
+	}
+
+	var csvDump bytes.Buffer
+	assert.NoError(t, source.Export(ctx, &csvDump, FormatCSV))
+	assert.True(t, strings.HasPrefix(csvDump.String(), "type,id,labels,value,updated_at\ncounter,PollCount,,5,"))
+	assert.Contains(t, csvDump.String(), "\ngauge,Alloc,\"host=\"\"web1\"\"\",2,")
+
+	assert.Error(t, source.Export(ctx, &bytes.Buffer{}, "xml"))
+}
+
+func TestImportModes(t *testing.T) {
+	ctx := context.Background()
+	dump := `[{"id":"PollCount","type":"counter","delta":5},{"id":"Alloc","type":"gauge","value":1},{"id":"latency","type":"histogram","histogram":{"bounds":[0.1,1],"counts":[1,0,0],"sum":0.05,"count":1}}]`
+
+	newServer := func() *Server {
+		s := newDumpTestServer()
+		stgList := s.storage.GetList()
+		_ = stgList["counter"].ProcessMassive(ctx, map[string]float64{"PollCount": 3, "Requests": 1})
+		_ = stgList["gauge"].ProcessMassive(ctx, map[string]float64{"Alloc": 7})
+		_ = stgList["histogram"].ProcessMassive(ctx, map[string]float64{"latency": 0.5})
+		return s
+	}
+
+	testCases := []struct {
+		mode      string
+		counters  map[string]float64
+		gauges    map[string]float64
+		histogram float64
+	}{
+		{mode: ImportMerge, counters: map[string]float64{"PollCount": 5, "Requests": 1}, gauges: map[string]float64{"Alloc": 1}, histogram: 1},
+		{mode: ImportReplace, counters: map[string]float64{"PollCount": 5}, gauges: map[string]float64{"Alloc": 1}, histogram: 1},
+		{mode: ImportDeltas, counters: map[string]float64{"PollCount": 8, "Requests": 1}, gauges: map[string]float64{"Alloc": 1}, histogram: 2},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.mode, func(t *testing.T) {
+			s := newServer()
+			imported, err := s.Import(ctx, strings.NewReader(dump), FormatJSON, testCase.mode)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, imported)
+
+			stgList := s.storage.GetList()
+			counters, _ := stgList["counter"].GetList(ctx)
+			assert.Equal(t, testCase.counters, counters)
+			gauges, _ := stgList["gauge"].GetList(ctx)
+			assert.Equal(t, testCase.gauges, gauges)
+			histograms, _ := stgList["histogram"].GetList(ctx)
+			assert.Equal(t, map[string]float64{"latency": testCase.histogram}, histograms)
+		})
+	}
+
+	s := newServer()
+	for _, invalid := range []string{
+		`[{"id":"Alloc","type":"unknown","value":1}]`,
+		`[{"id":"Alloc","type":"gauge"}]`,
+		`[{"id":"latency","type":"histogram","delta":1}]`,
+		`[{"id":"latency","type":"summary","value":1}]`,
+		`[{"id":"Alloc","type":"gauge","value":1,"labels":{"1host":"web1"}}]`,
+		`[{"id":"","type":"gauge","value":1}]`,
+		`[{"id":"Alloc{host=\"web1\"}","type":"gauge","value":1}]`,
+		`[{"id":"Alloc","type":"gauge","value":1},{"id":"latency","type":"histogram","histogram":{"bounds":[0.5,1],"counts":[1,0,0],"sum":0.05,"count":1}}]`,
+		`[{"id":"Alloc","type":"gauge","value":1},{"id":"latency","type":"histogram","histogram":{"bounds":[0.1,1],"counts":[1,0],"sum":0.05,"count":1}}]`,
+		`[{"id":"Alloc","type":"gauge","value":1},{"id":"latency","type":"summary","summary":{"relative_accuracy":0.05,"zero":1,"count":1}}]`,
+		`{`,
+	} {
+		_, err := s.Import(ctx, strings.NewReader(invalid), FormatJSON, ImportReplace)
+		assert.Error(t, err, invalid)
+	}
+	_, err := s.Import(ctx, strings.NewReader(dump), FormatJSON, "overwrite")
+	assert.Error(t, err)
+	_, err = s.Import(ctx, strings.NewReader(dump), "xml", ImportMerge)
+	assert.Error(t, err)
+
+	counters, _ := s.storage.GetList()["counter"].GetList(ctx)
+	assert.Equal(t, map[string]float64{"PollCount": 3, "Requests": 1}, counters)
+	gauges, _ := s.storage.GetList()["gauge"].GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 7}, gauges)
+	histograms, _ := s.storage.GetList()["histogram"].GetList(ctx)
+	assert.Equal(t, map[string]float64{"latency": 1}, histograms)
+}
+
+func TestReadDump(t *testing.T) {
+	list, err := readDump(strings.NewReader("{\"id\":\"Alloc\",\"type\":\"gauge\",\"value\":1}\n\n{\"id\":\"PollCount\",\"type\":\"counter\",\"delta\":2}\n"), FormatNDJSON)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	_, err = readDump(strings.NewReader("{\"id\":\"Alloc\"}\n{\n"), FormatNDJSON)
+	assert.ErrorContains(t, err, "line 2")
+
+	list, err = readDump(strings.NewReader(""), FormatCSV)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	for _, invalid := range []string{
+		"name,id,labels,value,updated_at\n",
+		"type,id,labels,value,updated_at\ngauge,Alloc,,one,\n",
+		"type,id,labels,value,updated_at\ngauge,Alloc,host,1,\n",
+		"type,id,labels,value,updated_at\nsummary,latency,,{,\n",
+		"type,id,labels,value\n",
+	} {
+		_, err := readDump(strings.NewReader(invalid), FormatCSV)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestDumpHandlers(t *testing.T) {
+	s := newValuesTestServer()
+	r := chi.NewRouter()
+	r.Get("/admin/export", s.exportHandler)
+	r.Post("/admin/import", s.importHandler)
+
+	testHandler(t, r, http.MethodGet, "/admin/export", http.StatusOK, `[{"id":"PollCount","type":"counter","delta":5},{"id":"Alloc","type":"gauge","value":1},{"id":"Alloc","type":"gauge","value":2,"labels":{"host":"web1"}},{"id":"HeapAlloc","type":"gauge","value":3},{"id":"RandomValue","type":"gauge","value":4}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/admin/export?format=xml", http.StatusBadRequest, "skip", nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/export?format=ndjson", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="metrics.ndjson"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, 5, strings.Count(rec.Body.String(), "\n"))
+
+	testHandler(t, r, http.MethodPost, "/admin/import?format=csv&mode=deltas", http.StatusOK, `{"imported":1}`, []byte("type,id,labels,value,updated_at\ncounter,PollCount,,2,\n"), nil)
+	testHandler(t, r, http.MethodPost, "/admin/import", http.StatusOK, `{"imported":0}`, []byte(`[]`), nil)
+	testHandler(t, r, http.MethodPost, "/admin/import", http.StatusBadRequest, "skip", []byte(`{`), nil)
+	testHandler(t, r, http.MethodPost, "/admin/import?mode=overwrite", http.StatusBadRequest, "skip", []byte(`[]`), nil)
+	testHandler(t, r, http.MethodPost, "/admin/import", http.StatusBadRequest, "skip", []byte(`[{"id":"Alloc","type":"unknown","value":1}]`), nil)
+
+	counters, _ := s.storage.GetList()["counter"].GetList(context.Background())
+	assert.Equal(t, map[string]float64{"PollCount": 7}, counters)
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	conf := getMockConf(t)
+	conf.EXPECT().GetAdminToken().Return("").Times(2)
+	conf.EXPECT().GetAdminToken().Return("secret").AnyTimes()
+	s := newValuesTestServer()
+	s.conf = conf
+	r := chi.NewRouter()
+	r.With(s.adminMiddleware).Get("/admin/export", s.exportHandler)
+	r.With(s.adminMiddleware).Post("/admin/import", s.importHandler)
+
+	testHandler(t, r, http.MethodGet, "/admin/export", http.StatusForbidden, "skip", nil, map[string]string{"X-Admin-Token": ""})
+	testHandler(t, r, http.MethodPost, "/admin/import", http.StatusForbidden, "skip", []byte(`[]`), nil)
+
+	testHandler(t, r, http.MethodGet, "/admin/export", http.StatusUnauthorized, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/admin/export", http.StatusUnauthorized, "skip", nil, map[string]string{"X-Admin-Token": "wrong"})
+	testHandler(t, r, http.MethodPost, "/admin/import", http.StatusUnauthorized, "skip", []byte(`[{"id":"PollCount","type":"counter","delta":2}]`), map[string]string{"Authorization": "Bearer secret"})
+	testHandler(t, r, http.MethodGet, "/admin/export?format=csv", http.StatusOK, "skip", nil, map[string]string{"X-Admin-Token": "secret"})
+
+	counters, _ := s.storage.GetList()["counter"].GetList(context.Background())
+	assert.Equal(t, map[string]float64{"PollCount": 5}, counters)
+}
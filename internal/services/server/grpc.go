@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	pb "github.com/AnatolySnegovskiy/metric/internal/proto"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor used by the agent
+	"google.golang.org/grpc/status"
+)
+
+// metricsService implements the gRPC Metrics service on top of the server storage,
+// so metrics written over gRPC are visible over HTTP and vice versa.
+type metricsService struct {
+	pb.UnimplementedMetricsServer
+	server *Server
+}
+
+// newGRPCServer creates a gRPC server with the Metrics service registered, the calls are routed to the tenant of their token.
+func (s *Server) newGRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(s.tenantInterceptor))
+	pb.RegisterMetricsServer(grpcServer, &metricsService{server: s})
+
+	return grpcServer
+}
+
+// UpdateMetrics stores a batch of metrics with the same semantics as POST /updates/.
+func (m *metricsService) UpdateMetrics(ctx context.Context, req *pb.UpdateMetricsRequest) (*pb.UpdateMetricsResponse, error) {
+	collection := pb.ToCollection(req.GetMetrics())
+
+	if err := m.server.updateMetrics(ctx, collection); err != nil {
+		var typeErr *metricTypeNotFoundError
+		var valueErr *metricValueError
+		var quotaErr *seriesQuotaError
+
+		switch {
+		case errors.As(err, &typeErr):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.As(err, &valueErr):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.As(err, &quotaErr):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return &pb.UpdateMetricsResponse{Metrics: req.GetMetrics()}, nil
+}
+
+// GetMetric returns the current value of a single series.
+func (m *metricsService) GetMetric(ctx context.Context, req *pb.GetMetricRequest) (*pb.GetMetricResponse, error) {
+	entity, err := m.server.storageOf(ctx).GetMetricType(req.GetType())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "metric type %s not found", req.GetType())
+	}
+
+	metricDTO, ok, err := findMetricDto(ctx, req.GetType(), dto.SeriesKey(req.GetId(), req.GetLabels()), entity)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get list of metrics: %s", err.Error())
+	}
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "metric %s not found", req.GetId())
+	}
+
+	return &pb.GetMetricResponse{Metric: pb.FromDTO(metricDTO)}, nil
+}
+
+// ListMetrics returns all metrics of the requested type, or of every type when the type is empty,
+// that match the label matchers of the request.
+func (m *metricsService) ListMetrics(ctx context.Context, req *pb.ListMetricsRequest) (*pb.ListMetricsResponse, error) {
+	types := m.server.storageOf(ctx).GetList()
+
+	matchers, err := parseLabelMatchers(req.GetLabels())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.GetType() != "" {
+		entity, err := m.server.storageOf(ctx).GetMetricType(req.GetType())
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "metric type %s not found", req.GetType())
+		}
+		types = map[string]storages.EntityMetric{req.GetType(): entity}
+	}
+
+	collection := dto.MetricsCollection{}
+	for metricType, entity := range types {
+		if entity == nil {
+			continue
+		}
+
+		list, err := listMetricDtos(ctx, metricType, entity)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get list of metrics: %s", err.Error())
+		}
+
+		for _, key := range selectSeries(list, "", matchers) {
+			collection = append(collection, list[key])
+		}
+	}
+
+	sort.Slice(collection, func(i, j int) bool {
+		if collection[i].MType != collection[j].MType {
+			return collection[i].MType < collection[j].MType
+		}
+		if collection[i].ID != collection[j].ID {
+			return collection[i].ID < collection[j].ID
+		}
+		return dto.FormatLabels(collection[i].Labels) < dto.FormatLabels(collection[j].Labels)
+	})
+
+	return &pb.ListMetricsResponse{Metrics: pb.FromCollection(collection)}, nil
+}
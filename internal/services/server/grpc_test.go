@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	pb "github.com/AnatolySnegovskiy/metric/internal/proto"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newGRPCTestClient(t *testing.T, s *Server) pb.MetricsClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := s.newGRPCServer()
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewMetricsClient(conn)
+}
+
+func TestGRPCMetricsService(t *testing.T) {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(nil))
+	stg.AddMetric("counter", metrics.NewCounter(nil))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+	client := newGRPCTestClient(t, s)
+	ctx := context.Background()
+
+	r := chi.NewRouter()
+	r.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
+	r.Get("/value/{metricType}/{metricName}", s.showMetricNameHandlers)
+
+	delta := int64(5)
+	value := 1.5
+	_, err := client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "PollCount", Type: "counter", Delta: &delta},
+		{Id: "PollCount", Type: "counter", Delta: &delta},
+		{Id: "Alloc", Type: "gauge", Value: &value},
+	}})
+	assert.NoError(t, err)
+
+	testHandler(t, r, http.MethodGet, "/value/counter/PollCount", http.StatusOK, "10", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc", http.StatusOK, "1.5", nil, nil)
+
+	testHandler(t, r, http.MethodPost, "/update/gauge/HeapAlloc/2.5", http.StatusOK, "", nil, nil)
+	resp, err := client.GetMetric(ctx, &pb.GetMetricRequest{Id: "HeapAlloc", Type: "gauge"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, resp.GetMetric().GetValue())
+
+	resp, err = client.GetMetric(ctx, &pb.GetMetricRequest{Id: "PollCount", Type: "counter"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), resp.GetMetric().GetDelta())
+
+	list, err := client.ListMetrics(ctx, &pb.ListMetricsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetMetrics(), 3)
+	assert.Equal(t, "PollCount", list.GetMetrics()[0].GetId())
+
+	list, err = client.ListMetrics(ctx, &pb.ListMetricsRequest{Type: "gauge"})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetMetrics(), 2)
+
+	_, err = client.GetMetric(ctx, &pb.GetMetricRequest{Id: "unknown", Type: "gauge"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetMetric(ctx, &pb.GetMetricRequest{Id: "Alloc", Type: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.ListMetrics(ctx, &pb.ListMetricsRequest{Type: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "Alloc", Type: "unknown", Value: &value},
+	}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCMetricsServiceSummary(t *testing.T) {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("summary", metrics.NewSummary(nil))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+	client := newGRPCTestClient(t, s)
+	ctx := context.Background()
+
+	summary := dto.NewSummary(dto.DefaultRelativeAccuracy)
+	summary.Observe(-1)
+	summary.Observe(0)
+	summary.Observe(10)
+	_, err := client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: pb.FromCollection(dto.MetricsCollection{
+		{ID: "latency", MType: "summary", Summary: &summary},
+	})})
+	assert.NoError(t, err)
+
+	resp, err := client.GetMetric(ctx, &pb.GetMetricRequest{Id: "latency", Type: "summary"})
+	assert.NoError(t, err)
+
+	actual := pb.ToDTO(resp.GetMetric()).Summary
+	assert.Equal(t, summary.WithQuantiles(dto.DefaultQuantiles), *actual)
+
+	mismatched := dto.NewSummary(0.05)
+	_, err = client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: pb.FromCollection(dto.MetricsCollection{
+		{ID: "latency", MType: "summary", Summary: &mismatched},
+	})})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	nan := math.NaN()
+	_, err = client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: pb.FromCollection(dto.MetricsCollection{
+		{ID: "latency", MType: "summary", Value: &nan},
+	})})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCMetricsServiceLabels(t *testing.T) {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(nil))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+	client := newGRPCTestClient(t, s)
+	ctx := context.Background()
+
+	web1, web2 := 1.0, 2.0
+	_, err := client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "Alloc", Type: "gauge", Value: &web2, Labels: map[string]string{"host": "web2"}},
+		{Id: "Alloc", Type: "gauge", Value: &web1, Labels: map[string]string{"host": "web1"}},
+		{Id: "Alloc", Type: "gauge", Value: &web1},
+	}})
+	assert.NoError(t, err)
+
+	resp, err := client.GetMetric(ctx, &pb.GetMetricRequest{Id: "Alloc", Type: "gauge", Labels: map[string]string{"host": "web2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, resp.GetMetric().GetValue())
+	assert.Equal(t, map[string]string{"host": "web2"}, resp.GetMetric().GetLabels())
+
+	list, err := client.ListMetrics(ctx, &pb.ListMetricsRequest{Labels: "host=~web.*"})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetMetrics(), 2)
+	assert.Equal(t, "web1", list.GetMetrics()[0].GetLabels()["host"])
+	assert.Equal(t, "web2", list.GetMetrics()[1].GetLabels()["host"])
+
+	_, err = client.ListMetrics(ctx, &pb.ListMetricsRequest{Labels: "host"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "Alloc", Type: "gauge", Value: &web1, Labels: map[string]string{"1host": "web1"}},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: `Alloc{host="web2"}`, Type: "gauge", Value: &web1},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -1,12 +1,19 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
 	"github.com/go-chi/chi/v5"
 	"github.com/mailru/easyjson"
 )
@@ -16,7 +23,7 @@ func (s *Server) writeGetMetricHandler(rw http.ResponseWriter, req *http.Request
 	metricName := chi.URLParam(req, "metricName")
 	metricValue := chi.URLParam(req, "metricValue")
 
-	storage := s.storage
+	storage := s.storageOf(req.Context())
 	metric, err := storage.GetMetricType(metricType)
 
 	if err != nil {
@@ -24,10 +31,28 @@ func (s *Server) writeGetMetricHandler(rw http.ResponseWriter, req *http.Request
 		return
 	}
 
-	if err := metric.Process(req.Context(), metricName, metricValue); err != nil {
+	if err := dto.ValidateMetricName(metricName); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var samples map[string]float64
+	err = s.withQuota(req.Context(), map[string][]string{metricType: {metricName}}, false, func() error {
+		var err error
+		samples, err = processSample(req.Context(), metric, metricName, metricValue)
+		return err
+	})
+	var quotaErr *seriesQuotaError
+	if errors.As(err, &quotaErr) {
+		http.Error(rw, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
 		http.Error(rw, fmt.Sprintf("failed to process metric: %s", err.Error()), http.StatusBadRequest)
 		return
 	}
+
+	s.recordHistory(req.Context(), metricType, samples)
 }
 
 func (s *Server) writePostMetricHandler(rw http.ResponseWriter, req *http.Request) {
@@ -36,16 +61,34 @@ func (s *Server) writePostMetricHandler(rw http.ResponseWriter, req *http.Reques
 
 	if err != nil {
 		rw.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"%s"}`, err.Error()))
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":%q}`, err.Error()))
 		return
 	}
 
-	storage := s.storage
+	storage := s.storageOf(req.Context())
 	metric, err := storage.GetMetricType(metricDTO.MType)
 
 	if err != nil {
 		rw.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"metric type %s not found"}`, metricDTO.MType))
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":%q}`, fmt.Sprintf("metric type %s not found", metricDTO.MType)))
+		return
+	}
+
+	if err := dto.ValidateMetricName(metricDTO.ID); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":%q}`, err.Error()))
+		return
+	}
+
+	if metricDTO.Histogram != nil || metricDTO.Summary != nil || len(metricDTO.Labels) > 0 {
+		if err := s.updateMetrics(req.Context(), dto.MetricsCollection{*metricDTO}); err != nil {
+			rw.WriteHeader(updateErrorStatus(err))
+			fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":%q}`, err.Error()))
+			return
+		}
+
+		json, _ := easyjson.Marshal(metricDTO)
+		fmt.Fprintf(rw, "%v", string(json))
 		return
 	}
 
@@ -62,44 +105,46 @@ func (s *Server) writePostMetricHandler(rw http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	_ = metric.Process(req.Context(), metricDTO.ID, value)
-	json, _ := easyjson.Marshal(metricDTO)
-	fmt.Fprintf(rw, "%v", string(json))
-}
-
-func (s *Server) showAllMetricHandler(rw http.ResponseWriter, req *http.Request) {
-	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
-	stgList := s.storage.GetList()
-
-	if len(stgList) == 0 {
-		s.notFoundHandler(rw, req)
+	var samples map[string]float64
+	err = s.withQuota(req.Context(), map[string][]string{metricDTO.MType: {metricDTO.ID}}, false, func() error {
+		var err error
+		samples, err = processSample(req.Context(), metric, metricDTO.ID, value)
+		return err
+	})
+	var quotaErr *seriesQuotaError
+	if errors.As(err, &quotaErr) {
+		rw.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":%q}`, err.Error()))
 		return
 	}
-
-	for storageType, storage := range stgList {
-		list, err := storage.GetList(req.Context())
-		if err != nil {
-			http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
-			continue
-		}
-
-		fmt.Fprintf(rw, "%s:\n", storageType)
-		for metricName, metric := range list {
-			fmt.Fprintf(rw, "\t%s: %v\n", metricName, metric)
-		}
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":%q}`, fmt.Sprintf("failed to process metric: %s", err.Error())))
+		return
 	}
+
+	s.recordHistory(req.Context(), metricDTO.MType, samples)
+	json, _ := easyjson.Marshal(metricDTO)
+	fmt.Fprintf(rw, "%v", string(json))
 }
 
+// showMetricTypeHandler writes every series of the metric type that matches the label matchers.
 func (s *Server) showMetricTypeHandler(rw http.ResponseWriter, req *http.Request) {
 
 	metricType := chi.URLParam(req, "metricType")
 
-	storage, err := s.storage.GetMetricType(metricType)
+	storage, err := s.storageOf(req.Context()).GetMetricType(metricType)
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("metric type %s not found", metricType), http.StatusNotFound)
 		return
 	}
 
+	matchers, err := requestLabelMatchers(req)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	list, err := storage.GetList(req.Context())
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
@@ -108,35 +153,138 @@ func (s *Server) showMetricTypeHandler(rw http.ResponseWriter, req *http.Request
 
 	fmt.Fprintf(rw, "%s:\n", metricType)
 
-	for metricName, metric := range list {
-		fmt.Fprintf(rw, "\t%s: %v\n", metricName, metric)
+	for _, key := range selectSeries(list, "", matchers) {
+		fmt.Fprintf(rw, "\t%s: %v\n", key, list[key])
 	}
 }
 
+// showMetricNameHandlers writes the value of the series with the name that match the label matchers.
+// A single series is written as a bare value, several series are written one per line with the series key.
 func (s *Server) showMetricNameHandlers(rw http.ResponseWriter, req *http.Request) {
 	metricType := chi.URLParam(req, "metricType")
 	metricName := chi.URLParam(req, "metricName")
 
-	storage, err := s.storage.GetMetricType(metricType)
+	storage, err := s.storageOf(req.Context()).GetMetricType(metricType)
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("metric type %s not found", metricType), http.StatusNotFound)
 		return
 	}
 
+	matchers, err := requestLabelMatchers(req)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if histogram, ok := storage.(storages.HistogramMetric); ok {
+		s.showHistogramHandler(rw, req, histogram, metricName, matchers)
+		return
+	}
+
+	if summary, ok := storage.(storages.SummaryMetric); ok {
+		s.showSummaryHandler(rw, req, summary, metricName, matchers)
+		return
+	}
+
 	list, err := storage.GetList(req.Context())
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
 		return
 	}
 
-	metric := list[metricName]
+	keys := selectSeries(list, metricName, matchers)
 
-	if metric == 0 {
+	if len(keys) == 0 || (len(keys) == 1 && list[keys[0]] == 0) {
 		s.notFoundHandler(rw, req)
 		return
 	}
 
-	fmt.Fprintf(rw, "%v", metric)
+	if len(keys) == 1 {
+		fmt.Fprintf(rw, "%v", list[keys[0]])
+		return
+	}
+
+	for _, key := range keys {
+		fmt.Fprintf(rw, "%s %v\n", key, list[key])
+	}
+}
+
+// showHistogramHandler writes the count, the sum and the cumulative bucket counts of a histogram.
+// Series selected by the label matchers are merged, series with other bounds than the first one are skipped.
+func (s *Server) showHistogramHandler(rw http.ResponseWriter, req *http.Request, histogram storages.HistogramMetric, metricName string, matchers []labelMatcher) {
+	items, err := histogram.GetHistograms(req.Context())
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	keys := selectSeries(items, metricName, matchers)
+	if len(keys) == 0 {
+		s.notFoundHandler(rw, req)
+		return
+	}
+
+	first := items[keys[0]]
+	item := first.Clone()
+	for _, key := range keys[1:] {
+		if err := item.Merge(items[key]); err != nil {
+			s.logger.Warnf("histogram %s skipped: %s", key, err)
+		}
+	}
+
+	fmt.Fprintf(rw, "count: %d\n", item.Count)
+	fmt.Fprintf(rw, "sum: %v\n", item.Sum)
+
+	var cumulative uint64
+	for i, count := range item.Counts {
+		cumulative += count
+		bound := "+Inf"
+		if i < len(item.Bounds) {
+			bound = strconv.FormatFloat(item.Bounds[i], 'f', -1, 64)
+		}
+		fmt.Fprintf(rw, "le %s: %d\n", bound, cumulative)
+	}
+}
+
+// showSummaryHandler writes the count, the sum and the estimated quantiles of a summary.
+// Quantiles are taken from the comma separated q query parameter, default quantiles are used without it.
+// Sketches of the series selected by the label matchers are merged before quantiles are estimated.
+func (s *Server) showSummaryHandler(rw http.ResponseWriter, req *http.Request, summary storages.SummaryMetric, metricName string, matchers []labelMatcher) {
+	quantiles := dto.DefaultQuantiles
+	if q := req.URL.Query().Get("q"); q != "" {
+		var err error
+		if quantiles, err = parseQuantiles(q); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	items, err := summary.GetSummaries(req.Context())
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	keys := selectSeries(items, metricName, matchers)
+	if len(keys) == 0 {
+		s.notFoundHandler(rw, req)
+		return
+	}
+
+	first := items[keys[0]]
+	item := first.Clone()
+	for _, key := range keys[1:] {
+		if err := item.Merge(items[key]); err != nil {
+			s.logger.Warnf("summary %s skipped: %s", key, err)
+		}
+	}
+
+	fmt.Fprintf(rw, "count: %d\n", item.Count)
+	fmt.Fprintf(rw, "sum: %v\n", item.Sum)
+
+	for _, q := range quantiles {
+		fmt.Fprintf(rw, "quantile %s: %v\n", strconv.FormatFloat(q, 'f', -1, 64), item.Quantile(q))
+	}
 }
 
 func (s *Server) showPostMetricHandler(rw http.ResponseWriter, req *http.Request) {
@@ -152,33 +300,30 @@ func (s *Server) showPostMetricHandler(rw http.ResponseWriter, req *http.Request
 	metricType := metricDTO.MType
 	metricName := metricDTO.ID
 
-	storage, err := s.storage.GetMetricType(metricType)
+	storage, err := s.storageOf(req.Context()).GetMetricType(metricType)
 	if err != nil {
 		rw.WriteHeader(http.StatusNotFound)
 		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"metric type %s not found"}`, metricType))
 		return
 	}
 
-	list, err := storage.GetList(req.Context())
+	metric, ok, err := findMetricDto(req.Context(), metricType, dto.SeriesKey(metricName, metricDTO.Labels), storage)
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
 		return
 	}
 
-	metric, ok := list[metricName]
-
 	if !ok {
 		rw.WriteHeader(http.StatusNotFound)
 		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"metric %s not found"}`, metricName))
 		return
 	}
 
-	if metricDTO.MType == "gauge" {
-		metricDTO.Value = &metric
-	} else {
-		val := int64(metric)
-		metricDTO.Delta = &val
-	}
+	metricDTO.Value = metric.Value
+	metricDTO.Delta = metric.Delta
+	metricDTO.Histogram = metric.Histogram
+	metricDTO.Summary = metric.Summary
+	metricDTO.UpdatedAt = metric.UpdatedAt
 
 	json, _ := easyjson.Marshal(metricDTO)
 
@@ -189,6 +334,20 @@ func (s *Server) notFoundHandler(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusNotFound)
 }
 
+// parseQuantiles parses comma separated quantiles, every quantile must be in [0, 1].
+func parseQuantiles(v string) ([]float64, error) {
+	var quantiles []float64
+	for _, item := range strings.Split(v, ",") {
+		q, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil || q < 0 || q > 1 {
+			return nil, fmt.Errorf("invalid quantile %s", item)
+		}
+		quantiles = append(quantiles, q)
+	}
+
+	return quantiles, nil
+}
+
 func getMetricDto(req *http.Request) (*dto.Metrics, error) {
 	metricDTO := &dto.Metrics{}
 	rawBytes, _ := io.ReadAll(req.Body)
@@ -200,15 +359,66 @@ func getMetricDto(req *http.Request) (*dto.Metrics, error) {
 	return metricDTO, nil
 }
 
-func (s *Server) postgersPingHandler(writer http.ResponseWriter, _ *http.Request) {
-	if s.dbIsOpen {
+// dbPingTimeout limits the ping of the database by the /ping handler.
+const dbPingTimeout = 3 * time.Second
+
+// dbState is the response of the /ping handler for the postgres storage.
+type dbState struct {
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	Pool   *clients.PoolStat `json:"pool,omitempty"`
+}
+
+func (s *Server) postgersPingHandler(writer http.ResponseWriter, req *http.Request) {
+	if s.snapshot != nil {
+		if err := s.snapshot.Err(); err != nil {
+			http.Error(writer, fmt.Sprintf("file storage: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
 		writer.WriteHeader(http.StatusOK)
 		return
 	}
 
+	if s.bolt != nil {
+		if err := s.bolt.Ping(); err != nil {
+			http.Error(writer, fmt.Sprintf("bolt storage: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if s.db != nil {
+		s.pingDB(writer, req)
+		return
+	}
+
 	writer.WriteHeader(http.StatusInternalServerError)
 }
 
+// pingDB pings the database and writes its state with the statistics of the connection pool.
+func (s *Server) pingDB(writer http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), dbPingTimeout)
+	defer cancel()
+
+	state := dbState{Status: "ok"}
+	status := http.StatusOK
+	if err := s.db.Ping(ctx); err != nil {
+		state = dbState{Status: "error", Error: err.Error()}
+		status = http.StatusInternalServerError
+	}
+	if stat, ok := s.db.Stat(); ok {
+		state.Pool = &stat
+	}
+
+	body, _ := json.Marshal(state)
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	fmt.Fprintf(writer, "%v", string(body))
+}
+
 func (s *Server) writeMassPostMetricHandler(rw http.ResponseWriter, req *http.Request) {
 	rw.Header().Set("Content-Type", "application/json")
 	metricDTOCollection := &dto.MetricsCollection{}
@@ -220,38 +430,10 @@ func (s *Server) writeMassPostMetricHandler(rw http.ResponseWriter, req *http.Re
 		return
 	}
 
-	list := make(map[string]map[string]float64)
-
-	for _, metricDTO := range *metricDTOCollection {
-		if list[metricDTO.MType] == nil {
-			list[metricDTO.MType] = make(map[string]float64)
-		}
-
-		if metricDTO.Delta != nil {
-			list[metricDTO.MType][metricDTO.ID] += float64(*metricDTO.Delta)
-		} else if metricDTO.Value != nil {
-			list[metricDTO.MType][metricDTO.ID] = *metricDTO.Value
-		}
-	}
-
-	storage := s.storage
-
-	for metricType, metric := range list {
-		matric, err := storage.GetMetricType(metricType)
-
-		if err != nil {
-			rw.WriteHeader(http.StatusNotFound)
-			fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"metric type %s not found"}`, metricType))
-			return
-		}
-
-		err = matric.ProcessMassive(req.Context(), metric)
-
-		if err != nil {
-			rw.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"%s"}`, err.Error()))
-			return
-		}
+	if err := s.updateMetrics(req.Context(), *metricDTOCollection); err != nil {
+		rw.WriteHeader(updateErrorStatus(err))
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"%s"}`, err.Error()))
+		return
 	}
 
 	json, _ := easyjson.Marshal(metricDTOCollection)
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/mailru/easyjson"
+)
+
+// defaultHistoryRange is the length of the range of a history query without the from parameter.
+const defaultHistoryRange = time.Hour
+
+// defaultHistoryStep is the step of a history query without the step parameter.
+const defaultHistoryStep = time.Minute
+
+// maxHistoryPoints limits the number of steps of a single history query.
+const maxHistoryPoints = 11000
+
+// pruneHistory caps the histories at size samples per series every interval.
+func (s *Server) pruneHistory(ctx context.Context, histories []storages.PrunableHistory, size int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, history := range histories {
+				if err := history.Prune(ctx, size); err != nil {
+					s.logger.Error(fmt.Errorf("history: %w", err))
+				}
+			}
+		}
+	}
+}
+
+// recordHistory stores the written values in the history of the request tenant, failures are only logged.
+func (s *Server) recordHistory(ctx context.Context, metricType string, samples map[string]float64) {
+	t := s.tenantOf(ctx)
+	if t.history == nil || len(samples) == 0 {
+		return
+	}
+
+	if err := t.history.Record(ctx, metricType, samples, time.Now()); err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// deleteHistory drops the history of the deleted series of the request tenant.
+func (s *Server) deleteHistory(ctx context.Context, metricType string, keys []string) {
+	history, ok := s.tenantOf(ctx).history.(storages.DeletableHistory)
+	if !ok || len(keys) == 0 {
+		return
+	}
+
+	if err := history.Delete(ctx, metricType, keys); err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// processSample writes the value of the named series and returns the stored value.
+func processSample(ctx context.Context, entity storages.EntityMetric, name string, data string) (map[string]float64, error) {
+	sampled, ok := entity.(storages.SampledMetric)
+	if !ok {
+		return nil, entity.Process(ctx, name, data)
+	}
+
+	value, err := sampled.ProcessSample(ctx, name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]float64{name: value}, nil
+}
+
+// processSamples writes the values of the series and returns the stored values.
+func processSamples(ctx context.Context, entity storages.EntityMetric, data map[string]float64) (map[string]float64, error) {
+	sampled, ok := entity.(storages.SampledMetric)
+	if !ok {
+		return nil, entity.ProcessMassive(ctx, data)
+	}
+
+	return sampled.ProcessSamples(ctx, data)
+}
+
+// historyHandler writes the downsampled history of the series with the name that match the label matchers.
+func (s *Server) historyHandler(rw http.ResponseWriter, req *http.Request) {
+	metricType := chi.URLParam(req, "metricType")
+	metricName := chi.URLParam(req, "metricName")
+
+	t := s.tenantOf(req.Context())
+	if t.history == nil {
+		http.Error(rw, "history is disabled", http.StatusNotFound)
+		return
+	}
+
+	storage, err := t.storage.GetMetricType(metricType)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("metric type %s not found", metricType), http.StatusNotFound)
+		return
+	}
+
+	from, to, step, err := parseHistoryRange(req, time.Now())
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	matchers, err := requestLabelMatchers(req)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := storage.GetList(req.Context())
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	keys := selectSeries(list, metricName, matchers)
+	if len(keys) == 0 {
+		http.Error(rw, fmt.Sprintf("metric %s not found", metricName), http.StatusNotFound)
+		return
+	}
+
+	collection := make(dto.HistorySeriesCollection, 0, len(keys))
+	for _, key := range keys {
+		samples, err := t.history.Range(req.Context(), metricType, key, from, to)
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("failed to get history of metric: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		name, labels := dto.ParseSeriesKey(key)
+		collection = append(collection, dto.HistorySeries{
+			ID:     name,
+			MType:  metricType,
+			Labels: labels,
+			Points: downsample(samples, from, step),
+		})
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	json, _ := easyjson.Marshal(collection)
+	fmt.Fprintf(rw, "%v", string(json))
+}
+
+// parseHistoryRange parses the from, to and step parameters of a history query.
+func parseHistoryRange(req *http.Request, now time.Time) (time.Time, time.Time, time.Duration, error) {
+	query := req.URL.Query()
+
+	to, err := parseHistoryTime(query.Get("to"), now)
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+
+	from, err := parseHistoryTime(query.Get("from"), to.Add(-defaultHistoryRange))
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+
+	step, err := parseHistoryStep(query.Get("step"))
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, 0, errors.New("from must be before to")
+	}
+
+	if to.Sub(from)/step >= maxHistoryPoints {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("step is too small, a query can't return more than %d points", maxHistoryPoints)
+	}
+
+	return from, to, step, nil
+}
+
+// parseHistoryTime parses a time given as RFC 3339 or unix seconds.
+func parseHistoryTime(v string, def time.Time) (time.Time, error) {
+	if v == "" {
+		return def, nil
+	}
+
+	if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+		whole, frac := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(frac*float64(time.Second))), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s", v)
+	}
+
+	return t, nil
+}
+
+// parseHistoryStep parses a positive step given as a duration or seconds.
+func parseHistoryStep(v string) (time.Duration, error) {
+	if v == "" {
+		return defaultHistoryStep, nil
+	}
+
+	step, err := time.ParseDuration(v)
+	if err != nil {
+		seconds, errSeconds := strconv.ParseFloat(v, 64)
+		if errSeconds != nil {
+			return 0, fmt.Errorf("invalid step %s", v)
+		}
+		step = time.Duration(seconds * float64(time.Second))
+	}
+
+	if step <= 0 {
+		return 0, fmt.Errorf("step must be positive: %s", v)
+	}
+
+	return step, nil
+}
+
+// downsample aggregates samples ordered by time into steps starting at from.
+func downsample(samples []dto.Sample, from time.Time, step time.Duration) []dto.HistoryPoint {
+	points := make([]dto.HistoryPoint, 0)
+
+	for _, sample := range samples {
+		start := from.Add(sample.Time.Sub(from) / step * step)
+
+		if len(points) == 0 || !points[len(points)-1].Time.Equal(start) {
+			points = append(points, dto.HistoryPoint{Time: start, Min: sample.Value, Max: sample.Value})
+		}
+
+		point := &points[len(points)-1]
+		point.Avg += (sample.Value - point.Avg) / float64(point.Count+1)
+		point.Min = math.Min(point.Min, sample.Value)
+		point.Max = math.Max(point.Max, sample.Value)
+		point.Last = sample.Value
+		point.Count++
+	}
+
+	return points
+}
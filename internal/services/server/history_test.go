@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/mocks"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDownsample(t *testing.T) {
+	from := time.Unix(1000, 0)
+	samples := []dto.Sample{
+		{Time: from, Value: 1},
+		{Time: from.Add(10 * time.Second), Value: 3},
+		{Time: from.Add(59 * time.Second), Value: 2},
+		{Time: from.Add(3 * time.Minute), Value: 5},
+	}
+
+	assert.Equal(t, []dto.HistoryPoint{
+		{Time: from, Avg: 2, Min: 1, Max: 3, Last: 2, Count: 3},
+		{Time: from.Add(3 * time.Minute), Avg: 5, Min: 5, Max: 5, Last: 5, Count: 1},
+	}, downsample(samples, from, time.Minute))
+	assert.Empty(t, downsample(nil, from, time.Minute))
+}
+
+func TestParseHistoryRange(t *testing.T) {
+	now := time.Unix(10000, 0)
+
+	testCases := []struct {
+		name  string
+		query string
+		from  time.Time
+		to    time.Time
+		step  time.Duration
+		err   bool
+	}{
+		{name: "defaults", query: "", from: now.Add(-time.Hour), to: now, step: time.Minute},
+		{name: "unix seconds", query: "?from=100&to=400.5&step=30", from: time.Unix(100, 0), to: time.Unix(400, 5e8), step: 30 * time.Second},
+		{name: "rfc3339", query: "?from=2024-01-01T00:00:00Z&to=2024-01-01T01:00:00Z&step=5m", from: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), step: 5 * time.Minute},
+		{name: "bad time", query: "?from=yesterday", err: true},
+		{name: "bad step", query: "?step=often", err: true},
+		{name: "negative step", query: "?step=-1s", err: true},
+		{name: "from after to", query: "?from=500&to=400", err: true},
+		{name: "too many points", query: "?from=0&to=100000&step=1", err: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/history/gauge/Alloc"+testCase.query, nil)
+			from, to, step, err := parseHistoryRange(req, now)
+			if testCase.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, testCase.from.Equal(from), "from %v", from)
+			assert.True(t, testCase.to.Equal(to), "to %v", to)
+			assert.Equal(t, testCase.step, step)
+		})
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(nil))
+	stg.AddMetric("counter", metrics.NewCounter(nil))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+		history: storages.NewMemHistory(2),
+	}
+
+	r := chi.NewRouter()
+	r.With(s.JSONContentTypeMiddleware).Post("/updates/", s.writeMassPostMetricHandler)
+	r.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
+	r.Get("/history/{metricType}/{metricName}", s.historyHandler)
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	web1, web2 := 1.5, 2.5
+	batch, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "Alloc", MType: "gauge", Value: &web1, Labels: map[string]string{"host": "web1"}},
+		{ID: "Alloc", MType: "gauge", Value: &web2, Labels: map[string]string{"host": "web2"}},
+	})
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", batch, headers)
+	testHandler(t, r, http.MethodPost, "/update/counter/PollCount/2", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/counter/PollCount/3", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/counter/PollCount/4", http.StatusOK, "", nil, nil)
+
+	samples, err := s.history.Range(context.Background(), "counter", "PollCount", time.Unix(0, 0), time.Now())
+	assert.NoError(t, err)
+	if assert.Len(t, samples, 2) {
+		assert.Equal(t, 5.0, samples[0].Value)
+		assert.Equal(t, 9.0, samples[1].Value)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/history/gauge/Alloc?labels=host=web2&step=1h", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var collection dto.HistorySeriesCollection
+	assert.NoError(t, easyjson.Unmarshal(rec.Body.Bytes(), &collection))
+	if assert.Len(t, collection, 1) {
+		assert.Equal(t, "Alloc", collection[0].ID)
+		assert.Equal(t, map[string]string{"host": "web2"}, collection[0].Labels)
+		if assert.Len(t, collection[0].Points, 1) {
+			assert.Equal(t, 2.5, collection[0].Points[0].Last)
+			assert.Equal(t, 1, collection[0].Points[0].Count)
+		}
+	}
+
+	testHandler(t, r, http.MethodGet, "/history/gauge/Alloc?from=0&to=1", http.StatusOK, `[{"id":"Alloc","type":"gauge","labels":{"host":"web1"},"points":[]},{"id":"Alloc","type":"gauge","labels":{"host":"web2"},"points":[]}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/history/gauge/Heap", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/history/unknown/Alloc", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/history/gauge/Alloc?step=0", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/history/gauge/Alloc?labels=host", http.StatusBadRequest, "skip", nil, nil)
+
+	s.history = nil
+	testHandler(t, r, http.MethodGet, "/history/gauge/Alloc", http.StatusNotFound, "skip", nil, nil)
+}
+
+func TestRecordHistoryFromWrittenValues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	counter := mocks.NewMockSampledMetric(ctrl)
+	counter.EXPECT().ProcessSample(gomock.Any(), "PollCount", "2").Return(7.0, nil)
+	counter.EXPECT().ProcessSamples(gomock.Any(), map[string]float64{"PollCount": 3}).Return(map[string]float64{"PollCount": 10}, nil)
+	stg := storages.NewMemStorage()
+	stg.AddMetric("counter", counter)
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+		history: storages.NewMemHistory(4),
+	}
+
+	r := chi.NewRouter()
+	r.With(s.JSONContentTypeMiddleware).Post("/updates/", s.writeMassPostMetricHandler)
+	r.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
+
+	delta := int64(3)
+	batch, _ := easyjson.Marshal(dto.MetricsCollection{{ID: "PollCount", MType: "counter", Delta: &delta}})
+	testHandler(t, r, http.MethodPost, "/update/counter/PollCount/2", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", batch, map[string]string{"Content-Type": "application/json"})
+
+	samples, err := s.history.Range(context.Background(), "counter", "PollCount", time.Unix(0, 0), time.Now())
+	assert.NoError(t, err)
+	if assert.Len(t, samples, 2) {
+		assert.Equal(t, 7.0, samples[0].Value)
+		assert.Equal(t, 10.0, samples[1].Value)
+	}
+}
+
+// prunedHistory records the sizes it was pruned to.
+type prunedHistory struct {
+	*storages.MemHistory
+	sizes chan int
+}
+
+func (p *prunedHistory) Prune(_ context.Context, size int) error {
+	p.sizes <- size
+	return nil
+}
+
+func TestPruneHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	history := &prunedHistory{MemHistory: storages.NewMemHistory(1), sizes: make(chan int, 1)}
+	s := &Server{logger: slog.New()}
+	go s.pruneHistory(ctx, []storages.PrunableHistory{history}, 100, time.Millisecond)
+
+	select {
+	case size := <-history.sizes:
+		assert.Equal(t, 100, size)
+	case <-time.After(time.Second):
+		t.Fatal("history wasn't pruned")
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+)
+
+// labelsQueryParam is the query parameter of read endpoints that holds label matchers.
+const labelsQueryParam = "labels"
+
+// labelMatcher selects series by the value of a single label, a missing label matches as an empty value.
+type labelMatcher struct {
+	name   string
+	op     string
+	value  string
+	regexp *regexp.Regexp
+}
+
+func (m labelMatcher) matches(labels map[string]string) bool {
+	value := labels[m.name]
+
+	switch m.op {
+	case "=":
+		return value == m.value
+	case "!=":
+		return value != m.value
+	case "=~":
+		return m.regexp.MatchString(value)
+	default:
+		return !m.regexp.MatchString(value)
+	}
+}
+
+// parseLabelMatchers parses comma separated matchers such as host=web1,env!=dev,service=~api.*
+// The operators are =, !=, =~ and !~, regular expressions are anchored at both ends.
+func parseLabelMatchers(v string) ([]labelMatcher, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	var matchers []labelMatcher
+	for _, item := range strings.Split(v, ",") {
+		i := strings.IndexAny(item, "=!")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid label matcher %s", item)
+		}
+
+		matcher := labelMatcher{name: strings.TrimSpace(item[:i])}
+		rest := item[i:]
+		for _, op := range []string{"=~", "!~", "!=", "="} {
+			if strings.HasPrefix(rest, op) {
+				matcher.op = op
+				matcher.value = rest[len(op):]
+				break
+			}
+		}
+
+		if matcher.op == "" {
+			return nil, fmt.Errorf("invalid label matcher %s", item)
+		}
+
+		if matcher.op == "=~" || matcher.op == "!~" {
+			re, err := regexp.Compile("^(?:" + matcher.value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid label matcher %s: %w", item, err)
+			}
+			matcher.regexp = re
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	return matchers, nil
+}
+
+// requestLabelMatchers parses the label matchers of a request.
+func requestLabelMatchers(req *http.Request) ([]labelMatcher, error) {
+	return parseLabelMatchers(req.URL.Query().Get(labelsQueryParam))
+}
+
+// matchSeries reports whether the series key matches every matcher.
+// An empty name matches any name.
+func matchSeries(key string, name string, matchers []labelMatcher) bool {
+	seriesName, labels := dto.ParseSeriesKey(key)
+	if name != "" && seriesName != name {
+		return false
+	}
+
+	for _, matcher := range matchers {
+		if !matcher.matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selectSeries returns the sorted keys of the series with the name that match every matcher.
+func selectSeries[V any](items map[string]V, name string, matchers []labelMatcher) []string {
+	var keys []string
+	for key := range items {
+		if matchSeries(key, name, matchers) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
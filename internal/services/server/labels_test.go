@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeriesKey(t *testing.T) {
+	labels := map[string]string{"service": "api", "host": `web"1\` + "\n"}
+	key := dto.SeriesKey("Alloc", labels)
+	assert.Equal(t, `Alloc{host="web\"1\\\n",service="api"}`, key)
+
+	name, parsed := dto.ParseSeriesKey(key)
+	assert.Equal(t, "Alloc", name)
+	assert.Equal(t, labels, parsed)
+
+	assert.Equal(t, "Alloc", dto.SeriesKey("Alloc", nil))
+	name, parsed = dto.ParseSeriesKey("Alloc")
+	assert.Equal(t, "Alloc", name)
+	assert.Nil(t, parsed)
+
+	name, parsed = dto.ParseSeriesKey(`Alloc{host=web}`)
+	assert.Equal(t, `Alloc{host=web}`, name)
+	assert.Nil(t, parsed)
+
+	assert.NoError(t, dto.ValidateMetricName("heap.alloc-bytes"))
+	assert.Error(t, dto.ValidateMetricName(`Alloc{host="web1"}`))
+	assert.Error(t, dto.ValidateMetricName("Alloc}"))
+	assert.Error(t, dto.ValidateMetricName(`Alloc"`))
+
+	assert.NoError(t, dto.ValidateLabels(map[string]string{"_host1": "a"}))
+	assert.Error(t, dto.ValidateLabels(map[string]string{"1host": "a"}))
+	assert.Error(t, dto.ValidateLabels(map[string]string{"host-name": "a"}))
+}
+
+func TestParseLabelMatchers(t *testing.T) {
+	labels := map[string]string{"host": "web1", "env": "prod"}
+
+	testCases := []struct {
+		name     string
+		matchers string
+		matches  bool
+		err      bool
+	}{
+		{name: "empty", matchers: "", matches: true},
+		{name: "equal", matchers: "host=web1", matches: true},
+		{name: "not equal", matchers: "host!=web1", matches: false},
+		{name: "regexp", matchers: "host=~web.*,env=prod", matches: true},
+		{name: "regexp is anchored", matchers: "host=~web", matches: false},
+		{name: "not regexp", matchers: "env!~dev|test", matches: true},
+		{name: "missing label", matchers: "dc=", matches: true},
+		{name: "no operator", matchers: "host", err: true},
+		{name: "no name", matchers: "=web1", err: true},
+		{name: "bad regexp", matchers: "host=~(", err: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			matchers, err := parseLabelMatchers(testCase.matchers)
+			if testCase.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.matches, matchSeries(dto.SeriesKey("Alloc", labels), "Alloc", matchers))
+		})
+	}
+}
+
+func TestLabeledMetricHandlers(t *testing.T) {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(nil))
+	stg.AddMetric("counter", metrics.NewCounter(nil))
+	stg.AddMetric("summary", metrics.NewSummary(nil))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+
+	r := chi.NewRouter()
+	r.With(s.JSONContentTypeMiddleware).Post("/update/", s.writePostMetricHandler)
+	r.With(s.JSONContentTypeMiddleware).Post("/updates/", s.writeMassPostMetricHandler)
+	r.With(s.JSONContentTypeMiddleware).Post("/value/", s.showPostMetricHandler)
+	r.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
+	r.Get("/value/{metricType}", s.showMetricTypeHandler)
+	r.Get("/value/{metricType}/{metricName}", s.showMetricNameHandlers)
+	r.Get("/metrics", s.prometheusMetricsHandler)
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	web1, web2 := 1.5, 2.5
+	delta := int64(3)
+	batch, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "Alloc", MType: "gauge", Value: &web1, Labels: map[string]string{"host": "web1"}},
+		{ID: "Alloc", MType: "gauge", Value: &web2, Labels: map[string]string{"host": "web2"}},
+		{ID: "PollCount", MType: "counter", Delta: &delta, Labels: map[string]string{"host": "web1"}},
+		{ID: "PollCount", MType: "counter", Delta: &delta, Labels: map[string]string{"host": "web1"}},
+		{ID: "latency", MType: "summary", Value: &web1, Labels: map[string]string{"host": "web1"}},
+		{ID: "latency", MType: "summary", Value: &web2, Labels: map[string]string{"host": "web2"}},
+	})
+	invalid, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "Alloc", MType: "gauge", Value: &web1, Labels: map[string]string{"host-name": "web1"}},
+	})
+	single, _ := easyjson.Marshal(dto.Metrics{ID: "Alloc", MType: "gauge", Value: &web1})
+	web3 := 9.5
+	delimited, _ := easyjson.Marshal(dto.Metrics{ID: `Alloc{host="web2"}`, MType: "gauge", Value: &web3})
+	delimitedBatch, _ := easyjson.Marshal(dto.MetricsCollection{{ID: `Alloc{host="web2"}`, MType: "gauge", Value: &web3}})
+	read, _ := easyjson.Marshal(dto.Metrics{ID: "Alloc", MType: "gauge", Labels: map[string]string{"host": "web2"}})
+
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", batch, headers)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusBadRequest, "skip", invalid, headers)
+	testHandler(t, r, http.MethodPost, "/update/", http.StatusOK, "skip", single, headers)
+	testHandler(t, r, http.MethodPost, "/update/", http.StatusBadRequest, "skip", delimited, headers)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusBadRequest, "skip", delimitedBatch, headers)
+	testHandler(t, r, http.MethodPost, `/update/gauge/Alloc{host="web2"}/9.5`, http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/gauge/Alloc%7Bhost=%22web2%22%7D/9.5", http.StatusBadRequest, "skip", nil, nil)
+
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc?labels=host=web2", http.StatusOK, "2.5", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc?labels=host=~web.*", http.StatusOK, "Alloc{host=\"web1\"} 1.5\nAlloc{host=\"web2\"} 2.5\n", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc?labels=host=", http.StatusOK, "1.5", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc?labels=host=web3", http.StatusNotFound, "", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc?labels=host", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/counter/PollCount", http.StatusOK, "6", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge?labels=host!=web1", http.StatusOK, "gauge:\n\tAlloc: 1.5\n\tAlloc{host=\"web2\"}: 2.5\n", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/summary/latency?q=0,1", http.StatusOK, "count: 2\nsum: 4\nquantile 0: 1.5\nquantile 1: 2.5\n", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/summary/latency?q=1&labels=host=web1", http.StatusOK, "count: 1\nsum: 1.5\nquantile 1: 1.5\n", nil, nil)
+	testHandler(t, r, http.MethodPost, "/value/", http.StatusOK, `{"id":"Alloc","type":"gauge","value":2.5,"labels":{"host":"web2"}}`, read, headers)
+
+	testHandler(t, r, http.MethodGet, "/metrics", http.StatusOK, "skip", nil, nil)
+
+	list, _ := stg.GetList()["gauge"].GetList(context.Background())
+	assert.Len(t, list, 3)
+}
+
+func TestPrometheusLabeledSeries(t *testing.T) {
+	gauge := metrics.NewGauge(nil)
+	_ = gauge.ProcessMassive(context.Background(), map[string]float64{
+		"Alloc":                  1,
+		"Alloc_total":            2,
+		`Alloc{host="web1"}`:     3,
+		`Alloc{host="w\"eb2"}`:   4,
+		`heap.alloc{host="web"}`: 5,
+	})
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", gauge)
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+	r := chi.NewRouter()
+	r.Get("/metrics", s.prometheusMetricsHandler)
+
+	testHandler(t, r, http.MethodGet, "/metrics", http.StatusOK, `# TYPE Alloc gauge
+Alloc 1
+Alloc{host="w\"eb2"} 4
+Alloc{host="web1"} 3
+# TYPE Alloc_total gauge
+Alloc_total 2
+# TYPE heap_alloc gauge
+heap_alloc{host="web"} 5
+`, nil, nil)
+}
@@ -7,12 +7,12 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"strings"
@@ -142,35 +142,51 @@ func (s *Server) JSONContentTypeMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func (s *Server) hashCheckMiddleware(next http.Handler) http.Handler {
+// adminMiddleware lets through the requests whose X-Admin-Token header carries the admin token.
+// A request without the token or with another one is unauthorized, the admin routes are forbidden when no admin token is set.
+func (s *Server) adminMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if s.conf.GetShaKey() == "" {
-			next.ServeHTTP(w, r)
+		adminToken := s.conf.GetAdminToken()
+		if adminToken == "" {
+			http.Error(w, "admin endpoints are disabled", http.StatusForbidden)
 			return
 		}
 
-		expectedHash := r.Header.Get("HashSHA256")
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(adminToken)) != 1 {
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
 
-		if expectedHash == "" {
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hashCheckMiddleware checks the HashSHA256 header of the request against the server key and the keys of the tenants.
+// The body is checked after decryption and before decompression, the same body the agent signs.
+// A request signed with the key of a tenant belongs to that tenant, a hash that matches no key is unauthorized.
+func (s *Server) hashCheckMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hash := r.Header.Get("HashSHA256")
+		if hash == "" || !s.hashKeys() {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		hash := hmac.New(sha256.New, []byte(s.conf.GetShaKey()))
-		body, _ := io.ReadAll(r.Body)
-		hash.Write(body)
-		expectedHashBytes := []byte(expectedHash)
-		calculatedHash := hash.Sum(nil)
-
-		// TODO (https://github.com/AnatolySnegovskiy/metric/issues/16): FIX ME
-		if hmac.Equal(expectedHashBytes, calculatedHash) {
-			log.Println(expectedHash)
-			log.Printf("%x", calculatedHash)
-			http.Error(w, "bad hash value", http.StatusBadRequest)
+		var body []byte
+		if r.Body != nil {
+			body, _ = io.ReadAll(r.Body)
+		}
+
+		t, err := s.hashTenant(hash, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
 		r.Body = io.NopCloser(bytes.NewBuffer(body))
+		if t != nil {
+			r = r.WithContext(withTenant(r.Context(), t))
+		}
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,377 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+)
+
+// prometheusContentType is the content type of the Prometheus text exposition format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// promSample is a single line of a metric family in the exposition format.
+type promSample struct {
+	suffix string
+	labels map[string]string
+	value  float64
+}
+
+// promFamily is a metric family with its TYPE and samples.
+// original keeps the stored metric name to report the families renamed after a name collision.
+type promFamily struct {
+	name     string
+	original string
+	kind     string
+	samples  []promSample
+}
+
+// prometheusMetricsHandler renders every stored metric in the Prometheus text exposition format.
+// A family whose name is already taken, such as a gauge named like a counter with the _total suffix, is renamed rather than dropped.
+func (s *Server) prometheusMetricsHandler(rw http.ResponseWriter, req *http.Request) {
+	families := make(map[string]*promFamily)
+	for _, family := range cacheFamilies(s.tenantOf(req.Context())) {
+		families[family.name] = family
+	}
+
+	stgList := s.storageOf(req.Context()).GetList()
+
+	for _, metricType := range sortedKeys(stgList) {
+		storage := stgList[metricType]
+		if storage == nil {
+			continue
+		}
+
+		list, err := prometheusFamilies(req.Context(), metricType, storage)
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("failed to get list of metrics: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		for _, family := range list {
+			if existing, ok := families[family.name]; ok {
+				name := family.name
+				family.name = uniqueFamilyName(families, family)
+				s.logger.Warnf("prometheus: %s %s renamed to %s, %s collides with %s %s", metricType, family.original, family.name, name, existing.kind, existing.original)
+			}
+			families[family.name] = family
+		}
+	}
+
+	rw.Header().Set("Content-Type", prometheusContentType)
+	if err := writePrometheus(rw, families); err != nil {
+		s.logger.Error(err)
+	}
+}
+
+// cacheFamilies returns the cache hits and misses of the tenant by metric type, nothing when the cache is disabled.
+func cacheFamilies(t *tenant) []*promFamily {
+	if t.cache == nil {
+		return nil
+	}
+
+	hits := &promFamily{name: "metrics_cache_hits_total", original: "metrics_cache_hits_total", kind: "counter"}
+	misses := &promFamily{name: "metrics_cache_misses_total", original: "metrics_cache_misses_total", kind: "counter"}
+	for _, metricType := range sortedKeys(t.cache) {
+		labels := map[string]string{"type": metricType}
+		hits.samples = append(hits.samples, promSample{labels: labels, value: float64(t.cache[metricType].Hits())})
+		misses.samples = append(misses.samples, promSample{labels: labels, value: float64(t.cache[metricType].Misses())})
+	}
+
+	return []*promFamily{hits, misses}
+}
+
+// uniqueFamilyName qualifies the name of the family with its type, and a number when that is taken too.
+func uniqueFamilyName(families map[string]*promFamily, family *promFamily) string {
+	name := family.name + "_" + family.kind
+	for i := 2; ; i++ {
+		if _, ok := families[name]; !ok {
+			return name
+		}
+		name = fmt.Sprintf("%s_%s_%d", family.name, family.kind, i)
+	}
+}
+
+// prometheusFamilies converts the series of the entity to metric families sorted by metric name.
+// Series with the same name and different label sets become samples of the same family.
+// Counter families get the _total suffix of the format unless the name already has it.
+func prometheusFamilies(ctx context.Context, metricType string, entity storages.EntityMetric) ([]*promFamily, error) {
+	byName := make(map[string]*promFamily)
+
+	// add appends the samples of a series to the family of its name.
+	add := func(key string, kind string, samples func(labels map[string]string) []promSample) {
+		name, labels := dto.ParseSeriesKey(key)
+		family, ok := byName[name]
+		if !ok {
+			family = &promFamily{
+				name:     sanitizeMetricName(name),
+				original: name,
+				kind:     kind,
+			}
+			if kind == "counter" && !strings.HasSuffix(family.name, "_total") {
+				family.name += "_total"
+			}
+			byName[name] = family
+		}
+		family.samples = append(family.samples, samples(labels)...)
+	}
+
+	// families returns the families sorted by the stored metric name.
+	families := func() []*promFamily {
+		list := make([]*promFamily, 0, len(byName))
+		for _, name := range sortedKeys(byName) {
+			list = append(list, byName[name])
+		}
+
+		return list
+	}
+
+	if histogram, ok := entity.(storages.HistogramMetric); ok {
+		items, err := histogram.GetHistograms(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range sortedKeys(items) {
+			add(key, "histogram", func(labels map[string]string) []promSample {
+				return histogramSamples(items[key], labels)
+			})
+		}
+
+		return families(), nil
+	}
+
+	if summary, ok := entity.(storages.SummaryMetric); ok {
+		items, err := summary.GetSummaries(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range sortedKeys(items) {
+			add(key, "summary", func(labels map[string]string) []promSample {
+				return summarySamples(items[key], labels)
+			})
+		}
+
+		return families(), nil
+	}
+
+	list, err := entity.GetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range sortedKeys(list) {
+		add(key, prometheusType(metricType), func(labels map[string]string) []promSample {
+			return []promSample{{labels: labels, value: list[key]}}
+		})
+	}
+
+	return families(), nil
+}
+
+// histogramSamples converts a histogram to cumulative _bucket samples followed by _sum and _count.
+func histogramSamples(histogram dto.Histogram, labels map[string]string) []promSample {
+	var samples []promSample
+
+	var cumulative uint64
+	for i, count := range histogram.Counts {
+		cumulative += count
+		bound := math.Inf(1)
+		if i < len(histogram.Bounds) {
+			bound = histogram.Bounds[i]
+		}
+
+		samples = append(samples, promSample{
+			suffix: "_bucket",
+			labels: withLabel(labels, "le", formatPrometheusValue(bound)),
+			value:  float64(cumulative),
+		})
+	}
+
+	return append(samples,
+		promSample{suffix: "_sum", labels: labels, value: histogram.Sum},
+		promSample{suffix: "_count", labels: labels, value: float64(histogram.Count)},
+	)
+}
+
+// summarySamples converts a summary to samples of the default quantiles followed by _sum and _count.
+func summarySamples(summary dto.Summary, labels map[string]string) []promSample {
+	var samples []promSample
+
+	for _, q := range dto.DefaultQuantiles {
+		samples = append(samples, promSample{
+			labels: withLabel(labels, "quantile", formatPrometheusValue(q)),
+			value:  summary.Quantile(q),
+		})
+	}
+
+	return append(samples,
+		promSample{suffix: "_sum", labels: labels, value: summary.Sum},
+		promSample{suffix: "_count", labels: labels, value: float64(summary.Count)},
+	)
+}
+
+// withLabel returns a copy of the label set with one more label.
+func withLabel(labels map[string]string, name string, value string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = value
+
+	return result
+}
+
+// writePrometheus writes metric families sorted by name.
+func writePrometheus(w io.Writer, families map[string]*promFamily) error {
+	bw := bufio.NewWriter(w)
+	for _, name := range sortedKeys(families) {
+		family := families[name]
+		fmt.Fprintf(bw, "# TYPE %s %s\n", family.name, family.kind)
+
+		for _, sample := range family.samples {
+			bw.WriteString(family.name + sample.suffix)
+			writeLabels(bw, sample.labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatPrometheusValue(sample.value))
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeLabels writes a label set in braces, label names are sorted to keep the output stable.
+func writeLabels(bw *bufio.Writer, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+
+	names := labelNames(labels)
+	bw.WriteByte('{')
+	for i, name := range sortedKeys(labels) {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(names[name])
+		bw.WriteString(`="`)
+		bw.WriteString(escapeLabelValue(labels[name]))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+// labelNames maps the label names to the sanitized names they are written with.
+// Valid names are kept, a sanitized name that is already taken, such as a.b next to a-b or a_b, gets a number like colliding families.
+func labelNames(labels map[string]string) map[string]string {
+	names := make(map[string]string, len(labels))
+	taken := make(map[string]bool, len(labels))
+	for name := range labels {
+		if sanitizeLabelName(name) == name {
+			names[name] = name
+			taken[name] = true
+		}
+	}
+
+	for _, name := range sortedKeys(labels) {
+		if _, ok := names[name]; ok {
+			continue
+		}
+
+		sanitized := sanitizeLabelName(name)
+		unique := sanitized
+		for i := 2; taken[unique]; i++ {
+			unique = fmt.Sprintf("%s_%d", sanitized, i)
+		}
+		names[name] = unique
+		taken[unique] = true
+	}
+
+	return names
+}
+
+// sortedKeys returns the keys of a map in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// prometheusType maps a storage metric type to a Prometheus metric type.
+func prometheusType(metricType string) string {
+	switch metricType {
+	case "gauge", "counter":
+		return metricType
+	default:
+		return "untyped"
+	}
+}
+
+// sanitizeMetricName replaces every character that is not allowed in a metric name with an underscore.
+// Valid names match [a-zA-Z_:][a-zA-Z0-9_:]*.
+func sanitizeMetricName(name string) string {
+	return sanitizeName(name, true)
+}
+
+// sanitizeLabelName replaces every character that is not allowed in a label name with an underscore.
+// Valid names match [a-zA-Z_][a-zA-Z0-9_]*.
+func sanitizeLabelName(name string) string {
+	return sanitizeName(name, false)
+}
+
+func sanitizeName(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r == ':' && allowColon:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	return b.String()
+}
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds as required by the text format.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatPrometheusValue formats a sample value, infinities and NaN use the spelling of the text format.
+func formatPrometheusValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/repositories"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusMetricsHandler(t *testing.T) {
+	gauge := metrics.NewGauge(nil)
+	counter := metrics.NewCounter(nil)
+	_ = gauge.ProcessMassive(context.Background(), map[string]float64{"Alloc": 1.5, "heap.alloc-bytes": 2, "9lives": 3})
+	_ = counter.ProcessMassive(context.Background(), map[string]float64{"PollCount": 5, "Alloc": 7})
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", gauge)
+	stg.AddMetric("counter", counter)
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+	r := chi.NewRouter()
+	r.Get("/metrics", s.prometheusMetricsHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, prometheusContentType, rr.Header().Get("Content-Type"))
+	assert.Equal(t, `# TYPE Alloc gauge
+Alloc 1.5
+# TYPE Alloc_total counter
+Alloc_total 7
+# TYPE PollCount_total counter
+PollCount_total 5
+# TYPE _9lives gauge
+_9lives 3
+# TYPE heap_alloc_bytes gauge
+heap_alloc_bytes 2
+`, rr.Body.String())
+}
+
+func TestPrometheusMetricsHandlerCollisions(t *testing.T) {
+	gauge := metrics.NewGauge(nil)
+	counter := metrics.NewCounter(nil)
+	_ = gauge.ProcessMassive(context.Background(), map[string]float64{"Alloc_total": 1, "heap.alloc": 2, "heap_alloc": 3})
+	_ = counter.ProcessMassive(context.Background(), map[string]float64{"Alloc": 4, "requests_total": 5, "requests": 6})
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", gauge)
+	stg.AddMetric("counter", counter)
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+	r := chi.NewRouter()
+	r.Get("/metrics", s.prometheusMetricsHandler)
+
+	testHandler(t, r, http.MethodGet, "/metrics", http.StatusOK, `# TYPE Alloc_total counter
+Alloc_total 4
+# TYPE Alloc_total_gauge gauge
+Alloc_total_gauge 1
+# TYPE heap_alloc gauge
+heap_alloc 2
+# TYPE heap_alloc_gauge gauge
+heap_alloc_gauge 3
+# TYPE requests_total counter
+requests_total 6
+# TYPE requests_total_counter counter
+requests_total_counter 5
+`, nil, nil)
+}
+
+func TestPrometheusMetricsHandlerDB(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("Alloc", "", float64(1.5)))
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(repositories.NewGaugeRepo(clients.NewPostgres(mock))))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+	r := chi.NewRouter()
+	r.Get("/metrics", s.prometheusMetricsHandler)
+
+	testHandler(t, r, http.MethodGet, "/metrics", http.StatusOK, "# TYPE Alloc gauge\nAlloc 1.5\n", nil, nil)
+	testHandler(t, r, http.MethodGet, "/metrics", http.StatusInternalServerError, "skip", nil, nil)
+}
+
+func TestWritePrometheusLabelCollisions(t *testing.T) {
+	families := map[string]*promFamily{
+		"requests": {
+			name: "requests",
+			kind: "gauge",
+			samples: []promSample{
+				{labels: map[string]string{"a-b": "1", "a.b": "2", "a_b": "3", "a_b_2": "4"}, value: 1},
+				{labels: map[string]string{"a-b": "1", "a.b": "2"}, value: 2},
+			},
+		},
+	}
+
+	var b strings.Builder
+	assert.NoError(t, writePrometheus(&b, families))
+	assert.Equal(t, `# TYPE requests gauge
+requests{a_b_3="1",a_b_4="2",a_b="3",a_b_2="4"} 1
+requests{a_b="1",a_b_2="2"} 2
+`, b.String())
+}
+
+func TestWritePrometheusLabels(t *testing.T) {
+	families := map[string]*promFamily{
+		"latency": {
+			name: "latency",
+			kind: "untyped",
+			samples: []promSample{
+				{labels: map[string]string{"path": `C:\tmp "x"` + "\n", "0bad-name": "v"}, value: math.Inf(1)},
+				{suffix: "_sum", value: math.NaN()},
+			},
+		},
+	}
+
+	var b strings.Builder
+	assert.NoError(t, writePrometheus(&b, families))
+
+	scanner := bufio.NewScanner(strings.NewReader(b.String()))
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.Equal(t, []string{
+		"# TYPE latency untyped",
+		`latency{_0bad_name="v",path="C:\\tmp \"x\"\n"} +Inf`,
+		"latency_sum NaN",
+	}, lines)
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/services/interfase"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/gookit/gsr"
+)
+
+// defaultRetentionInterval is the interval between the expiries when none is configured.
+const defaultRetentionInterval = time.Minute
+
+// retentionRule keeps the matching series for the ttl after their last update.
+type retentionRule struct {
+	metricType string
+	pattern    string
+	ttl        time.Duration
+}
+
+// specificity ranks the rules of a series, the most specific rule wins.
+func (r retentionRule) specificity() int {
+	specificity := 0
+	if r.pattern != "" {
+		specificity += 2
+	}
+	if r.metricType != "" {
+		specificity++
+	}
+
+	return specificity
+}
+
+func (r retentionRule) matches(metricType string, name string) bool {
+	if r.metricType != "" && r.metricType != metricType {
+		return false
+	}
+	if r.pattern == "" {
+		return true
+	}
+
+	ok, _ := path.Match(r.pattern, name)
+	return ok
+}
+
+// retentionPolicy holds the retention rules ordered from the most specific one.
+type retentionPolicy []retentionRule
+
+// parseRetention parses comma separated rules such as "24h,gauge=1h,gauge/Random*=10m".
+func parseRetention(spec string) (retentionPolicy, error) {
+	var policy retentionPolicy
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		rule := retentionRule{}
+		ttl := item
+		if i := strings.LastIndexByte(item, '='); i >= 0 {
+			selector := item[:i]
+			ttl = item[i+1:]
+
+			rule.metricType, rule.pattern, _ = strings.Cut(selector, "/")
+			if rule.metricType == "" {
+				return nil, fmt.Errorf("retention %s: missing metric type", item)
+			}
+			if rule.metricType == "*" {
+				rule.metricType = ""
+			}
+			if _, err := path.Match(rule.pattern, ""); err != nil {
+				return nil, fmt.Errorf("retention %s: invalid pattern %s", item, rule.pattern)
+			}
+		}
+
+		var err error
+		if rule.ttl, err = time.ParseDuration(ttl); err != nil || rule.ttl < 0 {
+			return nil, fmt.Errorf("retention %s: invalid duration %s", item, ttl)
+		}
+
+		policy = append(policy, rule)
+	}
+
+	sort.SliceStable(policy, func(i, j int) bool {
+		return policy[i].specificity() > policy[j].specificity()
+	})
+
+	return policy, nil
+}
+
+// ttl returns the retention of the series, zero keeps it forever.
+func (p retentionPolicy) ttl(metricType string, name string) time.Duration {
+	for _, rule := range p {
+		if rule.matches(metricType, name) {
+			return rule.ttl
+		}
+	}
+
+	return 0
+}
+
+// retentionJanitor periodically deletes the stale series.
+type retentionJanitor struct {
+	policy retentionPolicy
+	logger gsr.GenLogger
+}
+
+func newRetentionJanitor(policy retentionPolicy, logger gsr.GenLogger) *retentionJanitor {
+	return &retentionJanitor{
+		policy: policy,
+		logger: logger,
+	}
+}
+
+// run expires the stale series of the tenants every interval until the context is done.
+func (j *retentionJanitor) run(ctx context.Context, tenants []*tenant, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, t := range tenants {
+				j.expire(ctx, t.storage, t.history, now)
+			}
+		}
+	}
+}
+
+// expire deletes the stale series of the storage and their history.
+func (j *retentionJanitor) expire(ctx context.Context, storage interfase.Storage, history storages.History, now time.Time) {
+	for metricType, entity := range storage.GetList() {
+		expirable, ok := entity.(storages.ExpirableMetric)
+		if !ok {
+			continue
+		}
+
+		updated, err := expirable.UpdatedAt(ctx)
+		if errors.Is(err, storages.ErrUpdatesNotTracked) {
+			continue
+		}
+		if err != nil {
+			j.logger.Error(fmt.Errorf("retention %s: %w", metricType, err))
+			continue
+		}
+
+		stale := make(map[time.Duration][]string)
+		for key, at := range updated {
+			name, _ := dto.SplitSeriesKey(key)
+			ttl := j.policy.ttl(metricType, name)
+			if ttl > 0 && at.Before(now.Add(-ttl)) {
+				stale[ttl] = append(stale[ttl], key)
+			}
+		}
+
+		var expired []string
+		for ttl, keys := range stale {
+			if err := expirable.Expire(ctx, keys, now.Add(-ttl)); err != nil {
+				j.logger.Error(fmt.Errorf("retention %s: %w", metricType, err))
+				continue
+			}
+
+			expired = append(expired, keys...)
+			j.logger.Infof("retention: expired %d %s series older than %s", len(keys), metricType, ttl)
+		}
+
+		j.expireHistory(ctx, expirable, history, metricType, expired)
+	}
+}
+
+// expireHistory drops the history of the deleted series.
+func (j *retentionJanitor) expireHistory(ctx context.Context, expirable storages.ExpirableMetric, history storages.History, metricType string, expired []string) {
+	deletable, ok := history.(storages.DeletableHistory)
+	if !ok || len(expired) == 0 {
+		return
+	}
+
+	updated, err := expirable.UpdatedAt(ctx)
+	if err != nil {
+		j.logger.Error(fmt.Errorf("retention %s: %w", metricType, err))
+		return
+	}
+
+	var deleted []string
+	for _, key := range expired {
+		if _, ok := updated[key]; !ok {
+			deleted = append(deleted, key)
+		}
+	}
+
+	if err := deletable.Delete(ctx, metricType, deleted); err != nil {
+		j.logger.Error(fmt.Errorf("retention %s: %w", metricType, err))
+	}
+}
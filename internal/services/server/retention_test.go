@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/gookit/slog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetention(t *testing.T) {
+	policy, err := parseRetention("24h, gauge=1h, gauge/Random*=10m, */Poll*=0, counter=2h")
+	assert.NoError(t, err)
+	assert.Equal(t, retentionPolicy{
+		{metricType: "gauge", pattern: "Random*", ttl: 10 * time.Minute},
+		{pattern: "Poll*"},
+		{metricType: "gauge", ttl: time.Hour},
+		{metricType: "counter", ttl: 2 * time.Hour},
+		{ttl: 24 * time.Hour},
+	}, policy)
+
+	testCases := []struct {
+		metricType string
+		name       string
+		ttl        time.Duration
+	}{
+		{metricType: "gauge", name: "RandomValue", ttl: 10 * time.Minute},
+		{metricType: "gauge", name: "Alloc", ttl: time.Hour},
+		{metricType: "counter", name: "PollCount"},
+		{metricType: "counter", name: "Requests", ttl: 2 * time.Hour},
+		{metricType: "histogram", name: "latency", ttl: 24 * time.Hour},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.ttl, policy.ttl(testCase.metricType, testCase.name), testCase.name)
+	}
+
+	policy, err = parseRetention("gauge=1h")
+	assert.NoError(t, err)
+	assert.Zero(t, policy.ttl("counter", "PollCount"))
+
+	for _, spec := range []string{"forever", "gauge=-1h", "=1h", "/Alloc=1h", "gauge/[=1h", "gauge=1h,counter"} {
+		_, err := parseRetention(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRetentionJanitorExpire(t *testing.T) {
+	ctx := context.Background()
+	stg := storages.NewMemStorage()
+	gauge := metrics.NewGauge(nil)
+	counter := metrics.NewCounter(nil)
+	stg.AddMetric("gauge", gauge)
+	stg.AddMetric("counter", counter)
+
+	_ = gauge.ProcessMassive(ctx, map[string]float64{"Alloc": 1, "RandomValue": 2, `RandomValue{host="web1"}`: 3})
+	_ = counter.Process(ctx, "PollCount", "1")
+
+	history := storages.NewMemHistory(10)
+	_ = history.Record(ctx, "gauge", map[string]float64{"Alloc": 1, "RandomValue": 2}, time.Now())
+
+	policy, _ := parseRetention("gauge=1h,gauge/Random*=10m")
+	janitor := newRetentionJanitor(policy, slog.New())
+
+	janitor.expire(ctx, stg, history, time.Now().Add(5*time.Minute))
+	list, _ := gauge.GetList(ctx)
+	assert.Len(t, list, 3)
+
+	janitor.expire(ctx, stg, history, time.Now().Add(30*time.Minute))
+	list, _ = gauge.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 1}, list)
+	samples, _ := history.Range(ctx, "gauge", "RandomValue", time.Unix(0, 0), time.Now())
+	assert.Empty(t, samples)
+	samples, _ = history.Range(ctx, "gauge", "Alloc", time.Unix(0, 0), time.Now())
+	assert.Len(t, samples, 1)
+
+	janitor.expire(ctx, stg, history, time.Now().Add(2*time.Hour))
+	list, _ = gauge.GetList(ctx)
+	assert.Empty(t, list)
+
+	list, _ = counter.GetList(ctx)
+	assert.Equal(t, map[string]float64{"PollCount": 1}, list)
+}
+
+func TestUpRetention(t *testing.T) {
+	conf := getMockConf(t)
+	conf.EXPECT().GetRetention().Return("forever").Times(1)
+	conf.EXPECT().GetRetention().Return("").Times(1)
+	conf.EXPECT().GetRetention().Return("24h").Times(1)
+	conf.EXPECT().GetRetentionInterval().Return(1).AnyTimes()
+	s := &Server{
+		storage: storages.NewMemStorage(),
+		conf:    conf,
+		logger:  slog.New(),
+	}
+
+	assert.Error(t, s.upRetention(context.Background()))
+	assert.NoError(t, s.upRetention(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, s.upRetention(ctx))
+}
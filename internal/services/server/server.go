@@ -2,25 +2,46 @@ package server
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"time"
 
 	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
 	"github.com/AnatolySnegovskiy/metric/internal/repositories"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
 	"github.com/AnatolySnegovskiy/metric/internal/services/interfase"
 	"github.com/AnatolySnegovskiy/metric/internal/storages"
 	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/migrations"
 	"github.com/go-chi/chi/v5"
 	"github.com/gookit/gsr"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/tern/v2/migrate"
+	"google.golang.org/grpc"
 )
 
-var pgxConnect = pgx.Connect
+var postgresConnect = clients.NewPostgresPool
+
+// connectRetryDelay is the first delay between the attempts to connect to the database, it doubles every attempt.
+var connectRetryDelay = time.Second
+
+const (
+	// connectAttempts is the number of attempts to connect to the database on start.
+	connectAttempts = 4
+	// dbWatchInterval is the interval between the pings of a connected database.
+	dbWatchInterval = 10 * time.Second
+)
+
+// Storage backends selected by Config.GetStorage.
+const (
+	backendMemory   = "memory"
+	backendFile     = "file"
+	backendBolt     = "bolt"
+	backendPostgres = "postgres"
+)
 
 // Config defines the configuration interface for the server.
 type Config interface {
@@ -38,8 +59,44 @@ type Config interface {
 	GetShaKey() string
 	// GetMigrationsDir returns the directory path for database migrations.
 	GetMigrationsDir() string
+	// GetSkipMigrate returns a boolean indicating whether to skip migrations on start.
+	GetSkipMigrate() bool
 	// GetCryptoKey returns the path to the private key file.
 	GetCryptoKey() string
+	// GetGRPCAddress returns the gRPC server address.
+	GetGRPCAddress() string
+	// GetHistogramBuckets returns the bucket bounds of new histograms.
+	GetHistogramBuckets() []float64
+	// GetHistorySize returns the number of samples kept per series, zero keeps no history in memory.
+	GetHistorySize() int
+	// GetAlertRulesFile returns the path to the alert rules file.
+	GetAlertRulesFile() string
+	// GetAlertWebhook returns the URL alert notifications are posted to.
+	GetAlertWebhook() string
+	// GetAlertInterval returns the interval for evaluating the alert rules.
+	GetAlertInterval() int
+	// GetStorage returns the storage backend: memory, file, bolt or postgres.
+	GetStorage() string
+	// GetBoltPath returns the bolt storage path.
+	GetBoltPath() string
+	// GetDBMaxConns returns the maximum number of database connections.
+	GetDBMaxConns() int
+	// GetDBMinConns returns the minimum number of database connections.
+	GetDBMinConns() int
+	// GetDBConnectTimeout returns the database connect timeout.
+	GetDBConnectTimeout() int
+	// GetDBMaxConnIdleTime returns the maximum idle time of a database connection.
+	GetDBMaxConnIdleTime() int
+	// GetRetention returns the retention policy of the series.
+	GetRetention() string
+	// GetRetentionInterval returns the interval for expiring stale series.
+	GetRetentionInterval() int
+	// GetTenantsFile returns the path to the tenants file.
+	GetTenantsFile() string
+	// GetAdminToken returns the token of the admin endpoints.
+	GetAdminToken() string
+	// GetCacheStaleness returns the staleness bound of the database cache, zero disables it.
+	GetCacheStaleness() int
 }
 
 // Server represents the main server struct.
@@ -47,8 +104,17 @@ type Server struct {
 	storage  interfase.Storage
 	router   *chi.Mux
 	logger   gsr.GenLogger
-	dbIsOpen bool
+	db       *clients.Postgres
 	conf     Config
+	grpc     *grpc.Server
+	history  storages.History
+	alerts   map[string]*alertManager
+	snapshot *storages.FileStore
+	bolt     *clients.Bolt
+	tenants  map[string]*tenant
+	cache    map[string]*storages.CacheStats
+	// readOnly is set for a server opened to export the storage.
+	readOnly bool
 }
 
 // New creates a new server instance with the provided configuration and logger.
@@ -62,11 +128,44 @@ func New(ctx context.Context, c Config, l gsr.GenLogger) (*Server, error) {
 	return server.upServer(ctx)
 }
 
+// Open creates a server of the configured storage only, for the export and import subcommands.
+// A writable server fails to open a file or bolt storage held by a running server.
+func Open(ctx context.Context, c Config, l gsr.GenLogger, readOnly bool) (*Server, error) {
+	server := &Server{
+		router:   chi.NewRouter(),
+		logger:   l,
+		conf:     c,
+		readOnly: readOnly,
+	}
+
+	if err := server.upStorages(ctx); err != nil {
+		server.Close()
+		return nil, err
+	}
+
+	if server.snapshot == nil {
+		return server, nil
+	}
+
+	load := server.snapshot.Load
+	if readOnly {
+		load = server.snapshot.Read
+	}
+	if err := load(); err != nil {
+		server.Close()
+		return nil, err
+	}
+
+	return server, nil
+}
+
 // setupRoutes sets up the routes for handling different HTTP endpoints.
 func (s *Server) setupRoutes() {
 	// Middleware functions and handlers for routing in the server.
 	// Middleware functions:
+	// - DecryptMessageMiddleware decrypts the request body.
 	// - hashCheckMiddleware checks the hash of the request.
+	// - tenantMiddleware routes the request to the tenant of its token.
 	// - gzipCompressMiddleware compresses the response using gzip.
 	// - gzipDecompressMiddleware decompresses the request body using gzip.
 	// - logMiddleware logs request information.
@@ -76,172 +175,467 @@ func (s *Server) setupRoutes() {
 	// PostMetricHandler handles POST requests to update metrics.
 	// MassPostMetricHandler handles POST requests to update multiple metrics.
 	// ShowPostMetricHandler handles POST requests to display metrics.
+	// ShowValuesHandler and ShowPostValuesHandler handle requests to show filtered metrics.
 	// WriteGetMetricHandler handles GET requests to update a specific metric.
-	// ShowAllMetricHandler handles GET requests to show all metrics.
+	// ShowAllMetricHandler handles GET requests to show the dashboard of all metrics.
+	// DashboardAssetsHandler handles GET requests to the embedded dashboard assets.
 	// ShowMetricTypeHandler handles GET requests to show metrics of a specific type.
 	// ShowMetricNameHandlers handles GET requests to show metrics of a specific name.
+	// DeleteMetricHandler handles DELETE requests to delete a specific metric.
+	// ResetMetricHandler handles POST requests to reset a specific counter to zero.
+	// DeleteValuesHandler handles DELETE requests to delete the metrics with a name prefix.
+	// PrometheusMetricsHandler handles GET requests to show all metrics in the Prometheus format.
+	// HistoryHandler handles GET requests to show the downsampled history of a metric.
+	// AlertsHandler handles GET requests to list the current alert states.
+	// ExportHandler handles GET requests to dump every metric as JSON, NDJSON or CSV.
+	// ImportHandler handles POST requests to load a dump into the storage.
 
 	// PostgresPingHandler handles GET requests to ping the PostgreSQL database.
 
 	// Note: The router uses JSONContentTypeMiddleware for handling JSON content type in POST requests.
+	// Note: The export and import routes use adminMiddleware, which requires the admin token.
 
-	s.router.Use(s.hashCheckMiddleware, s.DecryptMessageMiddleware, s.gzipCompressMiddleware, s.gzipDecompressMiddleware, s.logMiddleware, s.hashResponseMiddleware)
+	s.router.Use(s.DecryptMessageMiddleware, s.hashCheckMiddleware, s.tenantMiddleware, s.gzipCompressMiddleware, s.gzipDecompressMiddleware, s.logMiddleware, s.hashResponseMiddleware)
 	s.router.NotFound(s.notFoundHandler)
 	s.router.With(s.JSONContentTypeMiddleware).Post("/update/", s.writePostMetricHandler)
 	s.router.With(s.JSONContentTypeMiddleware).Post("/updates/", s.writeMassPostMetricHandler)
 	s.router.With(s.JSONContentTypeMiddleware).Post("/value/", s.showPostMetricHandler)
+	s.router.With(s.JSONContentTypeMiddleware).Post("/values/", s.showPostValuesHandler)
+	s.router.Get("/values/", s.showValuesHandler)
 	s.router.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
 	s.router.Get("/", s.showAllMetricHandler)
+	s.router.Handle("/assets/*", dashboardAssetsHandler())
 	s.router.Get("/value/{metricType}", s.showMetricTypeHandler)
 	s.router.Get("/value/{metricType}/{metricName}", s.showMetricNameHandlers)
+	s.router.Delete("/value/{metricType}/{metricName}", s.deleteMetricHandler)
+	s.router.Post("/reset/{metricType}/{metricName}", s.resetMetricHandler)
+	s.router.Delete("/values/", s.deleteValuesHandler)
+	s.router.Get("/metrics", s.prometheusMetricsHandler)
+	s.router.Get("/history/{metricType}/{metricName}", s.historyHandler)
+	s.router.Get("/alerts", s.alertsHandler)
+	s.router.With(s.adminMiddleware).Get("/admin/export", s.exportHandler)
+	s.router.With(s.adminMiddleware).Post("/admin/import", s.importHandler)
 
 	s.router.Get("/ping", s.postgersPingHandler)
 }
 
 // Run starts the server and listens on the configured server address.
 func (s *Server) Run() error {
+	if s.grpc != nil {
+		listener, err := net.Listen("tcp", s.conf.GetGRPCAddress())
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := s.grpc.Serve(listener); err != nil {
+				s.logger.Error(err)
+			}
+		}()
+	}
+
 	return http.ListenAndServe(s.conf.GetServerAddress(), s.router)
 }
 
-// saveMetricsPeriodically saves metrics to a file periodically based on the interval.
-func (s *Server) saveMetricsPeriodically(ctx context.Context, interval int, filePath string) {
+// saveSnapshotPeriodically saves the file storage periodically based on the interval.
+func (s *Server) saveSnapshotPeriodically(ctx context.Context, interval int) {
 	ticker := time.NewTicker(time.Second * time.Duration(interval))
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			s.saveMetricsToFile(filePath)
+			s.saveSnapshot()
 		}
 	}
 }
 
-// loadMetricsOnStart loads metrics from a file on server start.
-func (s *Server) loadMetricsOnStart(filePath string) {
-	savedMetrics := loadMetricsFromFile(filePath)
+// saveSnapshot saves the metrics of the file storage to its snapshot file.
+func (s *Server) saveSnapshot() {
+	if s.snapshot == nil {
+		return
+	}
 
-	for metricType, metricValues := range savedMetrics {
-		metric, err := s.storage.GetMetricType(metricType)
+	if err := s.snapshot.Save(); err == nil {
+		s.logger.Info("Metrics saved: " + s.snapshot.Path())
+	}
+}
 
-		if err != nil {
+// restoreSnapshot loads the file storage on start when restore is enabled.
+func (s *Server) restoreSnapshot() error {
+	if !s.conf.GetRestore() {
+		return s.snapshot.Save()
+	}
+
+	if err := s.snapshot.Load(); err != nil {
+		return err
+	}
+	s.logger.Info("Metrics loaded: " + s.snapshot.Path())
+
+	return nil
+}
+
+// BDConnect establishes a connection to the database.
+func (s *Server) BDConnect() *clients.Postgres {
+	db, err := postgresConnect(context.Background(), s.conf.GetDataBaseDSN(), clients.PoolConfig{
+		MaxConns:        int32(s.conf.GetDBMaxConns()),
+		MinConns:        int32(s.conf.GetDBMinConns()),
+		ConnectTimeout:  time.Duration(s.conf.GetDBConnectTimeout()) * time.Second,
+		MaxConnIdleTime: time.Duration(s.conf.GetDBMaxConnIdleTime()) * time.Second,
+	})
+
+	if err != nil {
+		s.logger.Error(err)
+		return nil
+	}
+
+	db.OnRetry(func(attempt int, delay time.Duration, err error) {
+		s.logger.Warnf("postgres: attempt %d failed, retry in %s: %s", attempt, delay, err)
+	})
+
+	return db
+}
+
+// connectDB connects to the database with retries.
+func (s *Server) connectDB(attempts int) *clients.Postgres {
+	delay := connectRetryDelay
+	for attempt := 1; ; attempt++ {
+		if db := s.BDConnect(); db != nil || attempt == attempts {
+			return db
+		}
+
+		s.logger.Warnf("postgres: connection attempt %d of %d failed, retry in %s", attempt, attempts, delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// storageBackend resolves the storage backend and connects to the database when it is Postgres.
+// Without a configured backend Postgres, the file and memory are tried in that order.
+func (s *Server) storageBackend() (string, *clients.Postgres, error) {
+	switch backend := s.conf.GetStorage(); backend {
+	case backendPostgres:
+		db := s.connectDB(connectAttempts)
+		if db == nil {
+			return "", nil, errors.New("storage postgres: failed to connect to the database")
+		}
+
+		return backend, db, nil
+	case backendFile:
+		if s.conf.GetFileStoragePath() == "" {
+			return "", nil, errors.New("storage file: file storage path is empty")
+		}
+
+		return backend, nil, nil
+	case backendBolt:
+		if s.conf.GetBoltPath() == "" {
+			return "", nil, errors.New("storage bolt: bolt path is empty")
+		}
+
+		return backend, nil, nil
+	case backendMemory:
+		return backend, nil, nil
+	case "":
+	default:
+		return "", nil, fmt.Errorf("unknown storage %s", backend)
+	}
+
+	if s.conf.GetDataBaseDSN() != "" {
+		if db := s.BDConnect(); db != nil {
+			return backendPostgres, db, nil
+		}
+	}
+
+	if s.conf.GetFileStoragePath() != "" {
+		return backendFile, nil, nil
+	}
+
+	return backendMemory, nil, nil
+}
+
+// upStorage sets up the storage for metrics of every tenant on the backend.
+func (s *Server) upStorage(backend string, db *clients.Postgres) error {
+	tenants := s.tenantList()
+
+	switch backend {
+	case backendFile:
+		projectDir, _ := os.Getwd()
+		s.snapshot = storages.NewFileStore(filepath.Join(projectDir, s.conf.GetFileStoragePath()), s.conf.GetStoreInterval() == 0)
+		s.snapshot.OnError(func(err error) {
 			s.logger.Error(err)
-			continue
+		})
+		if !s.readOnly {
+			if err := s.snapshot.Lock(); err != nil {
+				return err
+			}
+		}
+	case backendBolt:
+		var buckets []string
+		for _, t := range tenants {
+			for _, metricType := range []string{"gauge", "counter", "histogram", "summary"} {
+				buckets = append(buckets, tenantSection(t.namespace(), metricType))
+			}
 		}
 
-		for _, items := range metricValues {
-			for key, value := range items {
-				_ = metric.Process(context.Background(), key, strconv.FormatFloat(value, 'f', -1, 64))
+		open := func() (*clients.Bolt, error) {
+			return clients.OpenBolt(s.conf.GetBoltPath(), buckets...)
+		}
+		if s.readOnly {
+			open = func() (*clients.Bolt, error) {
+				return clients.OpenBoltReadOnly(s.conf.GetBoltPath())
 			}
 		}
+
+		boltDB, err := open()
+		if err != nil {
+			return fmt.Errorf("storage bolt: %w", err)
+		}
+		s.bolt = boltDB
+	case backendPostgres, backendMemory:
+	default:
+		return fmt.Errorf("unknown storage %s", backend)
+	}
+
+	for _, t := range tenants {
+		s.upTenantStorage(backend, db, t)
+		if t.name == defaultTenant {
+			s.storage = t.storage
+			s.history = t.history
+			s.cache = t.cache
+		}
 	}
 
-	s.logger.Info("Metrics loaded: " + filePath)
+	return nil
 }
 
-// saveMetricsToFile saves metrics to a file at a specified path.
-func (s *Server) saveMetricsToFile(filePath string) {
-	projectDir, _ := os.Getwd()
-	absoluteFilePath := filepath.Join(projectDir, filePath)
+// upTenantStorage sets up the storage and the history of the tenant on the backend.
+func (s *Server) upTenantStorage(backend string, db *clients.Postgres, t *tenant) {
+	var gaugeBackend, counterBackend storages.Backend[float64]
+	var histogramBackend storages.Backend[dto.Histogram]
+	var summaryBackend storages.Backend[dto.Summary]
+	var history storages.History
+
+	namespace := t.namespace()
+	t.cache = nil
+	switch backend {
+	case backendPostgres:
+		gaugeRepo := repositories.NewGaugeRepo(db).WithTenant(namespace)
+		counterRepo := repositories.NewCounterRepo(db).WithTenant(namespace)
+		gaugeBackend, counterBackend = gaugeRepo, counterRepo
+		if staleness := time.Duration(s.conf.GetCacheStaleness()) * time.Second; staleness > 0 {
+			t.cache = map[string]*storages.CacheStats{"gauge": {}, "counter": {}}
+			gaugeBackend = storages.NewCachedBackend[float64](gaugeRepo, staleness, t.cache["gauge"])
+			counterBackend = storages.NewCachedIncrementer(counterRepo, staleness, t.cache["counter"])
+		}
+		histogramBackend = repositories.NewHistogramRepo(db).WithTenant(namespace)
+		summaryBackend = repositories.NewSummaryRepo(db).WithTenant(namespace)
+		history = repositories.NewSampleRepo(db).WithTenant(namespace)
+	case backendFile:
+		gaugeBackend = storages.NewFileBackend[float64](s.snapshot, tenantSection(namespace, "gauge"))
+		counterBackend = storages.NewFileBackend[float64](s.snapshot, tenantSection(namespace, "counter"))
+		histogramBackend = storages.NewFileBackend[dto.Histogram](s.snapshot, tenantSection(namespace, "histogram"))
+		summaryBackend = storages.NewFileBackend[dto.Summary](s.snapshot, tenantSection(namespace, "summary"))
+	case backendBolt:
+		gaugeBackend = repositories.NewBoltRepo[float64](s.bolt, tenantSection(namespace, "gauge"))
+		counterBackend = repositories.NewBoltRepo[float64](s.bolt, tenantSection(namespace, "counter"))
+		histogramBackend = repositories.NewBoltRepo[dto.Histogram](s.bolt, tenantSection(namespace, "histogram"))
+		summaryBackend = repositories.NewBoltRepo[dto.Summary](s.bolt, tenantSection(namespace, "summary"))
+	}
+
+	if size := s.conf.GetHistorySize(); history == nil && size > 0 {
+		history = storages.NewMemHistory(size)
+	}
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(gaugeBackend))
+	stg.AddMetric("counter", metrics.NewCounter(counterBackend))
+	stg.AddMetric("histogram", metrics.NewHistogram(histogramBackend, s.conf.GetHistogramBuckets()))
+	stg.AddMetric("summary", metrics.NewSummary(summaryBackend))
+	t.storage = stg
+	t.history = history
+}
+
+// upMigrate runs database migrations for the connected database unless they are skipped.
+func (s *Server) upMigrate(ctx context.Context, db *clients.Postgres) error {
+	if db == nil {
+		return nil
+	}
+
+	if s.conf.GetSkipMigrate() {
+		s.logger.Info("Migrations skipped")
+		return nil
+	}
+
+	return db.AcquireConn(ctx, func(conn *pgx.Conn) error {
+		migrator, err := migrations.NewMigrator(ctx, conn, migrations.Source(s.conf.GetMigrationsDir()))
+		if err != nil {
+			return err
+		}
 
-	directory := filepath.Dir(absoluteFilePath)
-	_ = os.MkdirAll(directory, os.ModePerm)
+		return migrator.Migrate(ctx)
+	})
+}
 
-	file, _ := os.Create(absoluteFilePath)
-	defer file.Close()
-	jsonData, _ := json.Marshal(s.storage.GetList())
+// watchDB logs the lost and restored connections of the database until the context is done.
+func (s *Server) watchDB(ctx context.Context) {
+	s.db.Watch(ctx, dbWatchInterval, func(err error) {
+		if err != nil {
+			s.logger.Error(err)
+			return
+		}
 
-	_, _ = file.Write(jsonData)
-	s.logger.Info("Metrics saved: " + absoluteFilePath)
+		s.logger.Info("postgres: connection restored")
+	})
 }
 
-// BDConnect establishes a connection to the database.
-func (s *Server) BDConnect() *pgx.Conn {
-	db, err := pgxConnect(context.Background(), s.conf.GetDataBaseDSN())
+// upAlerts starts evaluating the alert rules for every tenant.
+func (s *Server) upAlerts(ctx context.Context) error {
+	rulesFile := s.conf.GetAlertRulesFile()
+	if rulesFile == "" {
+		return nil
+	}
 
+	rules, err := loadAlertRules(rulesFile)
 	if err != nil {
-		s.logger.Error(err)
+		return err
 	}
 
-	return db
+	s.alerts = make(map[string]*alertManager)
+	for _, t := range s.tenantList() {
+		m := newAlertManager(t.namespace(), rules, s.conf.GetAlertWebhook(), s.logger)
+		s.alerts[t.name] = m
+		go m.run(ctx, t.storage, time.Duration(s.conf.GetAlertInterval())*time.Second)
+	}
+
+	return nil
 }
 
-// upStorage sets up the storage for metrics based on the database connection.
-func (s *Server) upStorage(db *pgx.Conn) error {
-	var gaugeRepo *repositories.GaugeRepo
-	var counterRepo *repositories.CounterRepo
+// upRetention starts expiring the stale series of every tenant.
+func (s *Server) upRetention(ctx context.Context) error {
+	spec := s.conf.GetRetention()
+	if spec == "" {
+		return nil
+	}
 
-	if db != nil {
-		pg := clients.NewPostgres(db)
-		gaugeRepo = repositories.NewGaugeRepo(pg)
-		counterRepo = repositories.NewCounterRepo(pg)
+	policy, err := parseRetention(spec)
+	if err != nil {
+		return err
 	}
 
-	stg := storages.NewMemStorage()
-	stg.AddMetric("gauge", metrics.NewGauge(gaugeRepo))
-	stg.AddMetric("counter", metrics.NewCounter(counterRepo))
-	s.storage = stg
+	janitor := newRetentionJanitor(policy, s.logger)
+	go janitor.run(ctx, s.tenantList(), time.Duration(s.conf.GetRetentionInterval())*time.Second)
 
 	return nil
 }
 
-// upMigrate runs database migrations for the connected database.
-func (s *Server) upMigrate(ctx context.Context, db *pgx.Conn) error {
-	if db == nil {
-		return nil
+// upHistoryPruning starts capping the histories kept in the database at the history size.
+func (s *Server) upHistoryPruning(ctx context.Context) {
+	size := s.conf.GetHistorySize()
+	if size <= 0 {
+		return
+	}
+
+	var histories []storages.PrunableHistory
+	for _, t := range s.tenantList() {
+		if history, ok := t.history.(storages.PrunableHistory); ok {
+			histories = append(histories, history)
+		}
+	}
+	if len(histories) == 0 {
+		return
 	}
 
-	migration, _ := migrate.NewMigrator(ctx, db, "public.schema_version")
-	if err := migration.LoadMigrations(os.DirFS(s.conf.GetMigrationsDir())); err != nil {
+	interval := time.Duration(s.conf.GetRetentionInterval()) * time.Second
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+	go s.pruneHistory(ctx, histories, size, interval)
+}
+
+// upStorages loads the tenants, connects to the database and sets up the storages.
+func (s *Server) upStorages(ctx context.Context) error {
+	if err := s.upTenants(); err != nil {
 		return err
 	}
 
-	if err := migration.Migrate(ctx); err != nil {
+	backend, db, err := s.storageBackend()
+	if err != nil {
 		return err
 	}
+	s.db = db
 
-	return nil
+	if !s.readOnly {
+		if err := s.upMigrate(ctx, db); err != nil {
+			return err
+		}
+	}
+
+	return s.upStorage(backend, db)
 }
 
-// upServer initializes the server by connecting to the database, setting up migrations, storage, and routes.
+// upServer initializes the server by setting up the storages, background jobs and routes.
 func (s *Server) upServer(ctx context.Context) (*Server, error) {
-	db := s.BDConnect()
-	s.dbIsOpen = db != nil
-
-	if err := s.upMigrate(ctx, db); err != nil {
+	if err := s.upStorages(ctx); err != nil {
 		return nil, err
 	}
-	if err := s.upStorage(db); err != nil {
-		return nil, err
+	if s.db != nil {
+		go s.watchDB(ctx)
 	}
 
-	fileStorage := s.conf.GetFileStoragePath()
+	if s.snapshot != nil {
+		if err := s.restoreSnapshot(); err != nil {
+			return nil, err
+		}
 
-	if s.conf.GetRestore() {
-		s.loadMetricsOnStart(fileStorage)
+		if interval := s.conf.GetStoreInterval(); interval > 0 {
+			go s.saveSnapshotPeriodically(ctx, interval)
+		}
 	}
 
-	go s.saveMetricsPeriodically(ctx, s.conf.GetStoreInterval(), s.conf.GetFileStoragePath())
+	if err := s.upAlerts(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.upRetention(ctx); err != nil {
+		return nil, err
+	}
+	s.upHistoryPruning(ctx)
 
 	s.setupRoutes()
 
+	if s.conf.GetGRPCAddress() != "" {
+		s.grpc = s.newGRPCServer()
+	}
+
 	return s, nil
 }
 
-// ShotDown saves metrics to a file before shutting down the server.
+// ShotDown saves metrics and closes the storages before shutting down the server.
 func (s *Server) ShotDown() {
-	s.saveMetricsToFile(s.conf.GetFileStoragePath())
+	if s.grpc != nil {
+		s.grpc.GracefulStop()
+	}
+	s.saveSnapshot()
+	s.Close()
 }
 
-// loadMetricsFromFile loads metrics from a file at the specified path and returns them as a map.
-func loadMetricsFromFile(filePath string) map[string]map[string]map[string]float64 {
-	projectDir, _ := os.Getwd()
-	absoluteFilePath := filepath.Join(projectDir, filePath)
-	file, _ := os.Open(absoluteFilePath)
-	defer file.Close()
+// Close closes the storages without saving metrics.
+func (s *Server) Close() {
+	if s.snapshot != nil {
+		if err := s.snapshot.Close(); err != nil {
+			s.logger.Error(err)
+		}
+	}
 
-	var metrics map[string]map[string]map[string]float64
-	decoder := json.NewDecoder(file)
-	_ = decoder.Decode(&metrics)
+	if s.bolt != nil {
+		if err := s.bolt.Close(); err != nil {
+			s.logger.Error(err)
+		}
+	}
 
-	return metrics
+	if s.db != nil {
+		s.db.Close()
+	}
 }
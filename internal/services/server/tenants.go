@@ -0,0 +1,397 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/interfase"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// defaultTenant is the tenant of the requests without tenant credentials.
+// Its series are stored without a namespace, so the metrics stored before tenants were configured stay visible to it.
+const defaultTenant = "default"
+
+// tenantNamePattern restricts tenant names to the ones safe to use as storage namespaces.
+var tenantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// tenantConfig is an entry of the tenants file.
+type tenantConfig struct {
+	Name      string `json:"name"`
+	Key       string `json:"key"`
+	Token     string `json:"token"`
+	MaxSeries int    `json:"max_series"`
+}
+
+// tenant is an isolated namespace of metrics with its own credentials and series quota.
+type tenant struct {
+	name      string
+	key       string
+	token     string
+	maxSeries int
+	storage   interfase.Storage
+	history   storages.History
+	// cache counts the reads of the tenant served by the cache in front of the database, it is nil when the cache is disabled.
+	cache map[string]*storages.CacheStats
+	// quota guards series: the writes of known series share it, the writes that may add series hold it alone.
+	quota sync.RWMutex
+	// series holds the keys by metric type of the series the tenant may have stored, it is nil until they are counted.
+	// It covers every stored series and stays within the quota, so a write of known series can't exceed the quota.
+	series map[string]map[string]bool
+}
+
+// knows reports whether every series of the keys by metric type is known to the tenant.
+func (t *tenant) knows(keys map[string][]string) bool {
+	if t.series == nil {
+		return false
+	}
+
+	for metricType, typeKeys := range keys {
+		for _, key := range typeKeys {
+			if !t.series[metricType][key] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// namespace returns the storage namespace of the tenant, it is empty for the default tenant.
+func (t *tenant) namespace() string {
+	if t.name == defaultTenant {
+		return ""
+	}
+
+	return t.name
+}
+
+// tenantSection returns the name of the file snapshot section or the bolt bucket of the metric type in the namespace.
+func tenantSection(namespace string, metricType string) string {
+	if namespace == "" {
+		return metricType
+	}
+
+	return namespace + "/" + metricType
+}
+
+// loadTenants reads the tenants file, a JSON array such as [{"name":"team-a","token":"...","max_series":1000}].
+func loadTenants(filePath string) (map[string]*tenant, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []tenantConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("tenants %s: %w", filePath, err)
+	}
+
+	return parseTenants(configs)
+}
+
+// parseTenants checks the tenant entries and adds the default tenant when it isn't listed.
+// Every other tenant needs an HMAC key or a token, keys and tokens can't be shared by tenants.
+// The default tenant is reached without credentials, so it can only have a token and a quota, its key is the KEY of the server.
+func parseTenants(configs []tenantConfig) (map[string]*tenant, error) {
+	tenants := make(map[string]*tenant, len(configs)+1)
+	keys := make(map[string]bool)
+	tokens := make(map[string]bool)
+
+	for i, config := range configs {
+		if !tenantNamePattern.MatchString(config.Name) {
+			return nil, fmt.Errorf("tenant %d: invalid name %q", i+1, config.Name)
+		}
+		if _, ok := tenants[config.Name]; ok {
+			return nil, fmt.Errorf("tenant %s: duplicate name", config.Name)
+		}
+		if config.MaxSeries < 0 {
+			return nil, fmt.Errorf("tenant %s: negative max_series", config.Name)
+		}
+		if config.Name == defaultTenant && config.Key != "" {
+			return nil, fmt.Errorf("tenant %s: the key of the default tenant is the server key", config.Name)
+		}
+		if config.Name != defaultTenant && config.Key == "" && config.Token == "" {
+			return nil, fmt.Errorf("tenant %s: key or token required", config.Name)
+		}
+		if config.Key != "" {
+			if keys[config.Key] {
+				return nil, fmt.Errorf("tenant %s: key is used by another tenant", config.Name)
+			}
+			keys[config.Key] = true
+		}
+		if config.Token != "" {
+			if tokens[config.Token] {
+				return nil, fmt.Errorf("tenant %s: token is used by another tenant", config.Name)
+			}
+			tokens[config.Token] = true
+		}
+
+		tenants[config.Name] = &tenant{
+			name:      config.Name,
+			key:       config.Key,
+			token:     config.Token,
+			maxSeries: config.MaxSeries,
+		}
+	}
+
+	if _, ok := tenants[defaultTenant]; !ok {
+		tenants[defaultTenant] = &tenant{name: defaultTenant}
+	}
+
+	return tenants, nil
+}
+
+// upTenants loads the tenants when a tenants file is configured, otherwise every request belongs to the default tenant.
+func (s *Server) upTenants() error {
+	filePath := s.conf.GetTenantsFile()
+	if filePath == "" {
+		return nil
+	}
+
+	tenants, err := loadTenants(filePath)
+	if err != nil {
+		return err
+	}
+	s.tenants = tenants
+
+	return nil
+}
+
+// tenantList returns the tenants sorted by name, only the default tenant when no tenants are configured.
+func (s *Server) tenantList() []*tenant {
+	if s.tenants == nil {
+		return []*tenant{s.tenantOf(context.Background())}
+	}
+
+	list := make([]*tenant, 0, len(s.tenants))
+	for _, t := range s.tenants {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+
+	return list
+}
+
+type tenantContextKey struct{}
+
+// withTenant returns a copy of the context that carries the tenant.
+func withTenant(ctx context.Context, t *tenant) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, t)
+}
+
+// tenantOf returns the tenant of the context, the default tenant when the context carries none.
+func (s *Server) tenantOf(ctx context.Context) *tenant {
+	if t, ok := ctx.Value(tenantContextKey{}).(*tenant); ok {
+		return t
+	}
+	if t, ok := s.tenants[defaultTenant]; ok {
+		return t
+	}
+
+	return &tenant{name: defaultTenant, storage: s.storage, history: s.history, cache: s.cache}
+}
+
+// storageOf returns the storage of the tenant of the context.
+func (s *Server) storageOf(ctx context.Context) interfase.Storage {
+	return s.tenantOf(ctx).storage
+}
+
+// WithTenant returns a copy of the context whose metrics are read and written in the namespace of the tenant.
+func (s *Server) WithTenant(ctx context.Context, name string) (context.Context, error) {
+	if name == "" || name == defaultTenant {
+		return ctx, nil
+	}
+
+	t, ok := s.tenants[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tenant %s", name)
+	}
+
+	return withTenant(ctx, t), nil
+}
+
+// errUnknownToken is returned for a bearer token that doesn't belong to any tenant.
+var errUnknownToken = errors.New("unknown token")
+
+// tokenTenant returns the tenant of the bearer token.
+func (s *Server) tokenTenant(token string) (*tenant, error) {
+	for _, t := range s.tenants {
+		if t.token != "" && subtle.ConstantTimeCompare([]byte(t.token), []byte(token)) == 1 {
+			return t, nil
+		}
+	}
+
+	return nil, errUnknownToken
+}
+
+// errBadHash is returned for a hash that matches neither the server key nor the key of a tenant.
+var errBadHash = errors.New("bad hash value")
+
+// hashKeys reports whether a key to check the hashes with is set, on the server or on a tenant.
+func (s *Server) hashKeys() bool {
+	if s.conf.GetShaKey() != "" {
+		return true
+	}
+	for _, t := range s.tenants {
+		if t.key != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hashTenant returns the tenant whose key signs the body with the hash, the default tenant when the server key signs it.
+// The default tenant is nil when no tenants are configured.
+func (s *Server) hashTenant(hash string, body []byte) (*tenant, error) {
+	for _, t := range s.tenants {
+		if t.key != "" && validHash(t.key, hash, body) {
+			return t, nil
+		}
+	}
+
+	if key := s.conf.GetShaKey(); key != "" && validHash(key, hash, body) {
+		return s.tenants[defaultTenant], nil
+	}
+
+	return nil, errBadHash
+}
+
+// validHash reports whether the hash is the hex HMAC-SHA256 of the body with the key.
+func validHash(key, hash string, body []byte) bool {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(body)
+
+	return hmac.Equal([]byte(hash), []byte(fmt.Sprintf("%x", mac.Sum(nil))))
+}
+
+// tenantMiddleware routes the request to the tenant of its bearer token.
+// A request with an unknown bearer token is rejected, a request without a token keeps the tenant of the key that signs it,
+// see hashCheckMiddleware, or else belongs to the default tenant.
+func (s *Server) tenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.tenants == nil || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		t, err := s.tokenTenant(token)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(withTenant(r.Context(), t)))
+	})
+}
+
+// tenantInterceptor routes the gRPC call to the tenant of the bearer token in the authorization metadata.
+func (s *Server) tenantInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if s.tenants == nil {
+		return handler(ctx, req)
+	}
+
+	t := s.tenants[defaultTenant]
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if !ok {
+			continue
+		}
+
+		var err error
+		if t, err = s.tokenTenant(token); err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		break
+	}
+
+	return handler(withTenant(ctx, t), req)
+}
+
+// seriesQuotaError reports a write that would create more series than the quota of the tenant allows.
+type seriesQuotaError struct {
+	tenant    string
+	maxSeries int
+}
+
+func (e *seriesQuotaError) Error() string {
+	return fmt.Sprintf("tenant %s: quota of %d series exceeded", e.tenant, e.maxSeries)
+}
+
+// withQuota runs the write of the series keyed by metric type for the tenant of the context.
+// When the tenant has a quota a write of known series runs alongside the other ones without counting the stored series.
+// A write that may add series runs alone after the stored series are counted, it fails when it would create series above the quota.
+// The series written by replace are the only ones left afterwards, otherwise they are added to the stored ones.
+// A write that doesn't add series always passes, so a lowered quota doesn't stop the updates of the existing series.
+func (s *Server) withQuota(ctx context.Context, keys map[string][]string, replace bool, write func() error) error {
+	t := s.tenantOf(ctx)
+	if t.maxSeries <= 0 {
+		return write()
+	}
+
+	if !replace {
+		t.quota.RLock()
+		if t.knows(keys) {
+			defer t.quota.RUnlock()
+			return write()
+		}
+		t.quota.RUnlock()
+	}
+
+	t.quota.Lock()
+	defer t.quota.Unlock()
+
+	series := make(map[string]map[string]bool)
+	before, after := 0, 0
+	for metricType, entity := range t.storage.GetList() {
+		stored, err := entity.GetList(ctx)
+		if err != nil {
+			return err
+		}
+		before += len(stored)
+
+		typeSeries := make(map[string]bool, len(stored)+len(keys[metricType]))
+		if !replace {
+			for key := range stored {
+				typeSeries[key] = true
+			}
+		}
+		for _, key := range keys[metricType] {
+			typeSeries[key] = true
+		}
+		series[metricType] = typeSeries
+		after += len(typeSeries)
+	}
+
+	if after > t.maxSeries && after > before {
+		return &seriesQuotaError{tenant: t.name, maxSeries: t.maxSeries}
+	}
+
+	if err := write(); err != nil {
+		t.series = nil
+		return err
+	}
+	t.series = series
+
+	return nil
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/mocks"
+	pb "github.com/AnatolySnegovskiy/metric/internal/proto"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newTenantTestServer returns a memory server of the key "key" with the tenants team-a of a token and team-b of a key limited to two series.
+func newTenantTestServer(t *testing.T) *Server {
+	conf := getMockConf(t)
+	conf.EXPECT().GetHistogramBuckets().Return(nil).AnyTimes()
+	conf.EXPECT().GetHistorySize().Return(10).AnyTimes()
+	conf.EXPECT().GetShaKey().Return("key").AnyTimes()
+
+	tenants, err := parseTenants([]tenantConfig{
+		{Name: "team-a", Token: "token-a"},
+		{Name: "team-b", Key: "key-b", MaxSeries: 2},
+	})
+	assert.NoError(t, err)
+
+	s := &Server{
+		conf:    conf,
+		logger:  slog.New(),
+		tenants: tenants,
+	}
+	assert.NoError(t, s.upStorage(backendMemory, nil))
+
+	return s
+}
+
+// signBody returns the hash of the body the agent sends with the key.
+func signBody(key string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(body)
+	return fmt.Sprintf("%x", mac.Sum(nil))
+}
+
+func TestParseTenants(t *testing.T) {
+	tenants, err := parseTenants([]tenantConfig{{Name: "team-a", Token: "token-a", MaxSeries: 10}, {Name: "default", Token: "token-default"}})
+	assert.NoError(t, err)
+	assert.Len(t, tenants, 2)
+	assert.Equal(t, 10, tenants["team-a"].maxSeries)
+	assert.Equal(t, "team-a", tenants["team-a"].namespace())
+	assert.Equal(t, "", tenants[defaultTenant].namespace())
+
+	tenants, err = parseTenants(nil)
+	assert.NoError(t, err)
+	assert.Contains(t, tenants, defaultTenant)
+
+	for _, configs := range [][]tenantConfig{
+		{{Name: "Team A", Token: "token-a"}},
+		{{Name: "team-a/gauge", Token: "token-a"}},
+		{{Name: "team-a"}},
+		{{Name: "team-a", Token: "token-a", MaxSeries: -1}},
+		{{Name: "team-a", Token: "token-a"}, {Name: "team-a", Token: "token-b"}},
+		{{Name: "team-a", Token: "token-a"}, {Name: "team-b", Token: "token-a"}},
+		{{Name: "team-a", Key: "key"}, {Name: "team-b", Key: "key"}},
+		{{Name: "default", Key: "key"}},
+	} {
+		_, err := parseTenants(configs)
+		assert.Error(t, err, configs)
+	}
+}
+
+func TestUpTenants(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "tenants.json")
+	conf := getMockConf(t)
+	conf.EXPECT().GetTenantsFile().Return("").Times(1)
+	conf.EXPECT().GetTenantsFile().Return(filePath).AnyTimes()
+	s := &Server{conf: conf, logger: slog.New()}
+
+	assert.NoError(t, s.upTenants())
+	assert.Nil(t, s.tenants)
+
+	assert.Error(t, s.upTenants())
+
+	assert.NoError(t, os.WriteFile(filePath, []byte(`{`), 0600))
+	assert.Error(t, s.upTenants())
+
+	assert.NoError(t, os.WriteFile(filePath, []byte(`[{"name":"team-a","token":"token-a","max_series":5}]`), 0600))
+	assert.NoError(t, s.upTenants())
+	assert.Equal(t, 5, s.tenants["team-a"].maxSeries)
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	s := newTenantTestServer(t)
+	r := chi.NewRouter()
+	r.Use(s.hashCheckMiddleware, s.tenantMiddleware)
+	r.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
+	r.Post("/updates/", s.writeMassPostMetricHandler)
+	r.Get("/value/{metricType}/{metricName}", s.showMetricNameHandlers)
+
+	tokenA := map[string]string{"Authorization": "Bearer token-a"}
+	testHandler(t, r, http.MethodPost, "/update/gauge/Alloc/1", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/gauge/Alloc/2", http.StatusOK, "", nil, tokenA)
+	testHandler(t, r, http.MethodPost, "/update/gauge/Alloc/3", http.StatusUnauthorized, "unknown token\n", nil, map[string]string{"Authorization": "Bearer token-b"})
+
+	body := []byte(`[{"id":"Alloc","type":"gauge","value":4}]`)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", body, map[string]string{"Content-Type": "application/json", "HashSHA256": signBody("key-b", body)})
+	body = []byte(`[{"id":"Alloc","type":"gauge","value":5}]`)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", body, map[string]string{"Content-Type": "application/json", "HashSHA256": signBody("key", body)})
+	body = []byte(`[{"id":"Alloc","type":"gauge","value":6}]`)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusUnauthorized, "bad hash value\n", body, map[string]string{"Content-Type": "application/json", "HashSHA256": signBody("unknown", body)})
+
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc", http.StatusOK, "5", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc", http.StatusOK, "2", nil, tokenA)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Alloc", http.StatusOK, "5", nil, map[string]string{"Authorization": "Basic dXNlcjpwYXNz"})
+
+	gauge, _ := s.tenants["team-b"].storage.GetMetricType("gauge")
+	list, _ := gauge.GetList(context.Background())
+	assert.Equal(t, map[string]float64{"Alloc": 4}, list)
+
+	history, _ := s.tenants["team-a"].history.Range(context.Background(), "gauge", "Alloc", time.Unix(0, 0), time.Now())
+	assert.Len(t, history, 1)
+}
+
+func TestTenantQuota(t *testing.T) {
+	s := newTenantTestServer(t)
+	r := chi.NewRouter()
+	r.Use(s.hashCheckMiddleware, s.tenantMiddleware)
+	r.Post("/update/", s.writePostMetricHandler)
+	r.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
+	r.Post("/updates/", s.writeMassPostMetricHandler)
+	r.Post("/admin/import", s.importHandler)
+
+	signed := func(body []byte) map[string]string {
+		return map[string]string{"Content-Type": "application/json", "HashSHA256": signBody("key-b", body)}
+	}
+
+	body := []byte(`[{"id":"Alloc","type":"gauge","value":1},{"id":"PollCount","type":"counter","delta":1}]`)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", body, signed(body))
+
+	body = []byte(`[{"id":"Alloc","type":"gauge","value":2},{"id":"HeapAlloc","type":"gauge","value":1}]`)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusForbidden, `{"error":"tenant team-b: quota of 2 series exceeded"}`, body, signed(body))
+
+	body = []byte(`{"id":"HeapAlloc","type":"gauge","value":1}`)
+	testHandler(t, r, http.MethodPost, "/update/", http.StatusForbidden, `{"error":"tenant team-b: quota of 2 series exceeded"}`, body, signed(body))
+	testHandler(t, r, http.MethodPost, "/update/counter/Requests/1", http.StatusForbidden, "tenant team-b: quota of 2 series exceeded\n", []byte{}, signed(nil))
+
+	body = []byte(`{"id":"PollCount","type":"counter","delta":2}`)
+	testHandler(t, r, http.MethodPost, "/update/", http.StatusOK, "skip", body, signed(body))
+
+	body = []byte(`[{"id":"HeapAlloc","type":"gauge","value":1},{"id":"Requests","type":"counter","delta":1},{"id":"Alloc","type":"gauge","value":3}]`)
+	testHandler(t, r, http.MethodPost, "/admin/import?mode=replace", http.StatusForbidden, "skip", body, signed(body))
+	body = []byte(`[{"id":"HeapAlloc","type":"gauge","value":1},{"id":"Requests","type":"counter","delta":1}]`)
+	testHandler(t, r, http.MethodPost, "/admin/import?mode=replace", http.StatusOK, `{"imported":2}`, body, signed(body))
+
+	stgList := s.tenants["team-b"].storage.GetList()
+	gauges, _ := stgList["gauge"].GetList(context.Background())
+	counters, _ := stgList["counter"].GetList(context.Background())
+	assert.Equal(t, map[string]float64{"HeapAlloc": 1}, gauges)
+	assert.Equal(t, map[string]float64{"Requests": 1}, counters)
+
+	body = []byte(`[{"id":"Alloc","type":"gauge","value":1},{"id":"HeapAlloc","type":"gauge","value":2}]`)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", body, map[string]string{"Content-Type": "application/json"})
+}
+
+func TestTenantQuotaKnownSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	gauge := mocks.NewMockEntityMetric(ctrl)
+	gomock.InOrder(
+		gauge.EXPECT().GetList(gomock.Any()).Return(map[string]float64{"Alloc": 1}, nil),
+		gauge.EXPECT().GetList(gomock.Any()).Return(map[string]float64{"Alloc": 1}, nil),
+		gauge.EXPECT().GetList(gomock.Any()).Return(map[string]float64{"Alloc": 1, "HeapAlloc": 1}, nil),
+		gauge.EXPECT().GetList(gomock.Any()).Return(map[string]float64{"HeapAlloc": 1}, nil),
+	)
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", gauge)
+	team := &tenant{name: "team-b", maxSeries: 2, storage: stg}
+	s := &Server{tenants: map[string]*tenant{"team-b": team}}
+	ctx := withTenant(context.Background(), team)
+	write := func() error { return nil }
+	var quotaErr *seriesQuotaError
+
+	assert.NoError(t, s.withQuota(ctx, map[string][]string{"gauge": {"Alloc"}}, false, write))
+	assert.NoError(t, s.withQuota(ctx, map[string][]string{"gauge": {"Alloc"}}, false, write))
+	assert.NoError(t, s.withQuota(ctx, map[string][]string{"gauge": {"HeapAlloc"}}, false, write))
+	assert.NoError(t, s.withQuota(ctx, map[string][]string{"gauge": {"Alloc", "HeapAlloc"}}, false, write))
+	assert.ErrorAs(t, s.withQuota(ctx, map[string][]string{"gauge": {"RandomValue"}}, false, write), &quotaErr)
+
+	assert.Error(t, s.withQuota(ctx, map[string][]string{"gauge": {"RandomValue"}}, false, func() error {
+		return errors.New("write failed")
+	}))
+	assert.Nil(t, team.series)
+}
+
+func TestTenantStorageSections(t *testing.T) {
+	pathName := "tenants-test-metrics.json"
+	defer os.Remove(pathName)
+	defer os.Remove(pathName + ".wal")
+	defer os.Remove(pathName + ".lock")
+
+	tenants, err := parseTenants([]tenantConfig{{Name: "team-a", Token: "token-a"}})
+	assert.NoError(t, err)
+	s := newFileStorageServer(t, pathName, 10)
+	s.Close()
+	s.tenants = tenants
+	assert.NoError(t, s.upStorage(backendFile, nil))
+
+	ctx, err := s.WithTenant(context.Background(), "team-a")
+	assert.NoError(t, err)
+	_ = s.storageOf(ctx).GetList()["gauge"].Process(ctx, "Alloc", "1")
+	_ = s.storage.GetList()["gauge"].Process(ctx, "Alloc", "2")
+	assert.NoError(t, s.snapshot.Save())
+
+	data, err := os.ReadFile(s.snapshot.Path())
+	assert.NoError(t, err)
+	var snapshot map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(data, &snapshot))
+	assert.Contains(t, snapshot, "gauge")
+	assert.Contains(t, snapshot, "team-a/gauge")
+	assert.True(t, strings.Contains(string(snapshot["team-a/gauge"]), `"Alloc":1`))
+	s.Close()
+
+	_, err = s.WithTenant(context.Background(), "team-b")
+	assert.Error(t, err)
+	ctx, err = s.WithTenant(context.Background(), defaultTenant)
+	assert.NoError(t, err)
+	assert.Equal(t, s.storage, s.storageOf(ctx))
+
+	boltPath := filepath.Join(t.TempDir(), "metrics.bolt")
+	conf := getMockConf(t)
+	conf.EXPECT().GetBoltPath().Return(boltPath).AnyTimes()
+	conf.EXPECT().GetHistogramBuckets().Return(nil).AnyTimes()
+	conf.EXPECT().GetHistorySize().Return(0).AnyTimes()
+	boltServer := &Server{conf: conf, logger: slog.New(), tenants: tenants}
+	assert.NoError(t, boltServer.upStorage(backendBolt, nil))
+	defer boltServer.Close()
+
+	_ = boltServer.tenants["team-a"].storage.GetList()["counter"].Process(ctx, "PollCount", "3")
+	counters, _ := boltServer.storage.GetList()["counter"].GetList(ctx)
+	assert.Empty(t, counters)
+	counters, _ = boltServer.tenants["team-a"].storage.GetList()["counter"].GetList(ctx)
+	assert.Equal(t, map[string]float64{"PollCount": 3}, counters)
+}
+
+func TestTenantInterceptor(t *testing.T) {
+	s := newTenantTestServer(t)
+	handler := func(ctx context.Context, _ any) (any, error) {
+		return s.tenantOf(ctx).name, nil
+	}
+	call := func(md metadata.MD) (any, error) {
+		return s.tenantInterceptor(metadata.NewIncomingContext(context.Background(), md), nil, &grpc.UnaryServerInfo{}, handler)
+	}
+
+	name, err := call(metadata.Pairs("authorization", "Bearer token-a"))
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", name)
+
+	name, err = call(metadata.MD{})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTenant, name)
+
+	_, err = call(metadata.Pairs("authorization", "Bearer token-b"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	service := &metricsService{server: s}
+	ctx := withTenant(context.Background(), s.tenants["team-b"])
+	_ = s.tenants["team-b"].storage.GetList()["gauge"].ProcessMassive(ctx, map[string]float64{"Alloc": 1, "HeapAlloc": 2})
+	_, err = service.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: pb.FromCollection(dto.MetricsCollection{{ID: "Requests", MType: "gauge", Value: new(float64)}})})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,366 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+)
+
+type metricTypeNotFoundError struct {
+	metricType string
+}
+
+func (e *metricTypeNotFoundError) Error() string {
+	return fmt.Sprintf("metric type %s not found", e.metricType)
+}
+
+// metricValueError reports a metric whose payload can't be applied to its metric type.
+type metricValueError struct {
+	err error
+}
+
+func (e *metricValueError) Error() string {
+	return fmt.Sprintf("invalid metric value: %s", e.err.Error())
+}
+
+func (e *metricValueError) Unwrap() error {
+	return e.err
+}
+
+// updateMetrics groups the collection by metric type and stores every group with a single call.
+// Metrics are keyed by name and label set, deltas of the same counter are summed up, for gauges the last value wins,
+// histogram and summary observations of the same metric are merged together.
+// Every metric is checked while the groups are built, so a batch with an invalid metric doesn't change the storage.
+func (s *Server) updateMetrics(ctx context.Context, collection dto.MetricsCollection) error {
+	storage := s.storageOf(ctx)
+	list := make(map[string]map[string]float64)
+	histograms := make(map[string]map[string]dto.Histogram)
+	summaries := make(map[string]map[string]dto.Summary)
+
+	for _, metricDTO := range collection {
+		entity, err := storage.GetMetricType(metricDTO.MType)
+		if err != nil {
+			return &metricTypeNotFoundError{metricType: metricDTO.MType}
+		}
+
+		if err := dto.ValidateMetricName(metricDTO.ID); err != nil {
+			return &metricValueError{err: err}
+		}
+		if err := dto.ValidateLabels(metricDTO.Labels); err != nil {
+			return &metricValueError{err: fmt.Errorf("metric %s: %w", metricDTO.ID, err)}
+		}
+		key := dto.SeriesKey(metricDTO.ID, metricDTO.Labels)
+
+		if histogram, ok := entity.(storages.HistogramMetric); ok {
+			if histograms[metricDTO.MType] == nil {
+				histograms[metricDTO.MType] = make(map[string]dto.Histogram)
+			}
+
+			if err := addObservations(histograms[metricDTO.MType], key, histogram.Bounds(), metricDTO); err != nil {
+				return &metricValueError{err: fmt.Errorf("metric %s: %w", metricDTO.ID, err)}
+			}
+			continue
+		}
+
+		if summary, ok := entity.(storages.SummaryMetric); ok {
+			if summaries[metricDTO.MType] == nil {
+				summaries[metricDTO.MType] = make(map[string]dto.Summary)
+			}
+
+			if err := addSketchObservations(summaries[metricDTO.MType], key, summary.RelativeAccuracy(), metricDTO); err != nil {
+				return &metricValueError{err: fmt.Errorf("metric %s: %w", metricDTO.ID, err)}
+			}
+			continue
+		}
+
+		if list[metricDTO.MType] == nil {
+			list[metricDTO.MType] = make(map[string]float64)
+		}
+
+		if metricDTO.Delta != nil {
+			list[metricDTO.MType][key] += float64(*metricDTO.Delta)
+		} else if metricDTO.Value != nil {
+			list[metricDTO.MType][key] = *metricDTO.Value
+		}
+	}
+
+	keys := make(map[string][]string)
+	for metricType, items := range list {
+		keys[metricType] = dto.SeriesKeys(items)
+	}
+	for metricType, items := range histograms {
+		keys[metricType] = dto.SeriesKeys(items)
+	}
+	for metricType, items := range summaries {
+		keys[metricType] = dto.SeriesKeys(items)
+	}
+
+	samples := make(map[string]map[string]float64, len(keys))
+	err := s.withQuota(ctx, keys, false, func() error {
+		var err error
+		for metricType, metric := range list {
+			entity, _ := storage.GetMetricType(metricType)
+
+			if samples[metricType], err = processSamples(ctx, entity, metric); err != nil {
+				return err
+			}
+		}
+
+		for metricType, items := range histograms {
+			entity, _ := storage.GetMetricType(metricType)
+
+			if samples[metricType], err = entity.(storages.HistogramMetric).ProcessHistograms(ctx, items); err != nil {
+				if errors.Is(err, dto.ErrHistogramBounds) {
+					return &metricValueError{err: err}
+				}
+				return err
+			}
+		}
+
+		for metricType, items := range summaries {
+			entity, _ := storage.GetMetricType(metricType)
+
+			if samples[metricType], err = entity.(storages.SummaryMetric).ProcessSummaries(ctx, items); err != nil {
+				if errors.Is(err, dto.ErrSummaryAccuracy) {
+					return &metricValueError{err: err}
+				}
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for metricType, items := range samples {
+		s.recordHistory(ctx, metricType, items)
+	}
+
+	return nil
+}
+
+// addObservations adds the histogram payload or the single observation of the DTO to the batch item of the series.
+// The batch item has the configured bounds, so a payload with other bounds fails with ErrHistogramBounds before anything is stored.
+func addObservations(batch map[string]dto.Histogram, key string, bounds []float64, metricDTO dto.Metrics) error {
+	item, ok := batch[key]
+	if !ok {
+		item = dto.NewHistogram(bounds)
+	}
+
+	switch {
+	case metricDTO.Histogram != nil:
+		if err := item.Merge(*metricDTO.Histogram); err != nil {
+			return err
+		}
+	case metricDTO.Value != nil:
+		if err := item.Observe(*metricDTO.Value); err != nil {
+			return err
+		}
+	case metricDTO.Delta != nil:
+		if err := item.Observe(float64(*metricDTO.Delta)); err != nil {
+			return err
+		}
+	default:
+		return errors.New("value, delta and histogram are empty")
+	}
+
+	batch[key] = item
+
+	return nil
+}
+
+// addSketchObservations adds the summary payload or the single observation of the DTO to the batch item of the series.
+// The batch item has the configured relative accuracy, so a payload with another accuracy fails with ErrSummaryAccuracy before anything is stored.
+func addSketchObservations(batch map[string]dto.Summary, key string, relativeAccuracy float64, metricDTO dto.Metrics) error {
+	item, ok := batch[key]
+	if !ok {
+		item = dto.NewSummary(relativeAccuracy)
+	}
+
+	switch {
+	case metricDTO.Summary != nil:
+		if err := item.Merge(*metricDTO.Summary); err != nil {
+			return err
+		}
+	case metricDTO.Value != nil:
+		if err := item.Observe(*metricDTO.Value); err != nil {
+			return err
+		}
+	case metricDTO.Delta != nil:
+		if err := item.Observe(float64(*metricDTO.Delta)); err != nil {
+			return err
+		}
+	default:
+		return errors.New("value, delta and summary are empty")
+	}
+
+	batch[key] = item
+
+	return nil
+}
+
+// updateErrorStatus maps an updateMetrics error to an HTTP status code.
+func updateErrorStatus(err error) int {
+	var typeErr *metricTypeNotFoundError
+	var valueErr *metricValueError
+	var quotaErr *seriesQuotaError
+
+	switch {
+	case errors.As(err, &typeErr):
+		return http.StatusNotFound
+	case errors.As(err, &valueErr):
+		return http.StatusBadRequest
+	case errors.As(err, &quotaErr):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// listMetricDtos returns every metric of the entity as DTOs keyed by series key.
+// The DTOs of an entity that tracks the update times of its series carry the time of the last update.
+func listMetricDtos(ctx context.Context, metricType string, entity storages.EntityMetric) (map[string]dto.Metrics, error) {
+	list, err := entityMetricDtos(ctx, metricType, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	expirable, ok := entity.(storages.ExpirableMetric)
+	if !ok {
+		return list, nil
+	}
+
+	updated, err := expirable.UpdatedAt(ctx)
+	if errors.Is(err, storages.ErrUpdatesNotTracked) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for key, at := range updated {
+		if metric, ok := list[key]; ok {
+			at := at
+			metric.UpdatedAt = &at
+			list[key] = metric
+		}
+	}
+
+	return list, nil
+}
+
+// findMetricDto returns the DTO of the series with the key, an entity that reads single series doesn't list the others.
+func findMetricDto(ctx context.Context, metricType string, key string, entity storages.EntityMetric) (dto.Metrics, bool, error) {
+	if item, ok := entity.(storages.ItemMetric); ok {
+		return itemMetricDto(ctx, metricType, key, item)
+	}
+
+	list, err := listMetricDtos(ctx, metricType, entity)
+	if err != nil {
+		return dto.Metrics{}, false, err
+	}
+
+	metric, ok := list[key]
+	return metric, ok, nil
+}
+
+// itemMetricDto returns the DTO of the series with the key read without listing the other series, ok is false when it isn't stored.
+// The DTO of an entity that tracks the update times of its series carries the time of the last update.
+func itemMetricDto(ctx context.Context, metricType string, key string, entity storages.ItemMetric) (dto.Metrics, bool, error) {
+	value, ok, err := entity.GetItem(ctx, key)
+	if err != nil || !ok {
+		return dto.Metrics{}, false, err
+	}
+
+	metricDTO := newMetricDto(metricType, key, value)
+
+	expirable, ok := entity.(storages.ExpirableMetric)
+	if !ok {
+		return metricDTO, true, nil
+	}
+
+	updated, err := expirable.UpdatedAt(ctx)
+	if errors.Is(err, storages.ErrUpdatesNotTracked) {
+		return metricDTO, true, nil
+	}
+	if err != nil {
+		return dto.Metrics{}, false, err
+	}
+
+	if at, ok := updated[key]; ok {
+		metricDTO.UpdatedAt = &at
+	}
+
+	return metricDTO, true, nil
+}
+
+func entityMetricDtos(ctx context.Context, metricType string, entity storages.EntityMetric) (map[string]dto.Metrics, error) {
+	if histogram, ok := entity.(storages.HistogramMetric); ok {
+		items, err := histogram.GetHistograms(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		list := make(map[string]dto.Metrics, len(items))
+		for key, item := range items {
+			item := item.Clone()
+			name, labels := dto.ParseSeriesKey(key)
+			list[key] = dto.Metrics{ID: name, MType: metricType, Histogram: &item, Labels: labels}
+		}
+
+		return list, nil
+	}
+
+	if summary, ok := entity.(storages.SummaryMetric); ok {
+		items, err := summary.GetSummaries(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		list := make(map[string]dto.Metrics, len(items))
+		for key, item := range items {
+			item := item.WithQuantiles(dto.DefaultQuantiles)
+			name, labels := dto.ParseSeriesKey(key)
+			list[key] = dto.Metrics{ID: name, MType: metricType, Summary: &item, Labels: labels}
+		}
+
+		return list, nil
+	}
+
+	items, err := entity.GetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make(map[string]dto.Metrics, len(items))
+	for key, value := range items {
+		list[key] = newMetricDto(metricType, key, value)
+	}
+
+	return list, nil
+}
+
+// newMetricDto builds a metric DTO of a series, gauges are reported as values and every other type as deltas.
+func newMetricDto(metricType string, key string, value float64) dto.Metrics {
+	name, labels := dto.ParseSeriesKey(key)
+	metricDTO := dto.Metrics{
+		ID:     name,
+		MType:  metricType,
+		Labels: labels,
+	}
+
+	if metricType == "gauge" {
+		metricDTO.Value = &value
+	} else {
+		delta := int64(value)
+		metricDTO.Delta = &delta
+	}
+
+	return metricDTO
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDistributionTestServer() (*Server, chi.Router) {
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(nil))
+	stg.AddMetric("histogram", metrics.NewHistogram(nil, []float64{1, 5}))
+	stg.AddMetric("summary", metrics.NewSummary(nil))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+
+	r := chi.NewRouter()
+	r.With(s.JSONContentTypeMiddleware).Post("/update/", s.writePostMetricHandler)
+	r.With(s.JSONContentTypeMiddleware).Post("/updates/", s.writeMassPostMetricHandler)
+	r.With(s.JSONContentTypeMiddleware).Post("/value/", s.showPostMetricHandler)
+	r.Post("/update/{metricType}/{metricName}/{metricValue}", s.writeGetMetricHandler)
+	r.Get("/value/{metricType}/{metricName}", s.showMetricNameHandlers)
+	r.Get("/metrics", s.prometheusMetricsHandler)
+
+	return s, r
+}
+
+func TestHistogramHandlers(t *testing.T) {
+	_, r := newDistributionTestServer()
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	value := 3.0
+	single, _ := easyjson.Marshal(dto.Metrics{ID: "latency", MType: "histogram", Value: &value})
+	batch, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "latency", MType: "histogram", Histogram: &dto.Histogram{Bounds: []float64{1, 5}, Counts: []uint64{1, 0, 1}, Sum: 10.5, Count: 2}},
+		{ID: "Alloc", MType: "gauge", Value: &value},
+	})
+	mismatch, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "Mismatch", MType: "gauge", Value: &value},
+		{ID: "latency", MType: "histogram", Histogram: &dto.Histogram{Bounds: []float64{1, 2}, Counts: []uint64{1, 0, 0}, Sum: 0.5, Count: 1}},
+	})
+	broken, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "latency", MType: "histogram", Histogram: &dto.Histogram{Bounds: []float64{1, 5}, Counts: []uint64{1}, Sum: 0.5, Count: 1}},
+	})
+	read, _ := easyjson.Marshal(dto.Metrics{ID: "latency", MType: "histogram"})
+
+	testHandler(t, r, http.MethodPost, "/update/histogram/latency/0.5", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/histogram/latency/invalid", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/histogram/latency/NaN", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/histogram/latency/+Inf", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/", http.StatusOK, "skip", single, headers)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", batch, headers)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusBadRequest, "skip", mismatch, headers)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusBadRequest, "skip", broken, headers)
+
+	testHandler(t, r, http.MethodGet, "/value/histogram/latency", http.StatusOK, "count: 4\nsum: 14\nle 1: 2\nle 5: 3\nle +Inf: 4\n", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/histogram/unknown", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Mismatch", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodPost, "/value/", http.StatusOK, `{"id":"latency","type":"histogram","histogram":{"bounds":[1,5],"counts":[2,1,1],"sum":14,"count":4}}`, read, headers)
+
+	testHandler(t, r, http.MethodGet, "/metrics", http.StatusOK, `# TYPE Alloc gauge
+Alloc 3
+# TYPE latency histogram
+latency_bucket{le="1"} 2
+latency_bucket{le="5"} 3
+latency_bucket{le="+Inf"} 4
+latency_sum 14
+latency_count 4
+`, nil, nil)
+}
+
+func TestDistributionSnapshot(t *testing.T) {
+	pathName := "tmp/histogram.json"
+	projectDir, _ := os.Getwd()
+	defer os.RemoveAll(filepath.Join(projectDir, filepath.Dir(pathName)))
+
+	s := newFileStorageServer(t, pathName, 300)
+	histogram, _ := s.storage.GetMetricType("histogram")
+	_ = histogram.ProcessMassive(context.Background(), map[string]float64{"latency": 2})
+	summary, _ := s.storage.GetMetricType("summary")
+	_ = summary.ProcessMassive(context.Background(), map[string]float64{"latency": 7})
+	s.saveSnapshot()
+
+	s.Close()
+	restored := newFileStorageServer(t, pathName, 300)
+	assert.NoError(t, restored.snapshot.Load())
+
+	entity, _ := restored.storage.GetMetricType("histogram")
+	items, err := entity.(storages.HistogramMetric).GetHistograms(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, dto.Histogram{Bounds: []float64{1, 5}, Counts: []uint64{0, 1, 0}, Sum: 2, Count: 1}, items["latency"])
+
+	entity, _ = restored.storage.GetMetricType("summary")
+	summaries, err := entity.(storages.SummaryMetric).GetSummaries(context.Background())
+	assert.NoError(t, err)
+	latency := summaries["latency"]
+	assert.Equal(t, uint64(1), latency.Count)
+	assert.InEpsilon(t, 7, latency.Quantile(0.5), dto.DefaultRelativeAccuracy)
+}
+
+func TestSummaryHandlers(t *testing.T) {
+	_, r := newDistributionTestServer()
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	value := 4.0
+	single, _ := easyjson.Marshal(dto.Metrics{ID: "latency", MType: "summary", Value: &value})
+
+	agent := dto.NewSummary(dto.DefaultRelativeAccuracy)
+	agent.Observe(1)
+	agent.Observe(2)
+	batch, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "latency", MType: "summary", Summary: &agent},
+		{ID: "latency", MType: "summary", Summary: &agent},
+	})
+
+	mismatched := dto.NewSummary(0.05)
+	mismatched.Observe(1)
+	mismatch, _ := easyjson.Marshal(dto.MetricsCollection{
+		{ID: "Mismatch", MType: "gauge", Value: &value},
+		{ID: "latency", MType: "summary", Summary: &mismatched},
+	})
+	empty, _ := easyjson.Marshal(dto.MetricsCollection{{ID: "latency", MType: "summary"}})
+	read, _ := easyjson.Marshal(dto.Metrics{ID: "latency", MType: "summary"})
+
+	testHandler(t, r, http.MethodPost, "/update/summary/latency/3", http.StatusOK, "", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/summary/latency/invalid", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/summary/latency/-Inf", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodPost, "/update/", http.StatusOK, "skip", single, headers)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusOK, "skip", batch, headers)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusBadRequest, "skip", mismatch, headers)
+	testHandler(t, r, http.MethodPost, "/updates/", http.StatusBadRequest, "skip", empty, headers)
+
+	testHandler(t, r, http.MethodGet, "/value/summary/latency?q=0,1", http.StatusOK, "count: 6\nsum: 13\nquantile 0: 1\nquantile 1: 4\n", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/summary/latency?q=2", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/gauge/Mismatch", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/value/summary/unknown", http.StatusNotFound, "skip", nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/value/", bytes.NewReader(read))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var metricDTO dto.Metrics
+	assert.NoError(t, easyjson.Unmarshal(rr.Body.Bytes(), &metricDTO))
+	assert.Equal(t, uint64(6), metricDTO.Summary.Count)
+	assert.Len(t, metricDTO.Summary.Quantiles, len(dto.DefaultQuantiles))
+	assert.InEpsilon(t, 2, metricDTO.Summary.Quantiles[0].Value, dto.DefaultRelativeAccuracy)
+
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Contains(t, rr.Body.String(), "# TYPE latency summary\nlatency{quantile=\"0.5\"} ")
+	assert.Contains(t, rr.Body.String(), "latency_sum 13\nlatency_count 6\n")
+}
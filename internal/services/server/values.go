@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/mailru/easyjson"
+)
+
+// metricsFilter is a parsed dto.MetricsFilter.
+type metricsFilter struct {
+	types    map[string]bool
+	names    map[string]bool
+	prefix   string
+	regexp   *regexp.Regexp
+	matchers []labelMatcher
+}
+
+func newMetricsFilter(filter dto.MetricsFilter) (*metricsFilter, error) {
+	f := &metricsFilter{prefix: filter.Prefix}
+
+	if len(filter.Types) > 0 {
+		f.types = make(map[string]bool, len(filter.Types))
+		for _, metricType := range filter.Types {
+			f.types[metricType] = true
+		}
+	}
+
+	if len(filter.Names) > 0 {
+		f.names = make(map[string]bool, len(filter.Names))
+		for _, name := range filter.Names {
+			f.names[name] = true
+		}
+	}
+
+	if filter.Regex != "" {
+		re, err := regexp.Compile("^(?:" + filter.Regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %s: %w", filter.Regex, err)
+		}
+		f.regexp = re
+	}
+
+	matchers, err := parseLabelMatchers(filter.Labels)
+	if err != nil {
+		return nil, err
+	}
+	f.matchers = matchers
+
+	return f, nil
+}
+
+// matches reports whether the series passes every part of the filter.
+func (f *metricsFilter) matches(key string) bool {
+	if !matchSeries(key, "", f.matchers) {
+		return false
+	}
+
+	name, _ := dto.SplitSeriesKey(key)
+	if f.names != nil && !f.names[name] {
+		return false
+	}
+
+	if !strings.HasPrefix(name, f.prefix) {
+		return false
+	}
+
+	return f.regexp == nil || f.regexp.MatchString(name)
+}
+
+// listValues returns the metrics that match the filter ordered by type and series key.
+func (s *Server) listValues(ctx context.Context, filter *metricsFilter) (dto.MetricsCollection, error) {
+	for metricType := range filter.types {
+		if _, err := s.storageOf(ctx).GetMetricType(metricType); err != nil {
+			return nil, &metricTypeNotFoundError{metricType: metricType}
+		}
+	}
+
+	stgList := s.storageOf(ctx).GetList()
+	metricTypes := make([]string, 0, len(stgList))
+	for metricType := range stgList {
+		if filter.types == nil || filter.types[metricType] {
+			metricTypes = append(metricTypes, metricType)
+		}
+	}
+	sort.Strings(metricTypes)
+
+	collection := dto.MetricsCollection{}
+	for _, metricType := range metricTypes {
+		list, err := listMetricDtos(ctx, metricType, stgList[metricType])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range selectSeries(list, "", nil) {
+			if filter.matches(key) {
+				collection = append(collection, list[key])
+			}
+		}
+	}
+
+	return collection, nil
+}
+
+// showValuesHandler writes the metrics selected by the query as a collection.
+// The type and name parameters take comma separated lists and may repeat, prefix, regex and labels filter series keys.
+func (s *Server) showValuesHandler(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	s.writeValues(rw, req, dto.MetricsFilter{
+		Types:  splitQueryList(query["type"]),
+		Names:  splitQueryList(query["name"]),
+		Prefix: query.Get("prefix"),
+		Regex:  query.Get("regex"),
+		Labels: query.Get(labelsQueryParam),
+	})
+}
+
+// showPostValuesHandler writes the metrics selected by the dto.MetricsFilter of the body, an empty body selects every metric.
+func (s *Server) showPostValuesHandler(rw http.ResponseWriter, req *http.Request) {
+	filter := dto.MetricsFilter{}
+	rawBytes, _ := io.ReadAll(req.Body)
+
+	if len(rawBytes) > 0 {
+		if err := easyjson.Unmarshal(rawBytes, &filter); err != nil {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"failed to unmarshal body: %s"}`, err.Error()))
+			return
+		}
+	}
+
+	s.writeValues(rw, req, filter)
+}
+
+func (s *Server) writeValues(rw http.ResponseWriter, req *http.Request, filter dto.MetricsFilter) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	f, err := newMetricsFilter(filter)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"%s"}`, err.Error()))
+		return
+	}
+
+	collection, err := s.listValues(req.Context(), f)
+	if err != nil {
+		rw.WriteHeader(updateErrorStatus(err))
+		fmt.Fprintf(rw, "%v", fmt.Sprintf(`{"error":"%s"}`, err.Error()))
+		return
+	}
+
+	json, _ := easyjson.Marshal(collection)
+	fmt.Fprintf(rw, "%v", string(json))
+}
+
+// splitQueryList flattens repeated query values holding comma separated lists.
+func splitQueryList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/entity/metrics"
+	"github.com/AnatolySnegovskiy/metric/internal/repositories"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+	"github.com/AnatolySnegovskiy/metric/internal/storages"
+	"github.com/AnatolySnegovskiy/metric/internal/storages/clients"
+	"github.com/go-chi/chi/v5"
+	"github.com/gookit/slog"
+	"github.com/mailru/easyjson"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValuesTestServer() *Server {
+	gauge := metrics.NewGauge(nil)
+	_ = gauge.ProcessMassive(context.Background(), map[string]float64{
+		"Alloc":              1,
+		`Alloc{host="web1"}`: 2,
+		"HeapAlloc":          3,
+		"RandomValue":        4,
+	})
+	counter := metrics.NewCounter(nil)
+	_ = counter.Process(context.Background(), "PollCount", "5")
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", gauge)
+	stg.AddMetric("counter", counter)
+
+	return &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+}
+
+func TestValuesHandlers(t *testing.T) {
+	s := newValuesTestServer()
+	r := chi.NewRouter()
+	r.With(s.JSONContentTypeMiddleware).Post("/values/", s.showPostValuesHandler)
+	r.Get("/values/", s.showValuesHandler)
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	testHandler(t, r, http.MethodGet, "/values/", http.StatusOK, `[{"id":"PollCount","type":"counter","delta":5},{"id":"Alloc","type":"gauge","value":1},{"id":"Alloc","type":"gauge","value":2,"labels":{"host":"web1"}},{"id":"HeapAlloc","type":"gauge","value":3},{"id":"RandomValue","type":"gauge","value":4}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?type=counter", http.StatusOK, `[{"id":"PollCount","type":"counter","delta":5}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?prefix=Alloc&labels=host=web1", http.StatusOK, `[{"id":"Alloc","type":"gauge","value":2,"labels":{"host":"web1"}}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?regex=.*Alloc", http.StatusOK, `[{"id":"Alloc","type":"gauge","value":1},{"id":"Alloc","type":"gauge","value":2,"labels":{"host":"web1"}},{"id":"HeapAlloc","type":"gauge","value":3}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?name=HeapAlloc,PollCount&name=RandomValue&type=gauge", http.StatusOK, `[{"id":"HeapAlloc","type":"gauge","value":3},{"id":"RandomValue","type":"gauge","value":4}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?prefix=Nothing", http.StatusOK, `[]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?type=unknown", http.StatusNotFound, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?regex=(", http.StatusBadRequest, "skip", nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/?labels=host", http.StatusBadRequest, "skip", nil, nil)
+
+	filter, _ := easyjson.Marshal(dto.MetricsFilter{Types: []string{"gauge"}, Names: []string{"Alloc"}, Labels: "host="})
+	testHandler(t, r, http.MethodPost, "/values/", http.StatusOK, `[{"id":"Alloc","type":"gauge","value":1}]`, filter, headers)
+	testHandler(t, r, http.MethodPost, "/values/", http.StatusOK, "skip", []byte{}, headers)
+	testHandler(t, r, http.MethodPost, "/values/", http.StatusBadRequest, "skip", []byte("{"), headers)
+	testHandler(t, r, http.MethodPost, "/values/", http.StatusBadRequest, "skip", filter, nil)
+}
+
+func TestValuesHandlerGzipAndHash(t *testing.T) {
+	s := newValuesTestServer()
+	conf := getMockConf(t)
+	conf.EXPECT().GetShaKey().Return("secret").AnyTimes()
+	conf.EXPECT().GetCryptoKey().Return("").AnyTimes()
+	s.conf = conf
+	s.router = chi.NewRouter()
+	s.setupRoutes()
+
+	req, _ := http.NewRequest(http.MethodGet, "/values/?type=counter", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.NotEmpty(t, rec.Header().Get("HashSHA256"))
+
+	gz, err := gzip.NewReader(bytes.NewReader(rec.Body.Bytes()))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(gz)
+	assert.Equal(t, `[{"id":"PollCount","type":"counter","delta":5}]`, updatedAtField.ReplaceAllString(string(body), ""))
+}
+
+func TestValuesHandlerDB(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("Alloc", `host="web1"`, float64(1.5)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, updated_at FROM gauge")).
+		WithArgs("").
+		WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "updated_at"}).AddRow("Alloc", `host="web1"`, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	stg := storages.NewMemStorage()
+	stg.AddMetric("gauge", metrics.NewGauge(repositories.NewGaugeRepo(clients.NewPostgres(mock))))
+	s := &Server{
+		storage: stg,
+		logger:  slog.New(),
+	}
+	r := chi.NewRouter()
+	r.Get("/values/", s.showValuesHandler)
+
+	testHandler(t, r, http.MethodGet, "/values/", http.StatusOK, `[{"id":"Alloc","type":"gauge","value":1.5,"labels":{"host":"web1"},"updated_at":"2024-01-01T00:00:00Z"}]`, nil, nil)
+	testHandler(t, r, http.MethodGet, "/values/", http.StatusInternalServerError, "skip", nil, nil)
+}
@@ -0,0 +1,273 @@
+package storages
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backend keeps the series of a single metric type keyed by series key.
+// GetList returns a copy of the stored series, AddMetrics replaces the values of the given series.
+type Backend[V any] interface {
+	GetList(ctx context.Context) (map[string]V, error)
+	AddMetrics(ctx context.Context, items map[string]V) error
+	DeleteMetrics(ctx context.Context, keys []string) error
+}
+
+// ErrSeriesNotFound is returned by GetItem of the backends for a series that isn't stored.
+var ErrSeriesNotFound = errors.New("series not found")
+
+// ItemGetter is a Backend that reads a single series without reading the others.
+type ItemGetter[V any] interface {
+	Backend[V]
+	// GetItem returns the value of the series with the key, ErrSeriesNotFound when it isn't stored.
+	GetItem(ctx context.Context, key string) (V, error)
+}
+
+// UpdateFunc returns the new value of the series with the key from its current value, ok is false when the series doesn't exist yet.
+type UpdateFunc[V any] func(key string, value V, ok bool) (V, error)
+
+// Updater is a Backend that reads and replaces series in one step, so concurrent updates of the same series are never lost.
+type Updater[V any] interface {
+	Backend[V]
+	// UpdateMetrics replaces every series of the keys with the result of fn, nothing is written when fn fails.
+	UpdateMetrics(ctx context.Context, keys []string, fn UpdateFunc[V]) error
+}
+
+// Incrementer is a Backend of counters that adds the deltas to the stored values itself,
+// so the counters stay exact when several servers share the storage.
+type Incrementer interface {
+	Backend[float64]
+	// IncrementMetrics adds the deltas to the series of their keys, missing series start from zero.
+	// The totals of the series after the increment are returned.
+	IncrementMetrics(ctx context.Context, deltas map[string]float64) (map[string]float64, error)
+}
+
+// ErrUpdatesNotTracked is returned by the entities whose backend doesn't keep the update times of the series.
+var ErrUpdatesNotTracked = errors.New("storage doesn't track update times")
+
+// Expirer is a Backend that keeps the time every series was updated last, so stale series can expire.
+type Expirer interface {
+	// UpdatedAt returns the last update time of every series.
+	UpdatedAt(ctx context.Context) (map[string]time.Time, error)
+	// ExpireMetrics deletes the series of the keys that weren't updated since before, the others are kept.
+	ExpireMetrics(ctx context.Context, keys []string, before time.Time) error
+}
+
+// timeNow returns the update time of the series written by the backends.
+var timeNow = time.Now
+
+// memShardCount is the number of independently locked parts of a MemBackend.
+const memShardCount = 32
+
+// MemBackend keeps the series in memory.
+// The series are spread over shards with their own locks, so writers of different series rarely wait for each other.
+type MemBackend[V any] struct {
+	seed   maphash.Seed
+	shards [memShardCount]memShard[V]
+}
+
+type memShard[V any] struct {
+	mu      sync.RWMutex
+	items   map[string]V
+	updated map[string]time.Time
+}
+
+func NewMemBackend[V any]() *MemBackend[V] {
+	m := &MemBackend[V]{
+		seed: maphash.MakeSeed(),
+	}
+	for i := range m.shards {
+		m.shards[i].items = make(map[string]V)
+		m.shards[i].updated = make(map[string]time.Time)
+	}
+
+	return m
+}
+
+func (m *MemBackend[V]) shardIndex(key string) int {
+	return int(maphash.String(m.seed, key) % memShardCount)
+}
+
+func (m *MemBackend[V]) GetList(_ context.Context) (map[string]V, error) {
+	items := make(map[string]V)
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mu.RLock()
+		for key, value := range shard.items {
+			items[key] = value
+		}
+		shard.mu.RUnlock()
+	}
+
+	return items, nil
+}
+
+func (m *MemBackend[V]) AddMetrics(_ context.Context, items map[string]V) error {
+	m.add(items, timeNow())
+	return nil
+}
+
+// add stores the items updated at the time.
+func (m *MemBackend[V]) add(items map[string]V, at time.Time) {
+	for key, value := range items {
+		shard := &m.shards[m.shardIndex(key)]
+		shard.mu.Lock()
+		shard.items[key] = value
+		shard.updated[key] = at
+		shard.mu.Unlock()
+	}
+}
+
+func (m *MemBackend[V]) DeleteMetrics(_ context.Context, keys []string) error {
+	for _, key := range keys {
+		shard := &m.shards[m.shardIndex(key)]
+		shard.mu.Lock()
+		delete(shard.items, key)
+		delete(shard.updated, key)
+		shard.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (m *MemBackend[V]) UpdatedAt(_ context.Context) (map[string]time.Time, error) {
+	updated := make(map[string]time.Time)
+	for i := range m.shards {
+		shard := &m.shards[i]
+		shard.mu.RLock()
+		for key, at := range shard.updated {
+			updated[key] = at
+		}
+		shard.mu.RUnlock()
+	}
+
+	return updated, nil
+}
+
+func (m *MemBackend[V]) ExpireMetrics(_ context.Context, keys []string, before time.Time) error {
+	return m.expire(keys, before, nil)
+}
+
+// UpdateMetrics locks the shards of the keys in a fixed order, so concurrent batches can't deadlock.
+func (m *MemBackend[V]) UpdateMetrics(_ context.Context, keys []string, fn UpdateFunc[V]) error {
+	return m.update(keys, fn, nil)
+}
+
+// lock locks the shards of the keys in the order of their indexes and returns the function that unlocks them.
+func (m *MemBackend[V]) lock(keys []string) func() {
+	indexes := make([]int, 0, len(keys))
+	seen := make(map[int]bool, len(keys))
+	for _, key := range keys {
+		index := m.shardIndex(key)
+		if !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		m.shards[index].mu.Lock()
+	}
+
+	return func() {
+		for _, index := range indexes {
+			m.shards[index].mu.Unlock()
+		}
+	}
+}
+
+// update applies fn to the series of the keys while their shards are locked.
+// The commit function, when set, runs with the new values and their update time before they are stored and cancels the update when it fails.
+func (m *MemBackend[V]) update(keys []string, fn UpdateFunc[V], commit func(changed map[string]V, at time.Time) error) error {
+	defer m.lock(keys)()
+
+	changed := make(map[string]V, len(keys))
+	for _, key := range keys {
+		shard := &m.shards[m.shardIndex(key)]
+		current, ok := changed[key]
+		if !ok {
+			current, ok = shard.items[key]
+		}
+
+		value, err := fn(key, current, ok)
+		if err != nil {
+			return err
+		}
+		changed[key] = value
+	}
+
+	at := timeNow()
+	if commit != nil {
+		if err := commit(changed, at); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range changed {
+		shard := &m.shards[m.shardIndex(key)]
+		shard.items[key] = value
+		shard.updated[key] = at
+	}
+
+	return nil
+}
+
+// expire deletes the series of the keys that weren't updated since before while their shards are locked.
+// The commit function, when set, gets the keys of the stale series before they are deleted and cancels the expiry when it fails.
+func (m *MemBackend[V]) expire(keys []string, before time.Time, commit func(expired []string) error) error {
+	defer m.lock(keys)()
+
+	var expired []string
+	for _, key := range keys {
+		at, ok := m.shards[m.shardIndex(key)].updated[key]
+		if ok && at.Before(before) {
+			expired = append(expired, key)
+		}
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	if commit != nil {
+		if err := commit(expired); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range expired {
+		shard := &m.shards[m.shardIndex(key)]
+		delete(shard.items, key)
+		delete(shard.updated, key)
+	}
+
+	return nil
+}
+
+// replace swaps every stored series for the given ones, the series without an update time are updated at the time.
+func (m *MemBackend[V]) replace(items map[string]V, updated map[string]time.Time, at time.Time) {
+	for i := range m.shards {
+		m.shards[i].mu.Lock()
+		m.shards[i].items = make(map[string]V)
+		m.shards[i].updated = make(map[string]time.Time)
+	}
+
+	for key, value := range items {
+		shard := &m.shards[m.shardIndex(key)]
+		shard.items[key] = value
+		if updatedAt, ok := updated[key]; ok {
+			shard.updated[key] = updatedAt
+		} else {
+			shard.updated[key] = at
+		}
+	}
+
+	for i := range m.shards {
+		m.shards[i].mu.Unlock()
+	}
+}
@@ -0,0 +1,286 @@
+package storages
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStats counts the hits and misses of the caches that share it.
+type CacheStats struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// Hits returns the number of reads served from memory.
+func (c *CacheStats) Hits() uint64 {
+	return c.hits.Load()
+}
+
+// Misses returns the number of reads that went to the backend.
+func (c *CacheStats) Misses() uint64 {
+	return c.misses.Load()
+}
+
+// cacheEntry is a cached series with the time it was read or written.
+type cacheEntry[V any] struct {
+	value V
+	at    time.Time
+}
+
+// CachedBackend is a read-through and write-through cache in front of a Backend, such as a database repository.
+// Reads are served from memory while the cached data is younger than the staleness bound.
+type CachedBackend[V any] struct {
+	backend   Backend[V]
+	staleness time.Duration
+	stats     *CacheStats
+
+	mu    sync.RWMutex
+	items map[string]cacheEntry[V]
+	// loaded is the time the list was read last.
+	loaded  time.Time
+	updated map[string]time.Time
+	// updatedLoaded is the time the update times were read last.
+	updatedLoaded time.Time
+	// version changes with every write to drop reads that started before it.
+	version uint64
+}
+
+// NewCachedBackend creates the cache of the backend.
+func NewCachedBackend[V any](backend Backend[V], staleness time.Duration, stats *CacheStats) *CachedBackend[V] {
+	if stats == nil {
+		stats = &CacheStats{}
+	}
+
+	return &CachedBackend[V]{
+		backend:   backend,
+		staleness: staleness,
+		stats:     stats,
+		items:     make(map[string]cacheEntry[V]),
+		updated:   make(map[string]time.Time),
+	}
+}
+
+// fresh reports whether data read at the time isn't stale.
+func (c *CachedBackend[V]) fresh(at time.Time) bool {
+	return !at.IsZero() && timeNow().Sub(at) <= c.staleness
+}
+
+func (c *CachedBackend[V]) GetList(ctx context.Context) (map[string]V, error) {
+	c.mu.RLock()
+	if c.fresh(c.loaded) {
+		items := make(map[string]V, len(c.items))
+		for key, entry := range c.items {
+			items[key] = entry.value
+		}
+		c.mu.RUnlock()
+		c.stats.hits.Add(1)
+
+		return items, nil
+	}
+	version := c.version
+	c.mu.RUnlock()
+	c.stats.misses.Add(1)
+
+	at := timeNow()
+	items, err := c.backend.GetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version == version {
+		c.items = make(map[string]cacheEntry[V], len(items))
+		for key, value := range items {
+			c.items[key] = cacheEntry[V]{value: value, at: at}
+		}
+		c.loaded = at
+	}
+
+	return items, nil
+}
+
+// GetItem returns the series with the key, ErrSeriesNotFound when it isn't stored.
+func (c *CachedBackend[V]) GetItem(ctx context.Context, key string) (V, error) {
+	c.mu.RLock()
+	entry, ok := c.items[key]
+	switch {
+	case ok && c.fresh(entry.at):
+		c.mu.RUnlock()
+		c.stats.hits.Add(1)
+		return entry.value, nil
+	case !ok && c.fresh(c.loaded):
+		c.mu.RUnlock()
+		c.stats.hits.Add(1)
+		return entry.value, ErrSeriesNotFound
+	}
+	version := c.version
+	c.mu.RUnlock()
+
+	getter, ok := c.backend.(ItemGetter[V])
+	if !ok {
+		items, err := c.GetList(ctx)
+		if err != nil {
+			return entry.value, err
+		}
+
+		value, ok := items[key]
+		if !ok {
+			return value, ErrSeriesNotFound
+		}
+
+		return value, nil
+	}
+
+	c.stats.misses.Add(1)
+	at := timeNow()
+	value, err := getter.GetItem(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	if c.version == version {
+		c.items[key] = cacheEntry[V]{value: value, at: at}
+	}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+func (c *CachedBackend[V]) AddMetrics(ctx context.Context, items map[string]V) error {
+	if err := c.backend.AddMetrics(ctx, items); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	at := timeNow()
+	for key, value := range items {
+		c.items[key] = cacheEntry[V]{value: value, at: at}
+		c.updated[key] = at
+	}
+
+	return nil
+}
+
+func (c *CachedBackend[V]) DeleteMetrics(ctx context.Context, keys []string) error {
+	if err := c.backend.DeleteMetrics(ctx, keys); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	for _, key := range keys {
+		delete(c.items, key)
+		delete(c.updated, key)
+	}
+
+	return nil
+}
+
+// UpdatedAt returns the last update time of every series.
+func (c *CachedBackend[V]) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	expirer, ok := c.backend.(Expirer)
+	if !ok {
+		return nil, ErrUpdatesNotTracked
+	}
+
+	c.mu.RLock()
+	if c.fresh(c.updatedLoaded) {
+		updated := make(map[string]time.Time, len(c.updated))
+		for key, at := range c.updated {
+			updated[key] = at
+		}
+		c.mu.RUnlock()
+		c.stats.hits.Add(1)
+
+		return updated, nil
+	}
+	version := c.version
+	c.mu.RUnlock()
+	c.stats.misses.Add(1)
+
+	at := timeNow()
+	updated, err := expirer.UpdatedAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version == version {
+		c.updated = make(map[string]time.Time, len(updated))
+		for key, updatedAt := range updated {
+			c.updated[key] = updatedAt
+		}
+		c.updatedLoaded = at
+	}
+
+	return updated, nil
+}
+
+// ExpireMetrics deletes the stale series of the keys from the backend.
+func (c *CachedBackend[V]) ExpireMetrics(ctx context.Context, keys []string, before time.Time) error {
+	expirer, ok := c.backend.(Expirer)
+	if !ok {
+		return ErrUpdatesNotTracked
+	}
+
+	err := expirer.ExpireMetrics(ctx, keys, before)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	for _, key := range keys {
+		delete(c.items, key)
+		delete(c.updated, key)
+	}
+	c.loaded = time.Time{}
+	c.updatedLoaded = time.Time{}
+
+	return err
+}
+
+// CachedIncrementer is a CachedBackend of counters whose backend adds the deltas itself.
+type CachedIncrementer struct {
+	*CachedBackend[float64]
+	incrementer Incrementer
+}
+
+// NewCachedIncrementer creates the cache of the counters of the backend.
+func NewCachedIncrementer(backend Incrementer, staleness time.Duration, stats *CacheStats) *CachedIncrementer {
+	return &CachedIncrementer{
+		CachedBackend: NewCachedBackend[float64](backend, staleness, stats),
+		incrementer:   backend,
+	}
+}
+
+// IncrementMetrics adds the deltas to the counters of the backend and caches the totals it returns.
+func (c *CachedIncrementer) IncrementMetrics(ctx context.Context, deltas map[string]float64) (map[string]float64, error) {
+	totals, err := c.incrementer.IncrementMetrics(ctx, deltas)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	at := timeNow()
+	for key, total := range totals {
+		c.items[key] = cacheEntry[float64]{value: total, at: at}
+		c.updated[key] = at
+	}
+
+	return totals, nil
+}
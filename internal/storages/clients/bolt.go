@@ -0,0 +1,78 @@
+package clients
+
+import (
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bolt is an embedded key-value database in a single file.
+// Every update is a transaction that is synced to disk before it returns.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens the database file at the path and creates the buckets that don't exist yet.
+func OpenBolt(path string, buckets ...string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Bolt{
+		db: db,
+	}, nil
+}
+
+// OpenBoltReadOnly opens the database file at the path for reading only, the buckets that don't exist yet read as empty.
+// The file is locked shared, so it can't be opened while a writer holds it.
+func OpenBoltReadOnly(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bolt{
+		db: db,
+	}, nil
+}
+
+// View runs the function in a read-only transaction.
+func (b *Bolt) View(fn func(tx *bolt.Tx) error) error {
+	return b.db.View(fn)
+}
+
+// Update runs the function in a read-write transaction, the transaction is rolled back when the function fails.
+func (b *Bolt) Update(fn func(tx *bolt.Tx) error) error {
+	return b.db.Update(fn)
+}
+
+// Ping checks that the database is still open.
+func (b *Bolt) Ping() error {
+	return b.db.View(func(*bolt.Tx) error {
+		return nil
+	})
+}
+
+// Path returns the path of the database file.
+func (b *Bolt) Path() string {
+	return b.db.Path()
+}
+
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
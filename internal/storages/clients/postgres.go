@@ -2,9 +2,13 @@ package clients
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PgxIface interface {
@@ -12,10 +16,39 @@ type PgxIface interface {
 	Query(context.Context, string, ...interface{}) (pgx.Rows, error)
 	Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
 	QueryRow(context.Context, string, ...interface{}) pgx.Row
+	Ping(context.Context) error
 }
 
+// PoolConfig holds the size and the timeouts of a connection pool, zero values keep the pgxpool defaults.
+type PoolConfig struct {
+	MaxConns        int32
+	MinConns        int32
+	ConnectTimeout  time.Duration
+	MaxConnIdleTime time.Duration
+}
+
+// PoolStat is a snapshot of the connection pool statistics.
+type PoolStat struct {
+	MaxConns          int32 `json:"max_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	AcquireCount      int64 `json:"acquire_count"`
+	EmptyAcquireCount int64 `json:"empty_acquire_count"`
+	NewConnsCount     int64 `json:"new_conns_count"`
+}
+
+// Delays between the pings of a lost database, the delay doubles after every failed ping up to the maximum.
+var (
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// Postgres runs the statements of the repositories, the ones failed with a retriable error are run again with a growing delay.
 type Postgres struct {
-	conn PgxIface
+	conn    PgxIface
+	pool    *pgxpool.Pool
+	onRetry func(attempt int, delay time.Duration, err error)
 }
 
 func NewPostgres(conn PgxIface) *Postgres {
@@ -24,14 +57,139 @@ func NewPostgres(conn PgxIface) *Postgres {
 	}
 }
 
-func (db *Postgres) Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
-	return db.conn.Query(ctx, query, args...)
+// NewPostgresPool creates a connection pool of the database at the dsn and checks that the database answers.
+// Broken connections are dropped and replaced by the pool, so the client outlives restarts of the database.
+func NewPostgresPool(ctx context.Context, dsn string, conf PoolConfig) (*Postgres, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.MaxConns > 0 {
+		poolConfig.MaxConns = conf.MaxConns
+	}
+	if conf.MinConns > 0 {
+		poolConfig.MinConns = conf.MinConns
+	}
+	if conf.ConnectTimeout > 0 {
+		poolConfig.ConnConfig.ConnectTimeout = conf.ConnectTimeout
+	}
+	if conf.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = conf.MaxConnIdleTime
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &Postgres{conn: pool, pool: pool}
+	if err := db.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (db *Postgres) Query(ctx context.Context, query string, args ...interface{}) (rows pgx.Rows, err error) {
+	err = db.retry(ctx, func() error {
+		rows, err = db.conn.Query(ctx, query, args...)
+		return err
+	})
+
+	return rows, err
 }
 
-func (db *Postgres) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
-	return db.conn.Exec(ctx, query, args...)
+func (db *Postgres) Exec(ctx context.Context, query string, args ...interface{}) (tag pgconn.CommandTag, err error) {
+	err = db.retry(ctx, func() error {
+		tag, err = db.conn.Exec(ctx, query, args...)
+		return err
+	})
+
+	return tag, err
 }
 
+// QueryRow returns a row whose query is run by Scan, so it is retried as well.
 func (db *Postgres) QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
-	return db.conn.QueryRow(ctx, query, args...)
+	return &retryRow{db: db, ctx: ctx, query: query, args: args}
+}
+
+// Ping checks that the database answers.
+func (db *Postgres) Ping(ctx context.Context) error {
+	return db.conn.Ping(ctx)
+}
+
+// Stat returns the statistics of the connection pool, ok is false when the client isn't backed by a pool.
+func (db *Postgres) Stat() (stat PoolStat, ok bool) {
+	if db.pool == nil {
+		return stat, false
+	}
+
+	s := db.pool.Stat()
+	return PoolStat{
+		MaxConns:          s.MaxConns(),
+		TotalConns:        s.TotalConns(),
+		AcquiredConns:     s.AcquiredConns(),
+		IdleConns:         s.IdleConns(),
+		AcquireCount:      s.AcquireCount(),
+		EmptyAcquireCount: s.EmptyAcquireCount(),
+		NewConnsCount:     s.NewConnsCount(),
+	}, true
+}
+
+// AcquireConn runs fn on a single connection of the pool, for tools that need a *pgx.Conn such as migrations.
+func (db *Postgres) AcquireConn(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	if db.pool == nil {
+		return errors.New("postgres: client isn't backed by a pool")
+	}
+
+	return db.pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) error {
+		return fn(conn.Conn())
+	})
+}
+
+// Watch pings the database every interval until the context is done.
+// After a failed ping the idle connections are dropped and the database is pinged again with a growing delay until it answers.
+// onChange gets the error of every failed ping and nil once the database answers again.
+func (db *Postgres) Watch(ctx context.Context, interval time.Duration, onChange func(err error)) {
+	delay := interval
+	var lost error
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := db.Ping(pingCtx)
+		cancel()
+
+		switch {
+		case err != nil && ctx.Err() == nil:
+			if lost == nil {
+				delay = reconnectMinDelay
+			} else {
+				delay = min(delay*2, reconnectMaxDelay)
+			}
+			lost = err
+			if db.pool != nil {
+				db.pool.Reset()
+			}
+			onChange(fmt.Errorf("postgres: connection lost, retry in %s: %w", delay, err))
+		case err == nil && lost != nil:
+			lost = nil
+			delay = interval
+			onChange(nil)
+		}
+	}
+}
+
+// Close closes every connection of the pool.
+func (db *Postgres) Close() {
+	if db.pool != nil {
+		db.pool.Close()
+	}
 }
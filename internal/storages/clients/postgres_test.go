@@ -2,9 +2,13 @@ package clients
 
 import (
 	"context"
+	"errors"
+	"os"
 	"regexp"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
 )
@@ -69,3 +73,77 @@ func TestPostgres_Test(t *testing.T) {
 		})
 	}
 }
+
+func TestPostgres_Ping(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	db := NewPostgres(mock)
+	mock.ExpectPing()
+	assert.NoError(t, db.Ping(context.Background()))
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	assert.Error(t, db.Ping(context.Background()))
+
+	_, ok := db.Stat()
+	assert.False(t, ok)
+	assert.Error(t, db.AcquireConn(context.Background(), func(conn *pgx.Conn) error { return nil }))
+	db.Close()
+}
+
+func TestNewPostgresPool(t *testing.T) {
+	_, err := NewPostgresPool(context.Background(), "postgres://localhost:5432/db?pool_max_conns=many", PoolConfig{})
+	assert.Error(t, err)
+
+	_, err = NewPostgresPool(context.Background(), "postgres://user@127.0.0.1:1/db", PoolConfig{MaxConns: 2, MinConns: 1, ConnectTimeout: time.Second, MaxConnIdleTime: time.Minute})
+	assert.Error(t, err)
+
+	dsn := os.Getenv("MIGRATE_TEST_CONN_STRING")
+	if dsn == "" {
+		t.Skip("MIGRATE_TEST_CONN_STRING is not set")
+	}
+
+	db, err := NewPostgresPool(context.Background(), dsn, PoolConfig{MaxConns: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	assert.NoError(t, db.AcquireConn(context.Background(), func(conn *pgx.Conn) error { return conn.Ping(context.Background()) }))
+	stat, ok := db.Stat()
+	assert.True(t, ok)
+	assert.Equal(t, int32(2), stat.MaxConns)
+}
+
+func TestPostgres_Watch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	reconnectMinDelay, reconnectMaxDelay = time.Millisecond, 2*time.Millisecond
+	defer func() { reconnectMinDelay, reconnectMaxDelay = time.Second, 30*time.Second }()
+
+	mock.ExpectPing()
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	mock.ExpectPing()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var changes []error
+	NewPostgres(mock).Watch(ctx, time.Millisecond, func(err error) {
+		changes = append(changes, err)
+		if err == nil {
+			cancel()
+		}
+	})
+
+	assert.Len(t, changes, 3)
+	assert.ErrorContains(t, changes[0], "retry in 1ms")
+	assert.ErrorContains(t, changes[1], "retry in 2ms")
+	assert.NoError(t, changes[2])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,129 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// retryDelays are the delays before the next attempts of a statement that failed with a retriable error.
+var retryDelays = []time.Duration{time.Second, 3 * time.Second, 5 * time.Second}
+
+// SQLSTATE codes of transaction conflicts that succeed when the statement is run again.
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// IsRetriable reports whether the statement that failed with the error can be run again:
+// connection exceptions of SQLSTATE class 08, serialization failures and deadlocks,
+// failed connects and errors raised before anything was sent to the server.
+func IsRetriable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") ||
+			pgErr.Code == sqlStateSerializationFailure ||
+			pgErr.Code == sqlStateDeadlockDetected
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	return pgconn.SafeToRetry(err)
+}
+
+// OnRetry sets the function every retried attempt is reported to with its number, the delay before the next attempt and the error.
+func (db *Postgres) OnRetry(onRetry func(attempt int, delay time.Duration, err error)) {
+	db.onRetry = onRetry
+}
+
+// permanentError is an error of an attempt that must not be retried whatever its cause.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// retry runs fn until it succeeds, fails with an error that isn't retriable or every delay of retryDelays is used.
+// The wait before the next attempt ends with the error of fn when the context is done.
+func (db *Postgres) retry(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if err == nil || attempt == len(retryDelays) || !IsRetriable(err) {
+			return err
+		}
+
+		delay := retryDelays[attempt]
+		if db.onRetry != nil {
+			db.onRetry(attempt+1, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// InTx runs fn in a transaction that is committed when fn succeeds and rolled back otherwise.
+// The whole transaction is run again on a retriable error, except when the connection is lost during the commit:
+// the transaction may have been committed then, so the error is returned to keep the changes from being applied twice.
+func (db *Postgres) InTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	return db.retry(ctx, func() error {
+		tx, err := db.conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(tx); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			var pgErr *pgconn.PgError
+			if !errors.As(err, &pgErr) {
+				return &permanentError{err: err}
+			}
+
+			return err
+		}
+
+		return nil
+	})
+}
+
+// retryRow is a row whose query is run again by Scan when it fails with a retriable error.
+type retryRow struct {
+	db    *Postgres
+	ctx   context.Context
+	query string
+	args  []interface{}
+}
+
+func (r *retryRow) Scan(dest ...any) error {
+	return r.db.retry(r.ctx, func() error {
+		return r.db.conn.QueryRow(r.ctx, r.query, r.args...).Scan(dest...)
+	})
+}
+
+var _ pgx.Row = (*retryRow)(nil)
@@ -0,0 +1,179 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetriable(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "connection exception", err: fmt.Errorf("exec: %w", &pgconn.PgError{Code: "08000"}), want: true},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, want: true},
+		{name: "connect", err: &pgconn.ConnectError{}, want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "undefined table", err: &pgconn.PgError{Code: "42P01"}},
+		{name: "plain", err: errors.New("some error")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, IsRetriable(testCase.err))
+		})
+	}
+}
+
+func TestPostgres_Retry(t *testing.T) {
+	retryDelays = []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+	defer func() { retryDelays = []time.Duration{time.Second, 3 * time.Second, 5 * time.Second} }()
+
+	query := "INSERT INTO gauge (name, labels, value) VALUES ($1, $2, $3)"
+	newMock := func(t *testing.T) (pgxmock.PgxPoolIface, *Postgres, *[]int) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(mock.Close)
+
+		var attempts []int
+		db := NewPostgres(mock)
+		db.OnRetry(func(attempt int, delay time.Duration, err error) {
+			attempts = append(attempts, attempt)
+			assert.Equal(t, time.Duration(attempt)*time.Millisecond, delay)
+		})
+
+		return mock, db, &attempts
+	}
+
+	t.Run("Exec", func(t *testing.T) {
+		mock, db, attempts := newMock(t)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Alloc", "", 1.5).WillReturnError(&pgconn.PgError{Code: "08006"})
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Alloc", "", 1.5).WillReturnError(&pgconn.PgError{Code: "40001"})
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Alloc", "", 1.5).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		_, err := db.Exec(context.Background(), query, "Alloc", "", 1.5)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, *attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		mock, db, attempts := newMock(t)
+		for i := 0; i < 4; i++ {
+			mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Alloc", "", 1.5).WillReturnError(&pgconn.PgError{Code: "08006"})
+		}
+
+		_, err := db.Exec(context.Background(), query, "Alloc", "", 1.5)
+		assert.Error(t, err)
+		assert.Equal(t, []int{1, 2, 3}, *attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not retriable", func(t *testing.T) {
+		mock, db, attempts := newMock(t)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Alloc", "", 1.5).WillReturnError(&pgconn.PgError{Code: "23505"})
+
+		_, err := db.Exec(context.Background(), query, "Alloc", "", 1.5)
+		assert.Error(t, err)
+		assert.Empty(t, *attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		mock, db, _ := newMock(t)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Alloc", "", 1.5).WillReturnError(&pgconn.PgError{Code: "08006"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		db.OnRetry(func(attempt int, delay time.Duration, err error) {
+			cancel()
+		})
+		retryDelays[0] = time.Minute
+		defer func() { retryDelays[0] = time.Millisecond }()
+
+		_, err := db.Exec(ctx, query, "Alloc", "", 1.5)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		mock, db, attempts := newMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).WillReturnError(&pgconn.PgError{Code: "08003"})
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT name, labels, value FROM gauge")).
+			WillReturnRows(pgxmock.NewRows([]string{"name", "labels", "value"}).AddRow("Alloc", "", 1.5))
+
+		rows, err := db.Query(context.Background(), "SELECT name, labels, value FROM gauge")
+		assert.NoError(t, err)
+		rows.Close()
+		assert.Equal(t, []int{1}, *attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("QueryRow", func(t *testing.T) {
+		mock, db, attempts := newMock(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT value FROM gauge WHERE name = $1")).
+			WithArgs("Alloc").
+			WillReturnError(&pgconn.PgError{Code: "40P01"})
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT value FROM gauge WHERE name = $1")).
+			WithArgs("Alloc").
+			WillReturnRows(pgxmock.NewRows([]string{"value"}).AddRow(1.5))
+
+		var value float64
+		assert.NoError(t, db.QueryRow(context.Background(), "SELECT value FROM gauge WHERE name = $1", "Alloc").Scan(&value))
+		assert.Equal(t, 1.5, value)
+		assert.Equal(t, []int{1}, *attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("InTx", func(t *testing.T) {
+		mock, db, attempts := newMock(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Alloc", "", 1.5).WillReturnError(&pgconn.PgError{Code: "40001"})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Alloc", "", 1.5).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, db.InTx(context.Background(), func(tx pgx.Tx) error {
+			_, err := tx.Exec(context.Background(), query, "Alloc", "", 1.5)
+			return err
+		}))
+		assert.Equal(t, []int{1}, *attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("InTx lost commit", func(t *testing.T) {
+		mock, db, attempts := newMock(t)
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(&pgconn.ConnectError{})
+
+		assert.Error(t, db.InTx(context.Background(), func(tx pgx.Tx) error { return nil }))
+		assert.Empty(t, *attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("InTx failed commit", func(t *testing.T) {
+		mock, db, attempts := newMock(t)
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(&pgconn.PgError{Code: "40001"})
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		assert.NoError(t, db.InTx(context.Background(), func(tx pgx.Tx) error { return nil }))
+		assert.Equal(t, []int{1}, *attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,547 @@
+package storages
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultCompactSize is the size of the write-ahead log in bytes above which the store saves a new snapshot on its own.
+const defaultCompactSize = 64 << 20
+
+// FileStore keeps the series of every file backend in memory, in a snapshot file and in a write-ahead log next to it.
+// Every change is appended to the log before it is applied, Save compacts the series into a new snapshot and truncates the log.
+// The log is also compacted once it grows above the compaction size, so it stays bounded without periodic saves.
+// The snapshot holds one {"Items": {...}, "Updated": {...}} section per metric type, the log holds one JSON record per line.
+type FileStore struct {
+	mu          sync.Mutex
+	path        string
+	syncWrite   bool
+	sections    map[string]fileSection
+	wal         *os.File
+	lock        *os.File
+	walSize     int64
+	compactSize int64
+	err         error
+	onError     func(err error)
+}
+
+// fileSection is the part of the snapshot owned by the backend of a single metric type.
+type fileSection interface {
+	snapshot() (json.RawMessage, error)
+	restore(raw json.RawMessage) error
+	replay(record walRecord) error
+}
+
+// walRecord is a single change of the write-ahead log, it either sets the items or deletes the keys of a metric type.
+// At is the update time of the items, records written before it was logged are replayed as updated at the time of the replay.
+type walRecord struct {
+	Type  string          `json:"type"`
+	Items json.RawMessage `json:"items,omitempty"`
+	Keys  []string        `json:"keys,omitempty"`
+	At    *time.Time      `json:"at,omitempty"`
+}
+
+// NewFileStore creates a store of the snapshot file at the path, the write-ahead log is kept at the path with a .wal suffix.
+// With syncWrite every change is synced to disk before it is applied, otherwise it is left to the OS to flush the log.
+func NewFileStore(path string, syncWrite bool) *FileStore {
+	return &FileStore{
+		path:        path,
+		syncWrite:   syncWrite,
+		sections:    make(map[string]fileSection),
+		compactSize: defaultCompactSize,
+	}
+}
+
+// errFileLocked is returned by lockFile for a file locked by another process.
+var errFileLocked = errors.New("locked by another process")
+
+// Path returns the path of the snapshot file.
+func (f *FileStore) Path() string {
+	return f.path
+}
+
+// WALPath returns the path of the write-ahead log.
+func (f *FileStore) WALPath() string {
+	return f.path + ".wal"
+}
+
+// LockPath returns the path of the lock file of the store.
+func (f *FileStore) LockPath() string {
+	return f.path + ".lock"
+}
+
+// Lock takes the lock file of the store, so no other process writes the snapshot and the write-ahead log until Close.
+// It fails at once when another process, such as a running server, holds the lock.
+func (f *FileStore) Lock() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.lock != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.LockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	if err := lockFile(file); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("file storage %s: %w", f.path, err)
+	}
+	f.lock = file
+
+	return nil
+}
+
+// OnError sets the function every failed write to the log or the snapshot is reported to.
+func (f *FileStore) OnError(onError func(err error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.onError = onError
+}
+
+// CompactAt sets the size of the write-ahead log in bytes above which a new snapshot is saved, zero never compacts the log on its own.
+func (f *FileStore) CompactAt(size int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.compactSize = size
+}
+
+// Err returns the error of the last write to the log or the snapshot, nil when it succeeded.
+func (f *FileStore) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
+
+// Save writes the series of every backend to a new snapshot file and truncates the write-ahead log.
+// The snapshot is written to a temporary file that is synced and renamed over the old one, so a crash never leaves a partial snapshot.
+func (f *FileStore) Save() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.setErr(f.save())
+	return f.err
+}
+
+func (f *FileStore) save() error {
+	snapshot := make(map[string]json.RawMessage, len(f.sections))
+	for metricType, section := range f.sections {
+		raw, err := section.snapshot()
+		if err != nil {
+			return err
+		}
+		snapshot[metricType] = raw
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(f.path, data); err != nil {
+		return fmt.Errorf("save snapshot %s: %w", f.path, err)
+	}
+
+	if f.wal != nil {
+		if err := f.wal.Truncate(0); err != nil {
+			return fmt.Errorf("truncate wal %s: %w", f.WALPath(), err)
+		}
+		f.walSize = 0
+
+		return f.wal.Sync()
+	}
+
+	if err := os.Remove(f.WALPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove wal %s: %w", f.WALPath(), err)
+	}
+	f.walSize = 0
+
+	return nil
+}
+
+// compact saves a new snapshot once the write-ahead log grew above the compaction size.
+// The store must be locked and the logged changes applied, so the snapshot holds every change of the truncated log.
+func (f *FileStore) compact() {
+	if f.compactSize <= 0 || f.walSize < f.compactSize {
+		return
+	}
+
+	f.setErr(f.save())
+}
+
+// Load replaces the series of every backend with the ones of the snapshot file and replays the write-ahead log on top of them.
+// Missing files leave the backends empty, sections and records of unknown metric types are skipped.
+// A last record cut short or left unreadable by a crash is dropped from the log.
+func (f *FileStore) Load() error {
+	return f.load(true)
+}
+
+// Read loads the series like Load but leaves the files untouched, a torn last record is skipped instead of dropped from the log.
+// The files of a running server can be read, the store must not be written afterwards.
+func (f *FileStore) Read() error {
+	return f.load(false)
+}
+
+func (f *FileStore) load(dropTail bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.loadSnapshot(); err != nil {
+		return fmt.Errorf("load snapshot %s: %w", f.path, err)
+	}
+
+	if err := f.replayWAL(dropTail); err != nil {
+		return fmt.Errorf("replay wal %s: %w", f.WALPath(), err)
+	}
+
+	return nil
+}
+
+func (f *FileStore) loadSnapshot() error {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snapshot map[string]json.RawMessage
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	for metricType, raw := range snapshot {
+		if section, ok := f.sections[metricType]; ok {
+			if err := section.restore(raw); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *FileStore) replayWAL(dropTail bool) error {
+	f.walSize = 0
+	file, err := os.Open(f.WALPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 && dropTail {
+				return f.dropWALTail(offset)
+			}
+
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var record walRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			if _, errPeek := reader.Peek(1); errors.Is(errPeek, io.EOF) {
+				if !dropTail {
+					return nil
+				}
+
+				return f.dropWALTail(offset)
+			}
+
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		offset += int64(len(data))
+		f.walSize = offset
+
+		if section, ok := f.sections[record.Type]; ok {
+			if err := section.replay(record); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+	}
+}
+
+// dropWALTail truncates the write-ahead log to the records before the offset, the last record was torn by a crash.
+func (f *FileStore) dropWALTail(offset int64) error {
+	f.walSize = offset
+
+	return os.Truncate(f.WALPath(), offset)
+}
+
+// Close closes the write-ahead log and releases the lock file.
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var err error
+	if f.wal != nil {
+		err = f.wal.Close()
+		f.wal = nil
+	}
+
+	if f.lock != nil {
+		err = errors.Join(err, f.lock.Close())
+		f.lock = nil
+	}
+
+	return err
+}
+
+// write appends the record to the write-ahead log and applies the change once it is logged.
+func (f *FileStore) write(record walRecord, apply func()) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.log(record); err != nil {
+		return err
+	}
+	apply()
+	f.compact()
+
+	return nil
+}
+
+// log appends the record to the write-ahead log, the store must be locked.
+func (f *FileStore) log(record walRecord) error {
+	f.setErr(f.appendWAL(record))
+	return f.err
+}
+
+// setErr keeps the result of the last write and reports it when it failed.
+func (f *FileStore) setErr(err error) {
+	f.err = err
+	if err != nil && f.onError != nil {
+		f.onError(err)
+	}
+}
+
+func (f *FileStore) appendWAL(record walRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if f.wal == nil {
+		if err := os.MkdirAll(filepath.Dir(f.path), os.ModePerm); err != nil {
+			return err
+		}
+
+		f.wal, err = os.OpenFile(f.WALPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("open wal %s: %w", f.WALPath(), err)
+		}
+	}
+
+	n, err := f.wal.Write(append(data, '\n'))
+	f.walSize += int64(n)
+	if err != nil {
+		return fmt.Errorf("write wal %s: %w", f.WALPath(), err)
+	}
+
+	if f.syncWrite {
+		return f.wal.Sync()
+	}
+
+	return nil
+}
+
+// writeFileAtomic replaces the file at the path with the data through a synced temporary file in the same directory.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in the directory durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// FileBackend is a memory backend whose changes are logged to the write-ahead log of its store.
+type FileBackend[V any] struct {
+	mem        *MemBackend[V]
+	store      *FileStore
+	metricType string
+}
+
+// NewFileBackend creates the backend of the metric type and registers it in the store.
+func NewFileBackend[V any](store *FileStore, metricType string) *FileBackend[V] {
+	backend := &FileBackend[V]{
+		mem:        NewMemBackend[V](),
+		store:      store,
+		metricType: metricType,
+	}
+
+	store.mu.Lock()
+	store.sections[metricType] = backend
+	store.mu.Unlock()
+
+	return backend
+}
+
+func (f *FileBackend[V]) GetList(ctx context.Context) (map[string]V, error) {
+	return f.mem.GetList(ctx)
+}
+
+func (f *FileBackend[V]) AddMetrics(ctx context.Context, items map[string]V) error {
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	at := timeNow()
+	return f.store.write(walRecord{Type: f.metricType, Items: raw, At: &at}, func() {
+		f.mem.add(items, at)
+	})
+}
+
+// UpdateMetrics logs the new values of the series before they are stored.
+func (f *FileBackend[V]) UpdateMetrics(_ context.Context, keys []string, fn UpdateFunc[V]) error {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	err := f.mem.update(keys, fn, func(changed map[string]V, at time.Time) error {
+		raw, err := json.Marshal(changed)
+		if err != nil {
+			return err
+		}
+
+		return f.store.log(walRecord{Type: f.metricType, Items: raw, At: &at})
+	})
+	if err != nil {
+		return err
+	}
+	f.store.compact()
+
+	return nil
+}
+
+func (f *FileBackend[V]) DeleteMetrics(ctx context.Context, keys []string) error {
+	return f.store.write(walRecord{Type: f.metricType, Keys: keys}, func() {
+		_ = f.mem.DeleteMetrics(ctx, keys)
+	})
+}
+
+func (f *FileBackend[V]) UpdatedAt(ctx context.Context) (map[string]time.Time, error) {
+	return f.mem.UpdatedAt(ctx)
+}
+
+// ExpireMetrics logs the deletion of the stale series only, the series updated in the meantime are kept.
+func (f *FileBackend[V]) ExpireMetrics(_ context.Context, keys []string, before time.Time) error {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	err := f.mem.expire(keys, before, func(expired []string) error {
+		return f.store.log(walRecord{Type: f.metricType, Keys: expired})
+	})
+	if err != nil {
+		return err
+	}
+	f.store.compact()
+
+	return nil
+}
+
+type fileSectionItems[V any] struct {
+	Items   map[string]V
+	Updated map[string]time.Time `json:",omitempty"`
+}
+
+func (f *FileBackend[V]) snapshot() (json.RawMessage, error) {
+	items, _ := f.mem.GetList(context.Background())
+	updated, _ := f.mem.UpdatedAt(context.Background())
+	return json.Marshal(fileSectionItems[V]{Items: items, Updated: updated})
+}
+
+// restore replaces the series with the ones of the section, the series of snapshots without update times are updated now.
+func (f *FileBackend[V]) restore(raw json.RawMessage) error {
+	var section fileSectionItems[V]
+	if err := json.Unmarshal(raw, &section); err != nil {
+		return err
+	}
+	f.mem.replace(section.Items, section.Updated, timeNow())
+
+	return nil
+}
+
+func (f *FileBackend[V]) replay(record walRecord) error {
+	if record.Items != nil {
+		var items map[string]V
+		if err := json.Unmarshal(record.Items, &items); err != nil {
+			return err
+		}
+
+		at := timeNow()
+		if record.At != nil {
+			at = *record.At
+		}
+		f.mem.add(items, at)
+
+		return nil
+	}
+
+	return f.mem.DeleteMetrics(context.Background(), record.Keys)
+}
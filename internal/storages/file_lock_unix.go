@@ -0,0 +1,19 @@
+//go:build !windows
+
+package storages
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on the file without waiting, errFileLocked is returned when another process holds it.
+func lockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errFileLocked
+	}
+
+	return err
+}
@@ -0,0 +1,123 @@
+package storages
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
+)
+
+// History keeps timestamped samples of series values.
+type History interface {
+	Record(ctx context.Context, metricType string, samples map[string]float64, at time.Time) error
+	Range(ctx context.Context, metricType string, key string, from time.Time, to time.Time) ([]dto.Sample, error)
+}
+
+// PrunableHistory is a History that isn't capped while the samples are recorded, such as a database table.
+type PrunableHistory interface {
+	History
+	// Prune deletes all but the newest size samples of every series.
+	Prune(ctx context.Context, size int) error
+}
+
+// DeletableHistory is a History that drops the samples of deleted series, so they don't outlive the series.
+type DeletableHistory interface {
+	History
+	// Delete drops the samples of the series of the metric type.
+	Delete(ctx context.Context, metricType string, keys []string) error
+}
+
+// MemHistory keeps the latest samples of every series in a ring buffer.
+type MemHistory struct {
+	mu     sync.RWMutex
+	size   int
+	series map[historyKey]*sampleRing
+}
+
+type historyKey struct {
+	metricType string
+	key        string
+}
+
+// sampleRing holds up to cap(samples) samples, next is the position of the oldest one once the ring is full.
+type sampleRing struct {
+	samples []dto.Sample
+	next    int
+}
+
+func (r *sampleRing) add(sample dto.Sample) {
+	if len(r.samples) < cap(r.samples) {
+		r.samples = append(r.samples, sample)
+		return
+	}
+
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % len(r.samples)
+}
+
+// ordered returns the samples from the oldest to the newest.
+func (r *sampleRing) ordered() []dto.Sample {
+	return append(append([]dto.Sample(nil), r.samples[r.next:]...), r.samples[:r.next]...)
+}
+
+// NewMemHistory creates a history that keeps up to size samples per series.
+func NewMemHistory(size int) *MemHistory {
+	if size < 1 {
+		size = 1
+	}
+
+	return &MemHistory{
+		size:   size,
+		series: make(map[historyKey]*sampleRing),
+	}
+}
+
+func (h *MemHistory) Record(_ context.Context, metricType string, samples map[string]float64, at time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, value := range samples {
+		k := historyKey{metricType: metricType, key: key}
+		ring, ok := h.series[k]
+		if !ok {
+			ring = &sampleRing{samples: make([]dto.Sample, 0, h.size)}
+			h.series[k] = ring
+		}
+		ring.add(dto.Sample{Time: at, Value: value})
+	}
+
+	return nil
+}
+
+// Delete drops the ring buffers of the series of the metric type.
+func (h *MemHistory) Delete(_ context.Context, metricType string, keys []string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, key := range keys {
+		delete(h.series, historyKey{metricType: metricType, key: key})
+	}
+
+	return nil
+}
+
+// Range returns the samples of the series within [from, to] ordered by time.
+func (h *MemHistory) Range(_ context.Context, metricType string, key string, from time.Time, to time.Time) ([]dto.Sample, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ring, ok := h.series[historyKey{metricType: metricType, key: key}]
+	if !ok {
+		return nil, nil
+	}
+
+	var samples []dto.Sample
+	for _, sample := range ring.ordered() {
+		if !sample.Time.Before(from) && !sample.Time.After(to) {
+			samples = append(samples, sample)
+		}
+	}
+
+	return samples, nil
+}
@@ -3,15 +3,67 @@ package storages
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
+
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
 )
 
 type EntityMetric interface {
 	Process(ctx context.Context, name string, data string) error
 	ProcessMassive(ctx context.Context, data map[string]float64) error
 	GetList(ctx context.Context) (map[string]float64, error)
+	Delete(ctx context.Context, keys []string) error
+}
+
+// ResettableMetric is an EntityMetric whose series can be set back to zero, such as a counter.
+type ResettableMetric interface {
+	EntityMetric
+	Reset(ctx context.Context, keys []string) error
+}
+
+// ExpirableMetric is an EntityMetric whose series keep the time they were updated last, so stale series can expire.
+type ExpirableMetric interface {
+	EntityMetric
+	UpdatedAt(ctx context.Context) (map[string]time.Time, error)
+	Expire(ctx context.Context, keys []string, before time.Time) error
+}
+
+// ItemMetric is an EntityMetric that reads a single series without listing the others.
+type ItemMetric interface {
+	EntityMetric
+	// GetItem returns the value of the series with the key, ok is false when it isn't stored.
+	GetItem(ctx context.Context, key string) (value float64, ok bool, err error)
+}
+
+// SampledMetric is an EntityMetric whose writes return the stored values of the written series as GetList reports them,
+// so the values can be used without listing the series again.
+type SampledMetric interface {
+	EntityMetric
+	ProcessSample(ctx context.Context, name string, data string) (float64, error)
+	ProcessSamples(ctx context.Context, data map[string]float64) (map[string]float64, error)
+}
+
+// HistogramMetric is an EntityMetric that aggregates observations into buckets.
+// GetList of a histogram returns the number of observations, ProcessHistograms returns them for the merged histograms.
+type HistogramMetric interface {
+	EntityMetric
+	ProcessHistograms(ctx context.Context, data map[string]dto.Histogram) (map[string]float64, error)
+	GetHistograms(ctx context.Context) (map[string]dto.Histogram, error)
+	Bounds() []float64
+}
+
+// SummaryMetric is an EntityMetric that keeps a quantile sketch per metric.
+// GetList of a summary returns the number of observations, ProcessSummaries returns them for the merged summaries.
+type SummaryMetric interface {
+	EntityMetric
+	ProcessSummaries(ctx context.Context, data map[string]dto.Summary) (map[string]float64, error)
+	GetSummaries(ctx context.Context) (map[string]dto.Summary, error)
+	RelativeAccuracy() float64
 }
 
 type MemStorage struct {
+	mu      sync.RWMutex
 	metrics map[string]EntityMetric
 }
 
@@ -23,10 +75,16 @@ func NewMemStorage() *MemStorage {
 }
 
 func (m *MemStorage) AddMetric(metricType string, metric EntityMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.metrics[metricType] = metric
 }
 
 func (m *MemStorage) GetMetricType(metricType string) (EntityMetric, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	mt, ok := m.metrics[metricType]
 	if !ok {
 		return nil, errors.New("metric type not found")
@@ -35,6 +93,15 @@ func (m *MemStorage) GetMetricType(metricType string) (EntityMetric, error) {
 	return mt, nil
 }
 
+// GetList returns a copy of the metric types, so callers may range over it while types are added.
 func (m *MemStorage) GetList() map[string]EntityMetric {
-	return m.metrics
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	metrics := make(map[string]EntityMetric, len(m.metrics))
+	for metricType, metric := range m.metrics {
+		metrics[metricType] = metric
+	}
+
+	return metrics
 }
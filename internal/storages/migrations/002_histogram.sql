@@ -0,0 +1,6 @@
+-- Write your migrate up statements here
+CREATE TABLE histogram (name varchar(255) PRIMARY KEY, bounds DOUBLE PRECISION[] NOT NULL, counts int8[] NOT NULL, sum DOUBLE PRECISION NOT NULL, count int8 NOT NULL);
+---- create above / drop below ----
+DROP TABLE histogram;
+-- Write your migrate down statements here. If this migrations is irreversible
+-- Then delete the separator line above.
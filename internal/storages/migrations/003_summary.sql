@@ -0,0 +1,6 @@
+-- Write your migrate up statements here
+CREATE TABLE summary (name varchar(255) PRIMARY KEY, sketch jsonb NOT NULL);
+---- create above / drop below ----
+DROP TABLE summary;
+-- Write your migrate down statements here. If this migrations is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,20 @@
+-- Write your migrate up statements here
+ALTER TABLE counter ADD COLUMN labels varchar(1024) NOT NULL DEFAULT '';
+ALTER TABLE counter DROP CONSTRAINT counter_pkey, ADD PRIMARY KEY (name, labels);
+ALTER TABLE gauge ADD COLUMN labels varchar(1024) NOT NULL DEFAULT '';
+ALTER TABLE gauge DROP CONSTRAINT gauge_pkey, ADD PRIMARY KEY (name, labels);
+ALTER TABLE histogram ADD COLUMN labels varchar(1024) NOT NULL DEFAULT '';
+ALTER TABLE histogram DROP CONSTRAINT histogram_pkey, ADD PRIMARY KEY (name, labels);
+ALTER TABLE summary ADD COLUMN labels varchar(1024) NOT NULL DEFAULT '';
+ALTER TABLE summary DROP CONSTRAINT summary_pkey, ADD PRIMARY KEY (name, labels);
+---- create above / drop below ----
+DELETE FROM counter WHERE labels <> '';
+ALTER TABLE counter DROP CONSTRAINT counter_pkey, ADD PRIMARY KEY (name), DROP COLUMN labels;
+DELETE FROM gauge WHERE labels <> '';
+ALTER TABLE gauge DROP CONSTRAINT gauge_pkey, ADD PRIMARY KEY (name), DROP COLUMN labels;
+DELETE FROM histogram WHERE labels <> '';
+ALTER TABLE histogram DROP CONSTRAINT histogram_pkey, ADD PRIMARY KEY (name), DROP COLUMN labels;
+DELETE FROM summary WHERE labels <> '';
+ALTER TABLE summary DROP CONSTRAINT summary_pkey, ADD PRIMARY KEY (name), DROP COLUMN labels;
+-- Write your migrate down statements here. If this migrations is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,7 @@
+-- Write your migrate up statements here
+CREATE TABLE samples (type varchar(32) NOT NULL, name varchar(255) NOT NULL, labels varchar(1024) NOT NULL DEFAULT '', ts timestamptz NOT NULL, value DOUBLE PRECISION NOT NULL);
+CREATE INDEX samples_series_ts ON samples (type, name, labels, ts);
+---- create above / drop below ----
+DROP TABLE samples;
+-- Write your migrate down statements here. If this migrations is irreversible
+-- Then delete the separator line above.
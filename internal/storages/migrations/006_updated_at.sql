@@ -0,0 +1,12 @@
+-- Write your migrate up statements here
+ALTER TABLE gauge ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE counter ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE histogram ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE summary ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
+---- create above / drop below ----
+ALTER TABLE gauge DROP COLUMN updated_at;
+ALTER TABLE counter DROP COLUMN updated_at;
+ALTER TABLE histogram DROP COLUMN updated_at;
+ALTER TABLE summary DROP COLUMN updated_at;
+-- Write your migrate down statements here. If this migrations is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,27 @@
+-- Write your migrate up statements here
+ALTER TABLE counter ADD COLUMN tenant varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE counter DROP CONSTRAINT counter_pkey, ADD PRIMARY KEY (tenant, name, labels);
+ALTER TABLE gauge ADD COLUMN tenant varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE gauge DROP CONSTRAINT gauge_pkey, ADD PRIMARY KEY (tenant, name, labels);
+ALTER TABLE histogram ADD COLUMN tenant varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE histogram DROP CONSTRAINT histogram_pkey, ADD PRIMARY KEY (tenant, name, labels);
+ALTER TABLE summary ADD COLUMN tenant varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE summary DROP CONSTRAINT summary_pkey, ADD PRIMARY KEY (tenant, name, labels);
+ALTER TABLE samples ADD COLUMN tenant varchar(64) NOT NULL DEFAULT '';
+DROP INDEX samples_series_ts;
+CREATE INDEX samples_series_ts ON samples (tenant, type, name, labels, ts);
+---- create above / drop below ----
+DELETE FROM counter WHERE tenant <> '';
+ALTER TABLE counter DROP CONSTRAINT counter_pkey, ADD PRIMARY KEY (name, labels), DROP COLUMN tenant;
+DELETE FROM gauge WHERE tenant <> '';
+ALTER TABLE gauge DROP CONSTRAINT gauge_pkey, ADD PRIMARY KEY (name, labels), DROP COLUMN tenant;
+DELETE FROM histogram WHERE tenant <> '';
+ALTER TABLE histogram DROP CONSTRAINT histogram_pkey, ADD PRIMARY KEY (name, labels), DROP COLUMN tenant;
+DELETE FROM summary WHERE tenant <> '';
+ALTER TABLE summary DROP CONSTRAINT summary_pkey, ADD PRIMARY KEY (name, labels), DROP COLUMN tenant;
+DELETE FROM samples WHERE tenant <> '';
+DROP INDEX samples_series_ts;
+ALTER TABLE samples DROP COLUMN tenant;
+CREATE INDEX samples_series_ts ON samples (type, name, labels, ts);
+-- Write your migrate down statements here. If this migrations is irreversible
+-- Then delete the separator line above.
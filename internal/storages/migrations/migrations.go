@@ -0,0 +1,145 @@
+// Package migrations holds the database schema migrations of the server, they are embedded into the binary.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/tern/v2/migrate"
+)
+
+// FS holds the embedded migrations.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// VersionTable is the table the version of the applied migrations is kept in.
+const VersionTable = "public.schema_version"
+
+// Actions of a migrate command.
+const (
+	ActionUp     = "up"
+	ActionDown   = "down"
+	ActionStatus = "status"
+	ActionTo     = "to"
+)
+
+// Source returns the migrations of the directory, the embedded migrations when the directory is empty.
+func Source(dir string) fs.FS {
+	if dir == "" {
+		return FS
+	}
+
+	return os.DirFS(dir)
+}
+
+// NewMigrator creates a migrator of the connection with the migrations of fsys.
+func NewMigrator(ctx context.Context, conn *pgx.Conn, fsys fs.FS) (*migrate.Migrator, error) {
+	migrator, err := migrate.NewMigrator(ctx, conn, VersionTable)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrator.LoadMigrations(fsys); err != nil {
+		return nil, err
+	}
+
+	return migrator, nil
+}
+
+// Command is a parsed migrate command: up, down, status or to with the target version.
+type Command struct {
+	Action string
+	Target int32
+}
+
+// ParseCommand parses the arguments of a migrate command.
+func ParseCommand(args []string) (Command, error) {
+	if len(args) == 0 {
+		return Command{}, errors.New("migrate: expected up, down, status or to N")
+	}
+
+	command := Command{Action: args[0]}
+	switch command.Action {
+	case ActionUp, ActionDown, ActionStatus:
+		if len(args) != 1 {
+			return Command{}, fmt.Errorf("migrate %s: unexpected arguments %v", command.Action, args[1:])
+		}
+	case ActionTo:
+		if len(args) != 2 {
+			return Command{}, errors.New("migrate to: expected the target version")
+		}
+
+		target, err := strconv.ParseInt(args[1], 10, 32)
+		if err != nil || target < 0 {
+			return Command{}, fmt.Errorf("migrate to: invalid version %s", args[1])
+		}
+		command.Target = int32(target)
+	default:
+		return Command{}, fmt.Errorf("migrate: unknown command %s", command.Action)
+	}
+
+	return command, nil
+}
+
+// Run runs the command with the migrator and writes the resulting version to out.
+// Down reverts the last applied migration, status lists the migrations and marks the applied ones.
+func (c Command) Run(ctx context.Context, migrator *migrate.Migrator, out io.Writer) error {
+	current, err := migrator.GetCurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch c.Action {
+	case ActionUp:
+		err = migrator.Migrate(ctx)
+	case ActionDown:
+		if current == 0 {
+			return errors.New("migrate down: no migrations are applied")
+		}
+		err = migrator.MigrateTo(ctx, current-1)
+	case ActionTo:
+		err = migrator.MigrateTo(ctx, c.Target)
+	case ActionStatus:
+		return writeStatus(out, migrator, current)
+	default:
+		return fmt.Errorf("migrate: unknown command %s", c.Action)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, err := migrator.GetCurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "migrated from version %d to %d of %d\n", current, version, len(migrator.Migrations))
+	return err
+}
+
+func writeStatus(out io.Writer, migrator *migrate.Migrator, current int32) error {
+	if _, err := fmt.Fprintf(out, "version %d of %d\n", current, len(migrator.Migrations)); err != nil {
+		return err
+	}
+
+	for _, migration := range migrator.Migrations {
+		state := "pending"
+		if migration.Sequence <= current {
+			state = "applied"
+		}
+
+		if _, err := fmt.Fprintf(out, "%3d %-8s %s\n", migration.Sequence, state, migration.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
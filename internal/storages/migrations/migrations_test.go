@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/tern/v2/migrate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	names, err := migrate.FindMigrations(FS)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, names)
+	assert.Equal(t, "001_init.sql", names[0])
+
+	assert.Equal(t, FS, Source(""))
+	names, err = migrate.FindMigrations(Source("."))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, names)
+}
+
+func TestParseCommand(t *testing.T) {
+	testCases := []struct {
+		args    []string
+		command Command
+		wantErr bool
+	}{
+		{args: []string{"up"}, command: Command{Action: ActionUp}},
+		{args: []string{"down"}, command: Command{Action: ActionDown}},
+		{args: []string{"status"}, command: Command{Action: ActionStatus}},
+		{args: []string{"to", "3"}, command: Command{Action: ActionTo, Target: 3}},
+		{args: []string{"to", "0"}, command: Command{Action: ActionTo}},
+		{args: []string{}, wantErr: true},
+		{args: []string{"sideways"}, wantErr: true},
+		{args: []string{"up", "3"}, wantErr: true},
+		{args: []string{"to"}, wantErr: true},
+		{args: []string{"to", "three"}, wantErr: true},
+		{args: []string{"to", "-1"}, wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		command, err := ParseCommand(testCase.args)
+		if testCase.wantErr {
+			assert.Error(t, err, testCase.args)
+			continue
+		}
+
+		assert.NoError(t, err, testCase.args)
+		assert.Equal(t, testCase.command, command)
+	}
+}
+
+func TestCommandRun(t *testing.T) {
+	dsn := os.Getenv("MIGRATE_TEST_CONN_STRING")
+	if dsn == "" {
+		t.Skip("MIGRATE_TEST_CONN_STRING is not set")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+
+	migrator, err := NewMigrator(ctx, conn, FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := int32(len(migrator.Migrations))
+
+	run := func(args ...string) string {
+		command, err := ParseCommand(args)
+		assert.NoError(t, err)
+
+		var out bytes.Buffer
+		assert.NoError(t, command.Run(ctx, migrator, &out))
+		return out.String()
+	}
+
+	run("up")
+	assert.Contains(t, run("status"), "applied")
+	run("down")
+	version, _ := migrator.GetCurrentVersion(ctx)
+	assert.Equal(t, last-1, version)
+	run("to", "1")
+	assert.Contains(t, run("status"), "pending")
+	run("up")
+	version, _ = migrator.GetCurrentVersion(ctx)
+	assert.Equal(t, last, version)
+}
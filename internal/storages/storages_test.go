@@ -1,9 +1,18 @@
 package storages_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/AnatolySnegovskiy/metric/internal/mocks"
+	"github.com/AnatolySnegovskiy/metric/internal/services/dto"
 	"github.com/AnatolySnegovskiy/metric/internal/storages"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -64,3 +73,549 @@ func TestMemStorage(t *testing.T) {
 		})
 	}
 }
+
+func TestMemHistory(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(1000, 0)
+	history := storages.NewMemHistory(3)
+
+	for i := 0; i < 5; i++ {
+		err := history.Record(ctx, "gauge", map[string]float64{"Alloc": float64(i), `Alloc{host="web1"}`: float64(-i)}, start.Add(time.Duration(i)*time.Second))
+		assert.NoError(t, err)
+	}
+
+	samples, err := history.Range(ctx, "gauge", "Alloc", start, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.Sample{
+		{Time: start.Add(2 * time.Second), Value: 2},
+		{Time: start.Add(3 * time.Second), Value: 3},
+		{Time: start.Add(4 * time.Second), Value: 4},
+	}, samples)
+
+	samples, err = history.Range(ctx, "gauge", `Alloc{host="web1"}`, start.Add(3*time.Second), start.Add(3*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.Sample{{Time: start.Add(3 * time.Second), Value: -3}}, samples)
+
+	samples, err = history.Range(ctx, "counter", "Alloc", start, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, samples)
+
+	assert.NoError(t, history.Delete(ctx, "gauge", []string{"Alloc", "Missing"}))
+	samples, _ = history.Range(ctx, "gauge", "Alloc", start, start.Add(time.Minute))
+	assert.Empty(t, samples)
+	samples, _ = history.Range(ctx, "gauge", `Alloc{host="web1"}`, start, start.Add(time.Minute))
+	assert.Len(t, samples, 3)
+
+	assert.NoError(t, history.Record(ctx, "gauge", map[string]float64{"Alloc": 10}, start.Add(time.Hour)))
+	samples, _ = history.Range(ctx, "gauge", "Alloc", start, start.Add(2*time.Hour))
+	assert.Equal(t, []dto.Sample{{Time: start.Add(time.Hour), Value: 10}}, samples)
+}
+
+func TestMemBackend(t *testing.T) {
+	ctx := context.Background()
+	backend := storages.NewMemBackend[float64]()
+
+	assert.NoError(t, backend.AddMetrics(ctx, map[string]float64{"Alloc": 1, "HeapAlloc": 2}))
+	list, err := backend.GetList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"Alloc": 1, "HeapAlloc": 2}, list)
+
+	list["Alloc"] = 5
+	assert.NoError(t, backend.DeleteMetrics(ctx, []string{"HeapAlloc", "Missing"}))
+	list, _ = backend.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 1}, list)
+}
+
+func TestFileBackend(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "metrics.json")
+
+	store := storages.NewFileStore(path, false)
+	assert.Equal(t, path, store.Path())
+	assert.Equal(t, path+".wal", store.WALPath())
+	assert.NoError(t, store.Load())
+
+	gauge := storages.NewFileBackend[float64](store, "gauge")
+	histogram := storages.NewFileBackend[dto.Histogram](store, "histogram")
+	assert.NoError(t, gauge.AddMetrics(ctx, map[string]float64{"Alloc": 1, "HeapAlloc": 2}))
+	assert.NoError(t, histogram.AddMetrics(ctx, map[string]dto.Histogram{"latency": {Bounds: []float64{1}, Counts: []uint64{1, 0}, Sum: 0.5, Count: 1}}))
+	assert.NoFileExists(t, path)
+	assert.NoError(t, store.Save())
+
+	wal, _ := os.ReadFile(store.WALPath())
+	assert.Empty(t, wal)
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, entries, 2)
+
+	assert.NoError(t, gauge.DeleteMetrics(ctx, []string{"HeapAlloc"}))
+	assert.NoError(t, gauge.AddMetrics(ctx, map[string]float64{"Alloc": 3}))
+	assert.NoError(t, store.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var snapshot map[string]struct {
+		Items   json.RawMessage
+		Updated map[string]time.Time
+	}
+	assert.NoError(t, json.Unmarshal(data, &snapshot))
+	assert.JSONEq(t, `{"Alloc":1,"HeapAlloc":2}`, string(snapshot["gauge"].Items))
+	assert.JSONEq(t, `{"latency":{"bounds":[1],"counts":[1,0],"sum":0.5,"count":1}}`, string(snapshot["histogram"].Items))
+	assert.Len(t, snapshot["gauge"].Updated, 2)
+
+	restoredStore := storages.NewFileStore(path, true)
+	restoredGauge := storages.NewFileBackend[float64](restoredStore, "gauge")
+	restoredHistogram := storages.NewFileBackend[dto.Histogram](restoredStore, "histogram")
+	assert.NoError(t, restoredStore.Load())
+
+	list, _ := restoredGauge.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 3}, list)
+	histograms, _ := restoredHistogram.GetList(ctx)
+	assert.Equal(t, uint64(1), histograms["latency"].Count)
+
+	_ = os.WriteFile(path, []byte("{"), 0644)
+	assert.Error(t, restoredStore.Load())
+}
+
+func TestFileStoreTornWAL(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.json")
+
+	store := storages.NewFileStore(path, true)
+	gauge := storages.NewFileBackend[float64](store, "gauge")
+	assert.NoError(t, gauge.AddMetrics(ctx, map[string]float64{"Alloc": 1}))
+	assert.NoError(t, store.Close())
+
+	wal, _ := os.OpenFile(store.WALPath(), os.O_WRONLY|os.O_APPEND, 0644)
+	_, _ = wal.WriteString(`{"type":"gauge","items":{"Alloc":`)
+	_ = wal.Close()
+
+	restoredStore := storages.NewFileStore(path, true)
+	restoredGauge := storages.NewFileBackend[float64](restoredStore, "gauge")
+	assert.NoError(t, restoredStore.Load())
+	list, _ := restoredGauge.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 1}, list)
+
+	assert.NoError(t, restoredGauge.AddMetrics(ctx, map[string]float64{"HeapAlloc": 2}))
+	assert.NoError(t, restoredStore.Close())
+
+	replayedStore := storages.NewFileStore(path, true)
+	replayedGauge := storages.NewFileBackend[float64](replayedStore, "gauge")
+	assert.NoError(t, replayedStore.Load())
+	list, _ = replayedGauge.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 1, "HeapAlloc": 2}, list)
+
+	wal, _ = os.OpenFile(store.WALPath(), os.O_WRONLY|os.O_APPEND, 0644)
+	_, _ = wal.WriteString("{\"type\":\"gauge\",\"items\":{\"Alloc\":\x00\x00\x00\n")
+	_ = wal.Close()
+	size := fileSize(t, store.WALPath())
+	assert.NoError(t, replayedStore.Read())
+	assert.Equal(t, size, fileSize(t, store.WALPath()))
+	assert.NoError(t, replayedStore.Load())
+	list, _ = replayedGauge.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 1, "HeapAlloc": 2}, list)
+	assert.Less(t, fileSize(t, store.WALPath()), size)
+
+	_ = os.WriteFile(store.WALPath(), []byte("{\n{}\n"), 0644)
+	assert.Error(t, replayedStore.Load())
+}
+
+func TestFileStoreLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "metrics.json")
+
+	store := storages.NewFileStore(path, true)
+	assert.NoError(t, store.Lock())
+	assert.NoError(t, store.Lock())
+	assert.FileExists(t, store.LockPath())
+
+	other := storages.NewFileStore(path, true)
+	assert.ErrorContains(t, other.Lock(), "locked by another process")
+	assert.NoError(t, other.Read())
+
+	assert.NoError(t, store.Close())
+	assert.NoError(t, other.Lock())
+	assert.NoError(t, other.Close())
+}
+
+func TestFileStoreCompactAt(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.json")
+
+	store := storages.NewFileStore(path, true)
+	store.CompactAt(200)
+	gauge := storages.NewFileBackend[float64](store, "gauge")
+	counter := storages.NewFileBackend[float64](store, "counter")
+	assert.NoError(t, gauge.AddMetrics(ctx, map[string]float64{"Alloc": 1}))
+	assert.NoFileExists(t, path)
+
+	add := func(key string, value float64, _ bool) (float64, error) {
+		return value + 1, nil
+	}
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, counter.UpdateMetrics(ctx, []string{"PollCount"}, add))
+	}
+	assert.FileExists(t, path)
+	assert.Less(t, fileSize(t, store.WALPath()), int64(200))
+	assert.NoError(t, store.Close())
+
+	restoredStore := storages.NewFileStore(path, true)
+	restoredGauge := storages.NewFileBackend[float64](restoredStore, "gauge")
+	restoredCounter := storages.NewFileBackend[float64](restoredStore, "counter")
+	assert.NoError(t, restoredStore.Load())
+	list, _ := restoredGauge.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 1}, list)
+	list, _ = restoredCounter.GetList(ctx)
+	assert.Equal(t, map[string]float64{"PollCount": 10}, list)
+}
+
+// fileSize returns the size of the file at the path.
+func fileSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+
+	return info.Size()
+}
+
+func TestFileStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.json")
+
+	var reported []error
+	store := storages.NewFileStore(path, false)
+	store.OnError(func(err error) {
+		reported = append(reported, err)
+	})
+	gauge := storages.NewFileBackend[float64](store, "gauge")
+
+	_ = os.Mkdir(store.WALPath(), os.ModePerm)
+	assert.Error(t, gauge.AddMetrics(ctx, map[string]float64{"Alloc": 1}))
+	assert.Error(t, store.Err())
+	list, _ := gauge.GetList(ctx)
+	assert.Empty(t, list)
+
+	_ = os.Mkdir(path, os.ModePerm)
+	assert.Error(t, store.Save())
+	assert.Len(t, reported, 2)
+
+	_ = os.Remove(path)
+	_ = os.Remove(store.WALPath())
+	assert.NoError(t, store.Save())
+	assert.NoError(t, store.Err())
+}
+
+func TestMemBackendUpdateMetrics(t *testing.T) {
+	ctx := context.Background()
+	backend := storages.NewMemBackend[float64]()
+	add := func(key string, value float64, _ bool) (float64, error) {
+		return value + 1, nil
+	}
+
+	assert.NoError(t, backend.UpdateMetrics(ctx, []string{"Alloc", "HeapAlloc"}, add))
+	assert.NoError(t, backend.UpdateMetrics(ctx, []string{"Alloc"}, add))
+	assert.Error(t, backend.UpdateMetrics(ctx, []string{"Alloc", "Missing"}, func(key string, value float64, ok bool) (float64, error) {
+		if !ok {
+			return 0, errors.New("not found")
+		}
+		return value + 1, nil
+	}))
+
+	list, _ := backend.GetList(ctx)
+	assert.Equal(t, map[string]float64{"Alloc": 2, "HeapAlloc": 1}, list)
+}
+
+func TestMemBackendConcurrent(t *testing.T) {
+	ctx := context.Background()
+	backend := storages.NewMemBackend[float64]()
+	keys := []string{"PollCount", `PollCount{host="web1"}`, "Alloc", "HeapAlloc"}
+	add := func(key string, value float64, _ bool) (float64, error) {
+		return value + 1, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				_ = backend.UpdateMetrics(ctx, keys, add)
+				_ = backend.AddMetrics(ctx, map[string]float64{fmt.Sprintf("Random%d", i): float64(j)})
+				_ = backend.DeleteMetrics(ctx, []string{fmt.Sprintf("Random%d", (i+1)%16)})
+				_, _ = backend.GetList(ctx)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	list, _ := backend.GetList(ctx)
+	for _, key := range keys {
+		assert.Equal(t, float64(16*500), list[key], key)
+	}
+}
+
+func TestMemStorageConcurrent(t *testing.T) {
+	storage := storages.NewMemStorage()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				storage.AddMetric(fmt.Sprintf("type%d", i), nil)
+				for metricType := range storage.GetList() {
+					_, _ = storage.GetMetricType(metricType)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, storage.GetList(), 8)
+}
+
+func TestFileBackendUpdateMetrics(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	add := func(key string, value float64, _ bool) (float64, error) {
+		return value + 1, nil
+	}
+
+	store := storages.NewFileStore(path, false)
+	counter := storages.NewFileBackend[float64](store, "counter")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = counter.UpdateMetrics(ctx, []string{"PollCount"}, add)
+			}
+		}()
+		if i == 4 {
+			assert.NoError(t, store.Save())
+		}
+	}
+	wg.Wait()
+	assert.NoError(t, store.Close())
+
+	restoredStore := storages.NewFileStore(path, false)
+	restored := storages.NewFileBackend[float64](restoredStore, "counter")
+	assert.NoError(t, restoredStore.Load())
+	list, _ := restored.GetList(ctx)
+	assert.Equal(t, map[string]float64{"PollCount": 800}, list)
+}
+
+func TestMemBackendExpireMetrics(t *testing.T) {
+	ctx := context.Background()
+	backend := storages.NewMemBackend[float64]()
+
+	assert.NoError(t, backend.AddMetrics(ctx, map[string]float64{"Alloc": 1, "HeapAlloc": 2}))
+	updated, err := backend.UpdatedAt(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, updated, 2)
+
+	assert.NoError(t, backend.ExpireMetrics(ctx, []string{"Alloc", "HeapAlloc"}, updated["Alloc"]))
+	list, _ := backend.GetList(ctx)
+	assert.Len(t, list, 2)
+
+	time.Sleep(time.Millisecond)
+	cutoff := time.Now()
+	assert.NoError(t, backend.UpdateMetrics(ctx, []string{"HeapAlloc"}, func(key string, value float64, _ bool) (float64, error) {
+		return value + 1, nil
+	}))
+	assert.NoError(t, backend.ExpireMetrics(ctx, []string{"Alloc", "HeapAlloc", "Missing"}, cutoff))
+
+	list, _ = backend.GetList(ctx)
+	assert.Equal(t, map[string]float64{"HeapAlloc": 3}, list)
+	updated, _ = backend.UpdatedAt(ctx)
+	assert.Len(t, updated, 1)
+	assert.False(t, updated["HeapAlloc"].Before(cutoff))
+
+	assert.NoError(t, backend.DeleteMetrics(ctx, []string{"HeapAlloc"}))
+	updated, _ = backend.UpdatedAt(ctx)
+	assert.Empty(t, updated)
+}
+
+func TestFileBackendExpireMetrics(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metrics.json")
+
+	store := storages.NewFileStore(path, false)
+	gauge := storages.NewFileBackend[float64](store, "gauge")
+	assert.NoError(t, gauge.AddMetrics(ctx, map[string]float64{"Alloc": 1, "HeapAlloc": 2}))
+	assert.NoError(t, store.Save())
+	saved, _ := gauge.UpdatedAt(ctx)
+
+	time.Sleep(time.Millisecond)
+	cutoff := time.Now()
+	assert.NoError(t, gauge.AddMetrics(ctx, map[string]float64{"HeapAlloc": 3}))
+	added, _ := gauge.UpdatedAt(ctx)
+	assert.NoError(t, gauge.ExpireMetrics(ctx, []string{"Alloc", "HeapAlloc"}, cutoff))
+	assert.NoError(t, store.Close())
+
+	wal, _ := os.ReadFile(store.WALPath())
+	assert.Contains(t, string(wal), `"keys":["Alloc"]`)
+
+	restoredStore := storages.NewFileStore(path, false)
+	restored := storages.NewFileBackend[float64](restoredStore, "gauge")
+	assert.NoError(t, restoredStore.Load())
+
+	list, _ := restored.GetList(ctx)
+	assert.Equal(t, map[string]float64{"HeapAlloc": 3}, list)
+	updated, _ := restored.UpdatedAt(ctx)
+	assert.True(t, added["HeapAlloc"].Equal(updated["HeapAlloc"]))
+	assert.True(t, saved["HeapAlloc"].Before(updated["HeapAlloc"]))
+}
+
+// countingBackend is a backend of counters that counts the reads that reach it.
+type countingBackend struct {
+	*storages.MemBackend[float64]
+	lists int
+	items int
+}
+
+func (c *countingBackend) GetList(ctx context.Context) (map[string]float64, error) {
+	c.lists++
+	return c.MemBackend.GetList(ctx)
+}
+
+func (c *countingBackend) GetItem(ctx context.Context, key string) (float64, error) {
+	c.items++
+	items, _ := c.MemBackend.GetList(ctx)
+	value, ok := items[key]
+	if !ok {
+		return 0, storages.ErrSeriesNotFound
+	}
+
+	return value, nil
+}
+
+func (c *countingBackend) IncrementMetrics(ctx context.Context, deltas map[string]float64) (map[string]float64, error) {
+	keys := make([]string, 0, len(deltas))
+	for key := range deltas {
+		keys = append(keys, key)
+	}
+
+	totals := make(map[string]float64, len(deltas))
+	err := c.UpdateMetrics(ctx, keys, func(key string, value float64, _ bool) (float64, error) {
+		totals[key] = value + deltas[key]
+		return totals[key], nil
+	})
+
+	return totals, err
+}
+
+func TestCachedBackend(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingBackend{MemBackend: storages.NewMemBackend[float64]()}
+	stats := &storages.CacheStats{}
+	cache := storages.NewCachedBackend[float64](inner, time.Hour, stats)
+
+	assert.NoError(t, inner.AddMetrics(ctx, map[string]float64{"Alloc": 1}))
+	value, err := cache.GetItem(ctx, "Alloc")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), value)
+	value, _ = cache.GetItem(ctx, "Alloc")
+	assert.Equal(t, float64(1), value)
+	assert.Equal(t, 1, inner.items)
+
+	list, err := cache.GetList(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"Alloc": 1}, list)
+	_, err = cache.GetItem(ctx, "Missing")
+	assert.ErrorIs(t, err, storages.ErrSeriesNotFound)
+	assert.Equal(t, 1, inner.items)
+
+	assert.NoError(t, cache.AddMetrics(ctx, map[string]float64{"Alloc": 2, "HeapAlloc": 3}))
+	assert.NoError(t, cache.DeleteMetrics(ctx, []string{"Alloc"}))
+	list, _ = cache.GetList(ctx)
+	assert.Equal(t, map[string]float64{"HeapAlloc": 3}, list)
+	stored, _ := inner.MemBackend.GetList(ctx)
+	assert.Equal(t, list, stored)
+	assert.Equal(t, 1, inner.lists)
+
+	assert.NoError(t, inner.AddMetrics(ctx, map[string]float64{"HeapAlloc": 4}))
+	list, _ = cache.GetList(ctx)
+	assert.Equal(t, float64(3), list["HeapAlloc"])
+	assert.Equal(t, uint64(2), stats.Misses())
+	assert.Equal(t, uint64(4), stats.Hits())
+
+	updated, err := cache.UpdatedAt(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, updated, 1)
+	assert.NoError(t, cache.ExpireMetrics(ctx, []string{"HeapAlloc"}, time.Now().Add(time.Minute)))
+	list, _ = cache.GetList(ctx)
+	assert.Empty(t, list)
+	assert.Equal(t, 2, inner.lists)
+
+	stale := storages.NewCachedBackend[float64](inner, time.Nanosecond, nil)
+	assert.NoError(t, inner.AddMetrics(ctx, map[string]float64{"Alloc": 5}))
+	_, _ = stale.GetList(ctx)
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, inner.AddMetrics(ctx, map[string]float64{"Alloc": 6}))
+	value, _ = stale.GetItem(ctx, "Alloc")
+	assert.Equal(t, float64(6), value)
+	assert.Equal(t, 3, inner.lists)
+}
+
+func TestCachedIncrementer(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingBackend{MemBackend: storages.NewMemBackend[float64]()}
+	cache := storages.NewCachedIncrementer(inner, time.Hour, nil)
+
+	totals, err := cache.IncrementMetrics(ctx, map[string]float64{"PollCount": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"PollCount": 1}, totals)
+	list, _ := cache.GetList(ctx)
+	assert.Equal(t, map[string]float64{"PollCount": 1}, list)
+
+	assert.NoError(t, inner.AddMetrics(ctx, map[string]float64{"PollCount": 10}))
+	totals, err = cache.IncrementMetrics(ctx, map[string]float64{"PollCount": 2, "Requests": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"PollCount": 12, "Requests": 3}, totals)
+	list, _ = cache.GetList(ctx)
+	assert.Equal(t, map[string]float64{"PollCount": 12, "Requests": 3}, list)
+	value, err := cache.GetItem(ctx, "Requests")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), value)
+
+	stored, _ := inner.MemBackend.GetList(ctx)
+	assert.Equal(t, stored, list)
+	assert.Equal(t, 1, inner.lists)
+	assert.Equal(t, 0, inner.items)
+}
+
+func BenchmarkMemBackendUpdateParallel(b *testing.B) {
+	ctx := context.Background()
+	backend := storages.NewMemBackend[float64]()
+	add := func(key string, value float64, _ bool) (float64, error) {
+		return value + 1, nil
+	}
+
+	keys := make([][]string, 1024)
+	for i := range keys {
+		keys[i] = []string{fmt.Sprintf("Metric%d", i)}
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_ = backend.UpdateMetrics(ctx, keys[i%len(keys)], add)
+			i++
+		}
+	})
+}
+
+func BenchmarkMemBackendGetList(b *testing.B) {
+	ctx := context.Background()
+	backend := storages.NewMemBackend[float64]()
+	for i := 0; i < 1024; i++ {
+		_ = backend.AddMetrics(ctx, map[string]float64{fmt.Sprintf("Metric%d", i): float64(i)})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = backend.GetList(ctx)
+	}
+}